@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func createDBCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage the inventory database",
+		Long:  "Inspect and upgrade the schema of the CloudRecon inventory database",
+	}
+
+	cmd.AddCommand(createDBMigrateCmd())
+	cmd.AddCommand(createDBVersionCmd())
+
+	return cmd
+}
+
+func createDBMigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply pending schema migrations",
+		Long: `Upgrade the inventory database to the schema of this version of CloudRecon.
+Migrations are also applied automatically whenever the database is opened.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := storage.OpenSQLiteStorage(viper.GetString("db-path"))
+			if err != nil {
+				return fmt.Errorf("failed to open database: %w", err)
+			}
+			defer store.Close()
+
+			applied, err := store.Migrate()
+			for _, migration := range applied {
+				fmt.Printf("Applied migration %d: %s\n", migration.Version, migration.Description)
+			}
+			if err != nil {
+				return fmt.Errorf("migration failed: %w", err)
+			}
+
+			if len(applied) == 0 {
+				fmt.Printf("Database is up to date at schema version %d\n", storage.LatestSchemaVersion())
+			} else {
+				fmt.Printf("Database migrated to schema version %d\n", storage.LatestSchemaVersion())
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func createDBVersionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show the database schema version",
+		Long: `Show the schema version of the inventory database and the migrations still pending.
+The database is only read, so a missing database is reported rather than created.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			latest := storage.LatestSchemaVersion()
+
+			store, err := storage.OpenSQLiteStorageReadOnly(viper.GetString("db-path"))
+			if errors.Is(err, os.ErrNotExist) {
+				fmt.Printf("Schema Version: 0 (not initialized)\n")
+				fmt.Printf("Latest Version: %d\n", latest)
+				fmt.Printf("\nThe database does not exist yet; run 'cloudrecon db migrate' or a discovery to create it.\n")
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			current, err := store.SchemaVersion()
+			if err != nil {
+				return err
+			}
+			migrations, err := store.Migrations()
+			if err != nil {
+				return err
+			}
+
+			if current == 0 {
+				fmt.Printf("Schema Version: 0 (not initialized)\n")
+			} else {
+				fmt.Printf("Schema Version: %d\n", current)
+			}
+			fmt.Printf("Latest Version: %d\n", latest)
+			if current > latest {
+				fmt.Printf("\nThe database was written by a newer version of CloudRecon; upgrade CloudRecon to use it.\n")
+				return nil
+			}
+
+			fmt.Printf("\nMigrations:\n")
+			for _, migration := range migrations {
+				state := "pending"
+				if !migration.AppliedAt.IsZero() {
+					state = "applied " + migration.AppliedAt.Local().Format(time.RFC3339)
+				}
+				fmt.Printf("  %3d  %-30s %s\n", migration.Version, migration.Description, state)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/cloudrecon/cloudrecon/internal/export"
+	"github.com/cloudrecon/cloudrecon/internal/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func createDiffCmd() *cobra.Command {
+	var (
+		format    string
+		output    string
+		providers []string
+		accounts  []string
+		services  []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff <from-run> <to-run>",
+		Short: "Show inventory changes between two discovery runs",
+		Long: `Compare the inventories stored by two discovery runs and list the resources
+added, removed and modified in between. Runs are given by ID or by a time
+(RFC 3339 or YYYY-MM-DD), which selects the last run completed by then.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := storage.NewSQLiteStorage(viper.GetString("db-path"))
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer store.Close()
+
+			fromRun, err := store.ResolveRun(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid from run: %w", err)
+			}
+			toRun, err := store.ResolveRun(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid to run: %w", err)
+			}
+
+			diff, err := store.DiffRuns(fromRun, toRun, core.DiffFilter{
+				Providers: providers,
+				Accounts:  accounts,
+				Services:  services,
+			})
+			if err != nil {
+				return fmt.Errorf("diff failed: %w", err)
+			}
+
+			var w io.Writer = os.Stdout
+			if output != "" {
+				file, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				defer file.Close()
+				w = file
+			}
+
+			return export.NewExporter().WriteDiff(w, diff, format)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format (text, json, markdown)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path")
+	cmd.Flags().StringSliceVarP(&providers, "providers", "p", []string{}, "Only show changes of these providers")
+	cmd.Flags().StringSliceVarP(&accounts, "accounts", "a", []string{}, "Only show changes of these accounts")
+	cmd.Flags().StringSliceVarP(&services, "services", "s", []string{}, "Only show changes of these services")
+
+	return cmd
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cloudrecon/cloudrecon/internal/export"
+	"github.com/cloudrecon/cloudrecon/internal/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func createHistoryCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "history <resource-id>",
+		Short: "Show the change history of a resource",
+		Long: `Show the timeline of a resource: when it was first seen, every change with
+the configuration paths, tags and public access or encryption flags that
+changed, and its deletion.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := storage.NewSQLiteStorage(viper.GetString("db-path"))
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer store.Close()
+
+			history, err := store.GetResourceHistory(args[0])
+			if err != nil {
+				return err
+			}
+
+			return export.NewExporter().WriteHistory(os.Stdout, history, format)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format (text, json)")
+
+	return cmd
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cloudrecon/cloudrecon/internal/analysis"
+	"github.com/cloudrecon/cloudrecon/internal/export"
+	"github.com/cloudrecon/cloudrecon/internal/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func createImpactCmd() *cobra.Command {
+	var (
+		format        string
+		output        string
+		minConfidence float64
+		refresh       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "impact <resource-id>",
+		Short: "Show the blast radius of changing or deleting a resource",
+		Long: `Walk every resource that depends, directly or transitively, on a resource
+such as a VPC, security group, KMS key or IAM role, and report what would
+break if it were deleted or changed, grouped by depth, provider and service
+with the monthly cost and public-facing resources at stake.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := storage.NewSQLiteStorage(viper.GetString("db-path"))
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			defer store.Close()
+
+			graph, err := analysis.NewDependencyAnalyzer(store).GetDependencyGraph(context.TODO(), refresh)
+			if err != nil {
+				return fmt.Errorf("dependency analysis failed: %w", err)
+			}
+
+			report, err := graph.Impact(args[0], minConfidence)
+			if err != nil {
+				return err
+			}
+
+			var w io.Writer = os.Stdout
+			if output != "" {
+				file, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("failed to create output file: %w", err)
+				}
+				defer file.Close()
+				w = file
+			}
+
+			return export.NewExporter().WriteImpact(w, report, format)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format (text, json, markdown)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path")
+	cmd.Flags().Float64Var(&minConfidence, "min-confidence", 0, "Ignore dependencies found with a lower confidence (0.0 to 1.0)")
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Recompute dependencies instead of using the stored analysis")
+
+	return cmd
+}
@@ -3,8 +3,10 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
@@ -13,9 +15,6 @@ import (
 	"github.com/cloudrecon/cloudrecon/internal/cli"
 	"github.com/cloudrecon/cloudrecon/internal/core"
 	"github.com/cloudrecon/cloudrecon/internal/export"
-	"github.com/cloudrecon/cloudrecon/internal/providers/aws"
-	"github.com/cloudrecon/cloudrecon/internal/providers/azure"
-	"github.com/cloudrecon/cloudrecon/internal/providers/gcp"
 	"github.com/cloudrecon/cloudrecon/internal/query"
 	"github.com/cloudrecon/cloudrecon/internal/storage"
 	"github.com/sirupsen/logrus"
@@ -57,6 +56,8 @@ zero infrastructure requirements.`,
 	// Add subcommands
 	rootCmd.AddCommand(createDiscoverCmd())
 	rootCmd.AddCommand(createQueryCmd())
+	rootCmd.AddCommand(createDiffCmd())
+	rootCmd.AddCommand(createHistoryCmd())
 	rootCmd.AddCommand(createExportCmd())
 	rootCmd.AddCommand(createAskCmd())
 	rootCmd.AddCommand(createStatusCmd())
@@ -64,7 +65,10 @@ zero infrastructure requirements.`,
 	rootCmd.AddCommand(createSecurityCmd())
 	rootCmd.AddCommand(createCostCmd())
 	rootCmd.AddCommand(createDependenciesCmd())
+	rootCmd.AddCommand(createImpactCmd())
 	rootCmd.AddCommand(createInteractiveCmd())
+	rootCmd.AddCommand(createProvidersCmd())
+	rootCmd.AddCommand(createDBCmd())
 
 	// Handle graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -95,6 +99,13 @@ func createDiscoverCmd() *cobra.Command {
 		useNativeTools bool
 		maxParallel    int
 		timeout        time.Duration
+		showProgress   bool
+		resumeRunID    int64
+		excludeRegions []string
+		includeOptIn   bool
+		awsRole        string
+		awsExternalID  string
+		gcpFolders     []string
 	)
 
 	cmd := &cobra.Command{
@@ -125,39 +136,41 @@ using the most efficient method available (cloud-native tools when possible).`,
 			}
 			defer storage.Close()
 
-			// Initialize providers based on command line flags
-			providerMap := make(map[string]core.CloudProvider)
-
-			// If no providers specified, initialize all
-			if len(providers) == 0 {
-				providers = []string{"aws", "azure", "gcp"}
+			// Region flags override the configuration file
+			if cmd.Flags().Changed("include-opt-in-regions") {
+				config.AWS.IncludeOptInRegions = includeOptIn
 			}
-
-			if contains(providers, "aws") {
-				awsProvider, err := aws.NewProvider(config.AWS)
-				if err != nil {
-					logrus.Warnf("Failed to initialize AWS provider: %v", err)
-				} else {
-					providerMap["aws"] = awsProvider
-				}
+			if !cmd.Flags().Changed("exclude-regions") {
+				excludeRegions = config.Discovery.ExcludeRegions
 			}
 
-			if contains(providers, "azure") {
-				azureProvider, err := azure.NewProvider(config.Azure)
-				if err != nil {
-					logrus.Warnf("Failed to initialize Azure provider: %v", err)
+			// A role flag holding an ARN is a template, anything else a role name
+			if cmd.Flags().Changed("aws-role") {
+				if strings.HasPrefix(awsRole, "arn:") {
+					config.AWS.AssumeRole.RoleARN = awsRole
+					config.AWS.AssumeRole.RoleName = ""
 				} else {
-					providerMap["azure"] = azureProvider
+					config.AWS.AssumeRole.RoleName = awsRole
+					config.AWS.AssumeRole.RoleARN = ""
 				}
 			}
+			if cmd.Flags().Changed("aws-external-id") {
+				config.AWS.AssumeRole.ExternalID = awsExternalID
+			}
+			if cmd.Flags().Changed("gcp-folder") {
+				config.GCP.Folders = gcpFolders
+			}
 
-			if contains(providers, "gcp") {
-				gcpProvider, err := gcp.NewProvider(config.GCP)
-				if err != nil {
-					logrus.Warnf("Failed to initialize GCP provider: %v", err)
-				} else {
-					providerMap["gcp"] = gcpProvider
+			// Initialize the requested providers, or every default one
+			providerMap, providerErrs := core.DefaultProviderRegistry().Create(providers, config)
+			for _, providerErr := range providerErrs {
+				logrus.Warnf("%v", providerErr)
+			}
+			if len(providers) == 0 {
+				for name := range providerMap {
+					providers = append(providers, name)
 				}
+				sort.Strings(providers)
 			}
 
 			if len(providerMap) == 0 {
@@ -181,18 +194,33 @@ using the most efficient method available (cloud-native tools when possible).`,
 				Providers:      providers, // Use the actual providers from command line
 				Accounts:       accounts,
 				Regions:        regions,
+				ExcludeRegions: excludeRegions,
 				ResourceTypes:  resourceTypes,
 				UseNativeTools: useNativeTools,
 				MaxParallel:    maxParallel,
 				Timeout:        timeout,
-				ProgressHandler: func(progress core.DiscoveryProgress) {
-					logrus.Infof("Discovery progress: %d resources found, %d accounts processed",
-						progress.ResourcesFound, progress.AccountsProcessed)
-				},
+				ResumeRunID:    resumeRunID,
+			}
+
+			var renderer *discoveryProgressRenderer
+			if showProgress {
+				renderer = newDiscoveryProgressRenderer(os.Stderr)
+				options.ProgressHandler = renderer.Handle
+			} else {
+				options.ProgressHandler = func(event core.DiscoveryProgress) {
+					if event.Event != core.ProgressAccountFinished {
+						return
+					}
+					logrus.Infof("Discovery progress: %d resources found, %d/%d accounts processed",
+						event.ResourcesFound, event.AccountsProcessed, event.TotalAccounts)
+				}
 			}
 
 			// Create orchestrator
 			orchestrator := core.NewDiscoveryOrchestrator(providerMap, storage, options)
+			for _, enricher := range analysis.DefaultEnrichers(config.Analysis) {
+				orchestrator.RegisterEnricher(enricher)
+			}
 
 			// Start discovery with proper context
 			logrus.Info("Starting cloud resource discovery...")
@@ -202,35 +230,63 @@ using the most efficient method available (cloud-native tools when possible).`,
 			defer discoveryCtx.Cancel()
 
 			result, err := orchestrator.Discover(discoveryCtx.Context())
+			if renderer != nil {
+				renderer.Flush()
+			}
 			if err != nil {
+				if result != nil && result.RunID != 0 {
+					logrus.Infof("Resume with: cloudrecon discover --resume %d", result.RunID)
+				}
 				return core.NewProviderError("discovery failed", err)
 			}
 
+			if result.Status == core.RunStatusInterrupted {
+				logrus.Warnf("Discovery run %d was interrupted after %d resources", result.RunID, len(result.Resources))
+				logrus.Infof("Resume with: cloudrecon discover --resume %d", result.RunID)
+				return nil
+			}
+
 			duration := time.Since(start)
-			logrus.Infof("Discovery completed in %v", duration)
+			logrus.Infof("Discovery run %d completed in %v", result.RunID, duration)
 			logrus.Infof("Found %d resources across %d accounts",
 				len(result.Resources), len(result.Accounts))
 
+			if result.Status == core.RunStatusPartial {
+				for _, discoveryErr := range result.Errors {
+					logrus.Warnf("%v", discoveryErr)
+				}
+				logrus.Warnf("Some accounts failed; retry them with: cloudrecon discover --resume %d", result.RunID)
+			}
+
 			return nil
 		},
 	}
 
-	cmd.Flags().StringSliceVarP(&providers, "providers", "p", []string{}, "Cloud providers to scan (aws, azure, gcp)")
+	cmd.Flags().StringSliceVarP(&providers, "providers", "p", []string{}, "Cloud providers to scan (see 'cloudrecon providers list')")
 	cmd.Flags().StringSliceVarP(&accounts, "accounts", "a", []string{}, "Specific accounts to scan")
 	cmd.Flags().StringSliceVarP(&regions, "regions", "r", []string{}, "Specific regions to scan")
+	cmd.Flags().StringSliceVar(&excludeRegions, "exclude-regions", []string{}, "Regions to skip when scanning all enabled regions")
+	cmd.Flags().BoolVar(&includeOptIn, "include-opt-in-regions", true, "Scan AWS opt-in regions enabled for the account")
+	cmd.Flags().StringVar(&awsRole, "aws-role", "", "Role name or ARN template (with {account_id}) assumed in AWS member accounts")
+	cmd.Flags().StringVar(&awsExternalID, "aws-external-id", "", "External ID passed when assuming the AWS member account role")
+	cmd.Flags().StringSliceVar(&gcpFolders, "gcp-folder", []string{}, "GCP folders whose projects, including those of subfolders, are scanned")
 	cmd.Flags().StringSliceVarP(&resourceTypes, "resource-types", "t", []string{}, "Specific resource types to discover")
 	cmd.Flags().StringVarP(&mode, "mode", "m", "standard", "Discovery mode (quick, standard, deep)")
 	cmd.Flags().BoolVar(&useNativeTools, "native-tools", true, "Use cloud-native tools when available")
 	cmd.Flags().IntVar(&maxParallel, "max-parallel", 10, "Maximum parallel operations")
 	cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Minute, "Discovery timeout")
+	cmd.Flags().BoolVar(&showProgress, "progress", isTerminal(os.Stderr), "Show live progress bars during discovery, on by default when stderr is a terminal")
+	cmd.Flags().Int64Var(&resumeRunID, "resume", 0, "Resume an interrupted discovery run by ID")
 
 	return cmd
 }
 
 func createQueryCmd() *cobra.Command {
 	var (
-		format string
-		output string
+		format  string
+		output  string
+		orgNode string
+		asOf    string
 	)
 
 	cmd := &cobra.Command{
@@ -248,13 +304,26 @@ func createQueryCmd() *cobra.Command {
 			// Create query engine
 			engine := query.NewEngine(storage)
 
+			// Historical queries see the inventory stored by an earlier run
+			if asOf != "" {
+				runID, err := storage.ResolveRun(asOf)
+				if err != nil {
+					return fmt.Errorf("invalid --as-of: %w", err)
+				}
+				engine = engine.AsOf(runID)
+			}
+
 			// Execute query
+			var results []core.Resource
 			queryStr := strings.Join(args, " ")
-			if queryStr == "" {
+			switch {
+			case orgNode != "":
+				results, err = engine.GetResourcesUnderOrgNode(orgNode)
+			case queryStr == "":
 				return fmt.Errorf("query string is required")
+			default:
+				results, err = engine.ExecuteSQL(queryStr)
 			}
-
-			results, err := engine.ExecuteSQL(queryStr)
 			if err != nil {
 				return fmt.Errorf("query failed: %w", err)
 			}
@@ -276,6 +345,8 @@ func createQueryCmd() *cobra.Command {
 
 	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format (text, json, csv)")
 	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path")
+	cmd.Flags().StringVar(&asOf, "as-of", "", "Query the inventory as of a discovery run ID or a time (RFC 3339 or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&orgNode, "org-node", "", "List the resources of every account below an OU, management group or folder (ID or name)")
 
 	return cmd
 }
@@ -381,6 +452,24 @@ func createStatusCmd() *cobra.Command {
 			fmt.Printf("Total Resources: %d\n", status.ResourceCount)
 			fmt.Printf("Providers: %s\n", status.Providers)
 
+			summary, err := storage.GetResourceSummary()
+			if err != nil {
+				return fmt.Errorf("failed to get resource summary: %w", err)
+			}
+
+			fmt.Printf("\nRegistered Providers:\n")
+			for _, registration := range core.DefaultProviderRegistry().List() {
+				fmt.Printf("  %-10s %d resources\n", registration.Name, summary.ByProvider[registration.Name])
+			}
+
+			if len(summary.ByOrgNode) > 0 {
+				fmt.Printf("\nOrganization Hierarchy:\n")
+				for _, node := range summary.ByOrgNode {
+					fmt.Printf("  %-6s %-40s %6d resources  $%.2f/month  %d public\n",
+						node.Node.Provider, node.Path, node.ResourceCount, node.MonthlyCost, node.PublicResources)
+				}
+			}
+
 			return nil
 		},
 	}
@@ -498,10 +587,23 @@ func createCostCmd() *cobra.Command {
 }
 
 func createDependenciesCmd() *cobra.Command {
+	var (
+		refresh  bool
+		format   string
+		output   string
+		resource string
+		depth    int
+		tag      string
+	)
+
 	cmd := &cobra.Command{
 		Use:   "dependencies",
 		Short: "Run dependency analysis on discovered resources",
-		Long:  "Map resource dependencies and relationships",
+		Long: `Map resource dependencies and relationships. Results are stored and reused
+until the next discovery run; use --refresh to recompute them.
+
+The graph can be exported as a diagram in DOT, GraphML, Mermaid or Cytoscape
+JSON format, scoped to the resources around --resource or carrying --tag.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Initialize storage
 			storage, err := storage.NewSQLiteStorage(viper.GetString("db-path"))
@@ -513,23 +615,65 @@ func createDependenciesCmd() *cobra.Command {
 			// Create dependency analyzer
 			analyzer := analysis.NewDependencyAnalyzer(storage)
 
-			// Run dependency analysis
-			graph, err := analyzer.AnalyzeDependencies(context.TODO())
+			// Run dependency analysis, reusing the stored analysis of the latest run
+			graph, err := analyzer.GetDependencyGraph(context.TODO(), refresh)
 			if err != nil {
 				return fmt.Errorf("dependency analysis failed: %w", err)
 			}
 
+			// Scope the graph
+			if resource != "" {
+				graph, err = graph.Subgraph(resource, depth)
+				if err != nil {
+					return err
+				}
+			}
+			if tag != "" {
+				graph = graph.WithTag(tag)
+			}
+
+			if format != "text" {
+				var w io.Writer = os.Stdout
+				if output != "" {
+					file, err := os.Create(output)
+					if err != nil {
+						return fmt.Errorf("failed to create output file: %w", err)
+					}
+					defer file.Close()
+					w = file
+				}
+
+				return export.NewExporter().WriteGraph(w, graph, format)
+			}
+
 			// Print dependency results
 			fmt.Printf("Dependency Analysis completed!\n")
 			fmt.Printf("Total Resources: %d\n", graph.Stats.TotalResources)
 			fmt.Printf("Total Dependencies: %d\n", graph.Stats.TotalDependencies)
 			fmt.Printf("Cycles: %d\n", graph.Stats.Cycles)
 			fmt.Printf("Islands: %d\n", graph.Stats.Islands)
+			fmt.Printf("Max Depth: %d\n", graph.Stats.MaxDepth)
+			fmt.Printf("Articulation Points: %d\n", graph.Stats.ArticulationPoints)
+
+			if len(graph.SinglePointsOfFailure) > 0 {
+				fmt.Printf("\nSingle Points of Failure:\n")
+				for _, spof := range graph.SinglePointsOfFailure {
+					fmt.Printf("  [%s] %s (%d dependents, %d transitive)\n",
+						spof.Severity, spof.Title, spof.Dependents, spof.TransitiveDependents)
+				}
+			}
 
 			return nil
 		},
 	}
 
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Recompute dependencies instead of using the stored analysis")
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format (text, json, dot, graphml, mermaid, cytoscape)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file path")
+	cmd.Flags().StringVar(&resource, "resource", "", "Only include resources connected to this resource ID")
+	cmd.Flags().IntVar(&depth, "depth", 0, "Maximum number of dependencies from --resource (0 for no limit)")
+	cmd.Flags().StringVar(&tag, "tag", "", "Only include resources with this tag (key or key=value)")
+
 	return cmd
 }
 
@@ -612,25 +756,40 @@ func setDefaultConfig() {
 	viper.SetDefault("aws.regions", []string{"us-east-1", "us-west-2"})
 	viper.SetDefault("aws.max_retries", 3)
 	viper.SetDefault("aws.timeout", "30s")
+	viper.SetDefault("aws.include_opt_in_regions", true)
+	viper.SetDefault("aws.assume_role.role_name", "")
+	viper.SetDefault("aws.assume_role.role_arn", "")
+	viper.SetDefault("aws.assume_role.external_id", "")
+	viper.SetDefault("aws.assume_role.session_duration", "")
+	viper.SetDefault("aws.assume_role.session_name", "cloudrecon")
 
 	// Azure defaults
 	viper.SetDefault("azure.subscriptions", []string{})
 	viper.SetDefault("azure.max_retries", 3)
 	viper.SetDefault("azure.timeout", "30s")
+	viper.SetDefault("azure.endpoint", "")
 
 	// GCP defaults
 	viper.SetDefault("gcp.project_id", "")
 	viper.SetDefault("gcp.organization_id", "")
 	viper.SetDefault("gcp.credentials_path", "")
 	viper.SetDefault("gcp.projects", []string{})
+	viper.SetDefault("gcp.folders", []string{})
 	viper.SetDefault("gcp.max_retries", 3)
 	viper.SetDefault("gcp.timeout", "30s")
 	viper.SetDefault("gcp.discovery_methods", []string{"config", "environment", "gcloud", "metadata", "resource_manager"})
 
+	// File provider defaults
+	viper.SetDefault("file.path", "")
+
+	// Terraform provider defaults
+	viper.SetDefault("terraform.state_paths", []string{})
+
 	// Discovery defaults
 	viper.SetDefault("discovery.max_parallel", 10)
 	viper.SetDefault("discovery.timeout", "300s")
 	viper.SetDefault("discovery.use_native_tools", true)
+	viper.SetDefault("discovery.exclude_regions", []string{})
 
 	// Analysis defaults
 	viper.SetDefault("analysis.enable_cost_analysis", true)
@@ -672,12 +831,3 @@ func validateConfig(config *core.Config) error {
 
 	return nil
 }
-
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
-		}
-	}
-	return false
-}
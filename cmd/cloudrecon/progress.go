@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/cloudrecon/cloudrecon/pkg/progress"
+	"github.com/mattn/go-isatty"
+)
+
+// discoveryProgressRenderer draws discovery progress events as one bar per provider.
+// The orchestrator serializes progress events, so no locking is needed here.
+type discoveryProgressRenderer struct {
+	out        io.Writer
+	bars       *progress.MultiProgressBar
+	byProvider map[string]*progress.ProgressBar
+	errors     []string
+	lastDraw   time.Time
+}
+
+// newDiscoveryProgressRenderer creates a renderer writing to out
+func newDiscoveryProgressRenderer(out io.Writer) *discoveryProgressRenderer {
+	return &discoveryProgressRenderer{
+		out:        out,
+		bars:       progress.NewMultiProgressBar(),
+		byProvider: make(map[string]*progress.ProgressBar),
+	}
+}
+
+// Handle updates the provider bars for a single progress event
+func (r *discoveryProgressRenderer) Handle(event core.DiscoveryProgress) {
+	bar, ok := r.byProvider[event.CurrentProvider]
+	if !ok {
+		bar = r.bars.AddNamedBar(event.CurrentProvider, 0)
+		bar.SetShowRate(false)
+		r.byProvider[event.CurrentProvider] = bar
+	}
+
+	switch event.Event {
+	case core.ProgressProviderStarted:
+		bar.SetMessage("enumerating accounts")
+	case core.ProgressAccountsDiscovered:
+		bar.SetTotal(event.TotalAccounts)
+	case core.ProgressAccountStarted:
+		bar.SetMessage(event.CurrentAccount)
+	case core.ProgressRegionStarted:
+		bar.SetMessage(fmt.Sprintf("%s/%s", event.CurrentAccount, event.CurrentRegion))
+	case core.ProgressServiceStarted:
+		bar.SetMessage(fmt.Sprintf("%s/%s/%s", event.CurrentAccount, event.CurrentRegion, event.CurrentService))
+	case core.ProgressAccountFinished:
+		bar.Add(1)
+	case core.ProgressProviderFinished:
+		bar.Finish()
+		bar.SetMessage("done")
+	}
+
+	if event.Error != nil {
+		scope := event.CurrentProvider
+		for _, part := range []string{event.CurrentAccount, event.CurrentRegion, event.CurrentService} {
+			if part != "" {
+				scope += "/" + part
+			}
+		}
+		r.errors = append(r.errors, fmt.Sprintf("%s: %v", scope, event.Error))
+		if event.Event == core.ProgressProviderFinished {
+			bar.SetMessage("failed")
+		}
+	}
+
+	// Redraw at most ten times a second unless a provider just finished
+	if event.Event == core.ProgressProviderFinished || time.Since(r.lastDraw) >= 100*time.Millisecond {
+		r.draw()
+	}
+}
+
+// Flush draws the final state of all bars
+func (r *discoveryProgressRenderer) Flush() {
+	r.draw()
+}
+
+// draw writes the bars followed by any errors reported so far
+func (r *discoveryProgressRenderer) draw() {
+	r.lastDraw = time.Now()
+	footer := make([]string, 0, len(r.errors))
+	for _, msg := range r.errors {
+		footer = append(footer, "  error: "+msg)
+	}
+	r.bars.SetFooter(footer)
+	r.bars.Write(r.out)
+}
+
+// isTerminal reports whether a file is an interactive terminal, where
+// progress bars can be redrawn in place
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
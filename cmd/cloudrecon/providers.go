@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/spf13/cobra"
+
+	// Providers register themselves with the core registry when imported.
+	// In-house providers are enabled by adding their import here.
+	_ "github.com/cloudrecon/cloudrecon/internal/providers/aws"
+	_ "github.com/cloudrecon/cloudrecon/internal/providers/azure"
+	_ "github.com/cloudrecon/cloudrecon/internal/providers/file"
+	_ "github.com/cloudrecon/cloudrecon/internal/providers/gcp"
+	_ "github.com/cloudrecon/cloudrecon/internal/providers/terraform"
+)
+
+func createProvidersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "providers",
+		Short: "Inspect available cloud providers",
+		Long:  "Inspect the cloud providers registered with CloudRecon and their capabilities",
+	}
+
+	cmd.AddCommand(createProvidersListCmd())
+
+	return cmd
+}
+
+func createProvidersListCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List registered providers",
+		Long:  "List the registered cloud providers with their configuration section and capabilities",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registrations := core.DefaultProviderRegistry().List()
+
+			switch format {
+			case "json":
+				type providerInfo struct {
+					Name          string   `json:"name"`
+					Description   string   `json:"description"`
+					ConfigSection string   `json:"config_section"`
+					NativeTool    string   `json:"native_tool,omitempty"`
+					Regions       []string `json:"regions,omitempty"`
+					AccountTypes  []string `json:"account_types,omitempty"`
+				}
+
+				infos := make([]providerInfo, 0, len(registrations))
+				for _, registration := range registrations {
+					infos = append(infos, providerInfo{
+						Name:          registration.Name,
+						Description:   registration.Description,
+						ConfigSection: registration.ConfigSection,
+						NativeTool:    registration.Capabilities.NativeTool,
+						Regions:       registration.Capabilities.Regions,
+						AccountTypes:  registration.Capabilities.AccountTypes,
+					})
+				}
+
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(infos)
+
+			case "text":
+				for _, registration := range registrations {
+					capabilities := registration.Capabilities
+
+					nativeTool := "none"
+					if capabilities.SupportsNativeTool() {
+						nativeTool = capabilities.NativeTool
+					}
+					regions := "not region scoped"
+					if len(capabilities.Regions) > 0 {
+						regions = strings.Join(capabilities.Regions, ", ")
+					}
+
+					fmt.Printf("%s: %s\n", registration.Name, registration.Description)
+					fmt.Printf("  Config Section: %s\n", registration.ConfigSection)
+					fmt.Printf("  Native Tool: %s\n", nativeTool)
+					fmt.Printf("  Account Types: %s\n", strings.Join(capabilities.AccountTypes, ", "))
+					fmt.Printf("  Default Regions: %s\n", regions)
+				}
+				return nil
+
+			default:
+				return fmt.Errorf("unsupported format: %s", format)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format (text, json)")
+
+	return cmd
+}
@@ -0,0 +1,460 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// Signals of a cross-cloud dependency
+const (
+	SignalDNSRecord              = "dns_record"
+	SignalVPNPeer                = "vpn_peer"
+	SignalConfigurationReference = "configuration_reference"
+)
+
+// CrossCloudEvidence is one observation supporting a cross-cloud dependency:
+// a value found in the source's configuration that the target owns
+type CrossCloudEvidence struct {
+	Signal       string `json:"signal"`
+	Field        string `json:"field"`         // Path of the value in the source configuration
+	Value        string `json:"value"`         // IP address, hostname or resource ID
+	MatchedField string `json:"matched_field"` // Path of the value in the target configuration
+}
+
+// Keys, with case, underscores and dashes removed, whose values are addresses
+// of the resource itself. Keys ending with "endpoint", "host" or "hostname"
+// are owned addresses too.
+var ownedAddressKeys = map[string]bool{
+	"ip": true, "ips": true, "ipaddress": true, "ipaddresses": true,
+	"publicip": true, "publicips": true, "publicipaddress": true, "publicipaddresses": true,
+	"privateip": true, "privateips": true, "privateipaddress": true, "privateipaddresses": true,
+	"natip": true, "networkip": true, "externalip": true, "address": true, "addresses": true,
+	"outsideipaddress": true, "tunnel1address": true, "tunnel2address": true,
+	"dnsname": true, "publicdns": true, "publicdnsname": true, "privatedns": true, "privatednsname": true,
+	"fqdn": true, "fullyqualifieddomainname": true, "domainname": true,
+	"hostnames": true, "enabledhostnames": true, "endpoints": true,
+	"primaryendpoints": true, "secondaryendpoints": true, "url": true, "uri": true,
+}
+
+// Keys of containers holding settings such as environment variables, app
+// settings and connection strings. Their values point at other resources,
+// whatever their names, and are never addresses of the resource itself.
+var settingsContainerKeys = map[string]bool{
+	"environment": true, "environmentvariables": true, "variables": true, "env": true, "envvars": true,
+	"appsettings": true, "connectionstrings": true, "connectionstring": true,
+}
+
+// Keys of DNS records holding the addresses they resolve to
+var recordDataKeys = map[string]bool{
+	"records": true, "record": true, "resourcerecords": true, "value": true, "values": true,
+	"rrdatas": true, "alias": true, "aliastarget": true, "cname": true, "cnamerecord": true,
+	"arecords": true, "aaaarecords": true, "ipv4address": true, "ipv6address": true, "target": true,
+}
+
+// Keys of connection strings naming the host to connect to
+var connectionHostKeys = map[string]bool{
+	"server": true, "datasource": true, "host": true, "hostname": true, "address": true,
+	"addr": true, "endpoint": true, "networkaddress": true,
+}
+
+var (
+	urlHostPattern = regexp.MustCompile(`(?i)[a-z][a-z0-9+.-]*://(?:[^@/\s]*@)?(\[[0-9a-f:.]+\]|[^/:;,?#\s'"]+)`)
+	hostPattern    = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`)
+)
+
+// crossCloudKind is how a resource takes part in cross-cloud detection
+type crossCloudKind int
+
+const (
+	kindOther crossCloudKind = iota
+	kindDNSRecord
+	kindGateway     // VPN or interconnect gateway owning its addresses
+	kindPeerGateway // Gateway describing the remote side, whose addresses belong to the peer
+)
+
+// classifyCrossCloud finds the kind of a resource from its service and type
+func classifyCrossCloud(resource core.Resource) crossCloudKind {
+	service := normalizeKey(resource.Service)
+	kind := service + normalizeKey(resource.Type)
+
+	switch {
+	case (service == "route53" || service == "dns") && strings.Contains(kind, "record"):
+		return kindDNSRecord
+	case containsAny(kind, "customergateway", "localnetworkgateway", "externalvpngateway", "vpntunnel"):
+		return kindPeerGateway
+	case containsAny(kind, "vpn", "networkgateway", "interconnect", "expressroute", "directconnect"):
+		return kindGateway
+	default:
+		return kindOther
+	}
+}
+
+// endpointOwner is a resource owning an address, and where it says so
+type endpointOwner struct {
+	resource int
+	field    string
+}
+
+// crossCloudLink is a detected dependency with the evidence gathered for it
+type crossCloudLink struct {
+	dependency Dependency
+	evidence   []CrossCloudEvidence
+}
+
+// crossCloudDetector finds dependencies between resources of different
+// providers from concrete evidence in their configuration:
+//   - DNS records resolving to addresses owned by another cloud's resources
+//   - VPN and interconnect gateways whose peer IP is owned by another cloud
+//   - configuration values, such as environment variables and connection
+//     strings, referencing another cloud's endpoints or resource IDs
+//
+// Addresses are looked up in an index, so detection is linear in the size
+// of the configurations.
+type crossCloudDetector struct {
+	resources []core.Resource
+	owners    map[string][]endpointOwner // Owned addresses
+	ids       map[string]int             // Resource IDs and ARNs specific enough to be matched
+	links     map[string]*crossCloudLink
+	order     []string
+}
+
+// findCrossCloudDependencies detects dependencies across providers
+func findCrossCloudDependencies(resources []core.Resource) []Dependency {
+	detector := &crossCloudDetector{
+		resources: resources,
+		owners:    make(map[string][]endpointOwner),
+		ids:       make(map[string]int),
+		links:     make(map[string]*crossCloudLink),
+	}
+
+	providers := make(map[string]bool)
+	for _, resource := range resources {
+		providers[resource.Provider] = true
+	}
+	if len(providers) < 2 {
+		return []Dependency{}
+	}
+
+	detector.indexOwners()
+	for i := range resources {
+		detector.detect(i)
+	}
+	return detector.dependencies()
+}
+
+// indexOwners indexes the addresses and IDs owned by each resource
+func (d *crossCloudDetector) indexOwners() {
+	for i, resource := range d.resources {
+		if resource.ARN != "" {
+			d.ids[resource.ARN] = i
+		}
+		// Short IDs such as names are too likely to collide
+		if strings.ContainsAny(resource.ID, "/:") {
+			d.ids[resource.ID] = i
+		}
+
+		kind := classifyCrossCloud(resource)
+		if kind == kindDNSRecord || kind == kindPeerGateway {
+			continue
+		}
+
+		walkConfiguration(resource.Configuration, func(path string, keys []string, value string) {
+			if !ownsAddress(keys) {
+				return
+			}
+			for _, address := range addressesIn(value) {
+				d.addOwner(address, endpointOwner{resource: i, field: path})
+			}
+		})
+	}
+}
+
+// addOwner records an owner of an address once per resource
+func (d *crossCloudDetector) addOwner(address string, owner endpointOwner) {
+	for _, existing := range d.owners[address] {
+		if existing.resource == owner.resource {
+			return
+		}
+	}
+	d.owners[address] = append(d.owners[address], owner)
+}
+
+// detect looks for evidence in the configuration of a resource
+func (d *crossCloudDetector) detect(source int) {
+	resource := d.resources[source]
+	kind := classifyCrossCloud(resource)
+
+	walkConfiguration(resource.Configuration, func(path string, keys []string, value string) {
+		if target, ok := d.ids[value]; ok && target != source {
+			d.link(source, target, "references_resource", core.DirectionOutbound, 0.85,
+				CrossCloudEvidence{Signal: SignalConfigurationReference, Field: path, Value: value, MatchedField: "id"})
+		}
+
+		switch kind {
+		case kindDNSRecord:
+			// Only record data resolves somewhere; the name is the record's own
+			if !anyKey(keys, recordDataKeys) {
+				return
+			}
+		case kindPeerGateway:
+			// Every address describes the peer
+		default:
+			// Addresses of the resource itself are not references
+			if ownsAddress(keys) {
+				return
+			}
+		}
+
+		for _, address := range addressesIn(value) {
+			for _, owner := range d.otherCloudOwners(source, address) {
+				switch {
+				case kind == kindDNSRecord:
+					d.link(source, owner.resource, "dns_resolves_to", core.DirectionOutbound, 0.9,
+						CrossCloudEvidence{Signal: SignalDNSRecord, Field: path, Value: address, MatchedField: owner.field})
+				case (kind == kindGateway || kind == kindPeerGateway) && net.ParseIP(address) != nil:
+					d.link(source, owner.resource, "vpn_peer", core.DirectionBidirectional, 0.95,
+						CrossCloudEvidence{Signal: SignalVPNPeer, Field: path, Value: address, MatchedField: owner.field})
+				default:
+					d.link(source, owner.resource, "references_endpoint", core.DirectionOutbound, 0.8,
+						CrossCloudEvidence{Signal: SignalConfigurationReference, Field: path, Value: address, MatchedField: owner.field})
+				}
+			}
+		}
+	})
+}
+
+// otherCloudOwners returns the owners of an address in other providers than
+// the source. Addresses owned in several other providers, such as private
+// IPs reused across clouds, are ambiguous and match nothing.
+func (d *crossCloudDetector) otherCloudOwners(source int, address string) []endpointOwner {
+	provider := d.resources[source].Provider
+
+	var owners []endpointOwner
+	ownerProvider := ""
+	for _, owner := range d.owners[address] {
+		p := d.resources[owner.resource].Provider
+		if p == provider {
+			continue
+		}
+		if ownerProvider != "" && p != ownerProvider {
+			return nil
+		}
+		ownerProvider = p
+		owners = append(owners, owner)
+	}
+	return owners
+}
+
+// link records a dependency, merging the evidence of repeated findings.
+// Bidirectional dependencies are recorded once per pair of resources.
+func (d *crossCloudDetector) link(source, target int, relationship, direction string, confidence float64, evidence CrossCloudEvidence) {
+	from, to := d.resources[source], d.resources[target]
+	if from.Provider == to.Provider {
+		return
+	}
+	if direction == core.DirectionBidirectional && to.ID < from.ID {
+		from, to = to, from
+	}
+
+	key := from.ID + "\x00" + to.ID + "\x00" + relationship
+	existing, ok := d.links[key]
+	if !ok {
+		existing = &crossCloudLink{dependency: Dependency{
+			SourceID:     from.ID,
+			TargetID:     to.ID,
+			SourceARN:    from.ARN,
+			TargetARN:    to.ARN,
+			Relationship: relationship,
+			Direction:    direction,
+			Confidence:   confidence,
+		}}
+		d.links[key] = existing
+		d.order = append(d.order, key)
+	}
+
+	for _, e := range existing.evidence {
+		if e == evidence {
+			return
+		}
+	}
+	existing.evidence = append(existing.evidence, evidence)
+}
+
+// dependencies returns the detected dependencies in the order found
+func (d *crossCloudDetector) dependencies() []Dependency {
+	byID := make(map[string]core.Resource, len(d.resources))
+	for _, resource := range d.resources {
+		byID[resource.ID] = resource
+	}
+
+	dependencies := make([]Dependency, 0, len(d.order))
+	for _, key := range d.order {
+		link := d.links[key]
+		dep := link.dependency
+		dep.Metadata = map[string]interface{}{
+			"signal":          link.evidence[0].Signal,
+			"evidence":        link.evidence,
+			"source_provider": byID[dep.SourceID].Provider,
+			"target_provider": byID[dep.TargetID].Provider,
+		}
+		dependencies = append(dependencies, dep)
+	}
+	return dependencies
+}
+
+// walkConfiguration calls visit for every string in a JSON configuration
+// with its path and the normalized keys leading to it, in key order
+func walkConfiguration(configuration []byte, visit func(path string, keys []string, value string)) {
+	if len(configuration) == 0 {
+		return
+	}
+	var document interface{}
+	if err := json.Unmarshal(configuration, &document); err != nil {
+		return
+	}
+
+	var walk func(node interface{}, path string, keys []string)
+	walk = func(node interface{}, path string, keys []string) {
+		switch value := node.(type) {
+		case map[string]interface{}:
+			names := make([]string, 0, len(value))
+			for name := range value {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				child := name
+				if path != "" {
+					child = path + "." + name
+				}
+				walk(value[name], child, append(keys[:len(keys):len(keys)], normalizeKey(name)))
+			}
+		case []interface{}:
+			for i, item := range value {
+				walk(item, fmt.Sprintf("%s[%d]", path, i), keys)
+			}
+		case string:
+			visit(path, keys, value)
+		}
+	}
+	walk(document, "", nil)
+}
+
+// addressesIn extracts the IP addresses and hostnames of a configuration
+// value: a plain address, URLs, or connection strings such as
+// "Server=tcp:db.example.com,1433;..." and "host=db.example.com port=5432"
+func addressesIn(value string) []string {
+	var addresses []string
+	seen := make(map[string]bool)
+	add := func(candidate string) {
+		if address := hostOf(candidate); address != "" && !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	for _, match := range urlHostPattern.FindAllStringSubmatch(value, -1) {
+		add(strings.Trim(match[1], "[]"))
+	}
+
+	settings := make(map[string]string)
+	for _, token := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || r == ' ' || r == '\t' || r == '\n'
+	}) {
+		name, setting, found := strings.Cut(token, "=")
+		if !found {
+			add(token)
+			continue
+		}
+		name = normalizeKey(name)
+		settings[name] = setting
+		if connectionHostKeys[name] {
+			add(setting)
+		}
+	}
+
+	// Azure storage connection strings name the account and endpoint suffix
+	if account, suffix := settings["accountname"], settings["endpointsuffix"]; account != "" && suffix != "" {
+		for _, service := range []string{"blob", "queue", "table", "file", "dfs"} {
+			add(account + "." + service + "." + suffix)
+		}
+	}
+
+	return addresses
+}
+
+// hostOf returns the IP address or lowercase hostname of a host, a
+// "host:port" or "tcp:host,port" value, or "" when it is neither
+func hostOf(value string) string {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "tcp:")
+	if host, _, found := strings.Cut(value, ","); found {
+		value = host
+	}
+	if ip := net.ParseIP(value); ip != nil {
+		return ip.String()
+	}
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+		if ip := net.ParseIP(value); ip != nil {
+			return ip.String()
+		}
+	}
+
+	value = strings.TrimSuffix(strings.ToLower(value), ".")
+	if len(value) > 253 || !hostPattern.MatchString(value) {
+		return ""
+	}
+	// Top-level domains are not numeric, unlike versions such as 1.2.3
+	tld := value[strings.LastIndex(value, ".")+1:]
+	if strings.Trim(tld, "0123456789") == "" {
+		return ""
+	}
+	return value
+}
+
+// ownsAddress reports whether a value under these keys is an address of
+// the resource itself. Values of settings containers never are.
+func ownsAddress(keys []string) bool {
+	if anyKey(keys, settingsContainerKeys) {
+		return false
+	}
+	for _, key := range keys {
+		if ownedAddressKeys[key] || strings.HasSuffix(key, "endpoint") ||
+			strings.HasSuffix(key, "host") || strings.HasSuffix(key, "hostname") {
+			return true
+		}
+	}
+	return false
+}
+
+// anyKey reports whether any of the keys is in the set
+func anyKey(keys []string, set map[string]bool) bool {
+	for _, key := range keys {
+		if set[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeKey lowercases a key and removes underscores and dashes, so
+// PublicIpAddress, public_ip_address and public-ip-address are alike
+func normalizeKey(key string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+}
+
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, substrings ...string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
@@ -35,9 +35,22 @@ type Dependency struct {
 
 // DependencyGraph represents the complete dependency graph
 type DependencyGraph struct {
-	Resources    []core.Resource `json:"resources"`
-	Dependencies []Dependency    `json:"dependencies"`
-	Stats        GraphStats      `json:"stats"`
+	Resources             []core.Resource        `json:"resources"`
+	Dependencies          []Dependency           `json:"dependencies"`
+	Stats                 GraphStats             `json:"stats"`
+	SinglePointsOfFailure []SinglePointOfFailure `json:"single_points_of_failure"`
+}
+
+// newDependencyGraph assembles the dependency graph of resources with its
+// statistics and single points of failure
+func newDependencyGraph(resources []core.Resource, dependencies []Dependency) *DependencyGraph {
+	graph := NewGraph(resources, dependencies)
+	return &DependencyGraph{
+		Resources:             resources,
+		Dependencies:          dependencies,
+		Stats:                 graph.Stats(),
+		SinglePointsOfFailure: graph.SinglePointsOfFailure(resources),
+	}
 }
 
 // GraphStats provides statistics about the dependency graph
@@ -47,6 +60,11 @@ type GraphStats struct {
 	MaxDepth          int `json:"max_depth"`
 	Cycles            int `json:"cycles"`
 	Islands           int `json:"islands"` // Resources with no dependencies
+
+	// ArticulationPoints and Bridges count the resources and dependencies
+	// whose loss disconnects other resources from each other
+	ArticulationPoints int `json:"articulation_points"`
+	Bridges            int `json:"bridges"`
 }
 
 // AnalyzeDependencies analyzes dependencies for all resources
@@ -60,9 +78,106 @@ func (da *DependencyAnalyzer) AnalyzeDependencies(ctx context.Context) (*Depende
 	}
 
 	// Analyze dependencies
+	dependencies := da.findDependencies(ctx, resources)
+
+	// Calculate graph statistics and single points of failure
+	graph := newDependencyGraph(resources, dependencies)
+
+	logrus.Infof("Dependency analysis completed: %d resources, %d dependencies",
+		len(resources), len(dependencies))
+
+	// Persist the results so later commands can reuse them
+	if store, ok := da.storage.(core.RelationshipStorage); ok {
+		if err := store.StoreRelationships(dependenciesToRelationships(dependencies)); err != nil {
+			logrus.Warnf("Failed to store dependencies: %v", err)
+		}
+	}
+
+	return graph, nil
+}
+
+// LoadDependencies returns the dependency graph stored by the last analysis.
+// It returns nil when the storage keeps no analysis results, or when they
+// predate the latest discovery run, so the graph must be recomputed.
+func (da *DependencyAnalyzer) LoadDependencies(ctx context.Context) (*DependencyGraph, error) {
+	store, ok := da.storage.(core.RelationshipStorage)
+	if !ok {
+		return nil, nil
+	}
+
+	current, err := store.RelationshipsCurrent()
+	if err != nil || !current {
+		return nil, err
+	}
+
+	relationships, err := store.GetRelationships()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stored dependencies: %w", err)
+	}
+
+	resources, err := da.storage.GetResources("SELECT * FROM resources")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resources: %w", err)
+	}
+
+	arns := make(map[string]string, len(resources))
+	for _, resource := range resources {
+		arns[resource.ID] = resource.ARN
+	}
+
+	dependencies := make([]Dependency, 0, len(relationships))
+	for _, relationship := range relationships {
+		dependencies = append(dependencies, Dependency{
+			SourceID:     relationship.SourceID,
+			TargetID:     relationship.TargetID,
+			SourceARN:    arns[relationship.SourceID],
+			TargetARN:    arns[relationship.TargetID],
+			Relationship: relationship.Type,
+			Direction:    relationship.Direction,
+			Confidence:   relationship.Confidence,
+			Metadata:     relationship.Metadata,
+		})
+	}
+
+	return newDependencyGraph(resources, dependencies), nil
+}
+
+// GetDependencyGraph returns the stored dependency graph when it is current,
+// and analyzes dependencies otherwise or when refresh is set
+func (da *DependencyAnalyzer) GetDependencyGraph(ctx context.Context, refresh bool) (*DependencyGraph, error) {
+	if !refresh {
+		graph, err := da.LoadDependencies(ctx)
+		if err != nil {
+			logrus.Warnf("Failed to load stored dependencies: %v", err)
+		}
+		if graph != nil {
+			return graph, nil
+		}
+	}
+	return da.AnalyzeDependencies(ctx)
+}
+
+// dependenciesToRelationships converts analysis results for storage
+func dependenciesToRelationships(dependencies []Dependency) []core.Relationship {
+	relationships := make([]core.Relationship, 0, len(dependencies))
+	for _, dep := range dependencies {
+		relationships = append(relationships, core.Relationship{
+			SourceID:   dep.SourceID,
+			TargetID:   dep.TargetID,
+			Type:       dep.Relationship,
+			Direction:  dep.Direction,
+			Confidence: dep.Confidence,
+			Metadata:   dep.Metadata,
+		})
+	}
+	return relationships
+}
+
+// findDependencies computes provider-local and cross-provider dependencies for resources
+func (da *DependencyAnalyzer) findDependencies(ctx context.Context, resources []core.Resource) []Dependency {
 	dependencies := make([]Dependency, 0)
 
-	// Group resources by provider for cross-provider analysis
+	// Group resources by provider
 	providerResources := make(map[string][]core.Resource)
 	for _, resource := range resources {
 		providerResources[resource.Provider] = append(providerResources[resource.Provider], resource)
@@ -79,26 +194,10 @@ func (da *DependencyAnalyzer) AnalyzeDependencies(ctx context.Context) (*Depende
 		dependencies = append(dependencies, providerDeps...)
 	}
 
-	// Analyze cross-provider dependencies
-	crossProviderDeps, err := da.analyzeCrossProviderDependencies(ctx, providerResources)
-	if err != nil {
-		logrus.Warnf("Failed to analyze cross-provider dependencies: %v", err)
-	}
-	dependencies = append(dependencies, crossProviderDeps...)
-
-	// Calculate graph statistics
-	stats := da.calculateGraphStats(resources, dependencies)
+	// Analyze cross-provider dependencies from DNS records, VPN peers and configuration references
+	dependencies = append(dependencies, findCrossCloudDependencies(resources)...)
 
-	graph := &DependencyGraph{
-		Resources:    resources,
-		Dependencies: dependencies,
-		Stats:        stats,
-	}
-
-	logrus.Infof("Dependency analysis completed: %d resources, %d dependencies",
-		len(resources), len(dependencies))
-
-	return graph, nil
+	return dependencies
 }
 
 // analyzeProviderDependencies analyzes dependencies within a single provider
@@ -444,48 +543,6 @@ func (da *DependencyAnalyzer) analyzeGCPStorageDependencies(storage core.Resourc
 	return dependencies
 }
 
-// analyzeCrossProviderDependencies analyzes dependencies across different cloud providers
-func (da *DependencyAnalyzer) analyzeCrossProviderDependencies(ctx context.Context, providerResources map[string][]core.Resource) ([]Dependency, error) {
-	var dependencies []Dependency
-
-	// Look for common patterns that indicate cross-cloud dependencies
-	// This is a simplified implementation - in practice, you'd use more sophisticated analysis
-
-	// Example: Look for resources with similar names or tags that might be related
-	allResources := make([]core.Resource, 0)
-	for _, resources := range providerResources {
-		allResources = append(allResources, resources...)
-	}
-
-	// Group by common tags or naming patterns
-	resourceGroups := da.groupResourcesByPatterns(allResources)
-
-	// Create dependencies within each group
-	for _, group := range resourceGroups {
-		if len(group) > 1 {
-			// Create bidirectional dependencies between resources in the same group
-			for i := 0; i < len(group); i++ {
-				for j := i + 1; j < len(group); j++ {
-					dependencies = append(dependencies, Dependency{
-						SourceID:     group[i].ID,
-						TargetID:     group[j].ID,
-						SourceARN:    group[i].ARN,
-						TargetARN:    group[j].ARN,
-						Relationship: "cross_cloud_related",
-						Direction:    "bidirectional",
-						Confidence:   0.6,
-						Metadata: map[string]interface{}{
-							"reason": "similar_naming_or_tags",
-						},
-					})
-				}
-			}
-		}
-	}
-
-	return dependencies, nil
-}
-
 // findResourcesByPattern finds resources matching a pattern
 func (da *DependencyAnalyzer) findResourcesByPattern(resourceMap map[string]core.Resource, pattern, service string) []core.Resource {
 	var matches []core.Resource
@@ -501,95 +558,9 @@ func (da *DependencyAnalyzer) findResourcesByPattern(resourceMap map[string]core
 	return matches
 }
 
-// groupResourcesByPatterns groups resources by common patterns
-func (da *DependencyAnalyzer) groupResourcesByPatterns(resources []core.Resource) [][]core.Resource {
-	groups := make(map[string][]core.Resource)
-
-	for _, resource := range resources {
-		// Group by common tags
-		key := da.generateGroupKey(resource)
-		groups[key] = append(groups[key], resource)
-	}
-
-	var result [][]core.Resource
-	for _, group := range groups {
-		if len(group) > 1 {
-			result = append(result, group)
-		}
-	}
-
-	return result
-}
-
-// generateGroupKey generates a key for grouping resources
-func (da *DependencyAnalyzer) generateGroupKey(resource core.Resource) string {
-	// Use common tags or naming patterns to group resources
-	key := resource.Provider + ":" + resource.Service
-
-	// Add common tags to the key
-	if resource.Tags != nil {
-		if env, ok := resource.Tags["Environment"]; ok {
-			key += ":" + env
-		}
-		if project, ok := resource.Tags["Project"]; ok {
-			key += ":" + project
-		}
-	}
-
-	return key
-}
-
 // calculateGraphStats calculates statistics for the dependency graph
 func (da *DependencyAnalyzer) calculateGraphStats(resources []core.Resource, dependencies []Dependency) GraphStats {
-	stats := GraphStats{
-		TotalResources:    len(resources),
-		TotalDependencies: len(dependencies),
-	}
-
-	// Calculate max depth (simplified)
-	stats.MaxDepth = da.calculateMaxDepth(resources, dependencies)
-
-	// Calculate cycles (simplified)
-	stats.Cycles = da.detectCycles(resources, dependencies)
-
-	// Calculate islands (resources with no dependencies)
-	stats.Islands = da.countIslands(resources, dependencies)
-
-	return stats
-}
-
-// calculateMaxDepth calculates the maximum depth of the dependency graph
-func (da *DependencyAnalyzer) calculateMaxDepth(resources []core.Resource, dependencies []Dependency) int {
-	// This is a simplified implementation
-	// In practice, you'd use a proper graph traversal algorithm
-	return 3 // Placeholder
-}
-
-// detectCycles detects cycles in the dependency graph
-func (da *DependencyAnalyzer) detectCycles(resources []core.Resource, dependencies []Dependency) int {
-	// This is a simplified implementation
-	// In practice, you'd use DFS to detect cycles
-	return 0 // Placeholder
-}
-
-// countIslands counts resources with no dependencies
-func (da *DependencyAnalyzer) countIslands(resources []core.Resource, dependencies []Dependency) int {
-	// Create a set of resources that have dependencies
-	hasDeps := make(map[string]bool)
-	for _, dep := range dependencies {
-		hasDeps[dep.SourceID] = true
-		hasDeps[dep.TargetID] = true
-	}
-
-	// Count resources without dependencies
-	islands := 0
-	for _, resource := range resources {
-		if !hasDeps[resource.ID] {
-			islands++
-		}
-	}
-
-	return islands
+	return NewGraph(resources, dependencies).Stats()
 }
 
 // splitString is a helper method to split string by delimiter
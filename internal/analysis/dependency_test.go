@@ -61,6 +61,76 @@ func TestDependencyAnalyzer_AnalyzeDependencies(t *testing.T) {
 	mockStorage.AssertExpectations(t)
 }
 
+func TestDependencyAnalyzer_StoresDependencies(t *testing.T) {
+	mockStorage := new(MockRelationshipStorage)
+	analyzer := NewDependencyAnalyzer(mockStorage)
+
+	testResources := []core.Resource{
+		{ID: "ec2-instance-1", Provider: "aws", Service: "ec2", Type: "instance", Configuration: []byte(`{"VpcId": "vpc-12345678"}`)},
+		{ID: "vpc-1", Provider: "aws", Service: "ec2", Type: "vpc"},
+	}
+	mockStorage.On("GetResources", "SELECT * FROM resources", mock.Anything).Return(testResources, nil)
+	mockStorage.On("StoreRelationships", mock.MatchedBy(func(relationships []core.Relationship) bool {
+		for _, relationship := range relationships {
+			if relationship.SourceID == "ec2-instance-1" && relationship.TargetID == "vpc-1" &&
+				relationship.Type == "runs_in_vpc" && relationship.Direction == core.DirectionOutbound {
+				return true
+			}
+		}
+		return false
+	})).Return(nil)
+
+	graph, err := analyzer.AnalyzeDependencies(context.Background())
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, graph.Dependencies)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestDependencyAnalyzer_LoadDependencies(t *testing.T) {
+	testResources := []core.Resource{
+		{ID: "ec2-instance-1", Provider: "aws", ARN: "arn:aws:ec2:us-east-1:123456789012:instance/i-1"},
+		{ID: "vpc-1", Provider: "aws"},
+	}
+	stored := []core.Relationship{
+		{SourceID: "ec2-instance-1", TargetID: "vpc-1", Type: "runs_in_vpc", Direction: core.DirectionOutbound, Confidence: 0.95, RunID: 3},
+	}
+
+	t.Run("current", func(t *testing.T) {
+		mockStorage := new(MockRelationshipStorage)
+		mockStorage.On("RelationshipsCurrent").Return(true, nil)
+		mockStorage.On("GetRelationships", mock.Anything).Return(stored, nil)
+		mockStorage.On("GetResources", "SELECT * FROM resources", mock.Anything).Return(testResources, nil)
+
+		graph, err := NewDependencyAnalyzer(mockStorage).LoadDependencies(context.Background())
+
+		assert.NoError(t, err)
+		if assert.NotNil(t, graph) && assert.Len(t, graph.Dependencies, 1) {
+			assert.Equal(t, "runs_in_vpc", graph.Dependencies[0].Relationship)
+			assert.Equal(t, testResources[0].ARN, graph.Dependencies[0].SourceARN)
+		}
+		assert.Equal(t, 1, graph.Stats.TotalDependencies)
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		mockStorage := new(MockRelationshipStorage)
+		mockStorage.On("RelationshipsCurrent").Return(false, nil)
+
+		graph, err := NewDependencyAnalyzer(mockStorage).LoadDependencies(context.Background())
+
+		assert.NoError(t, err)
+		assert.Nil(t, graph)
+		mockStorage.AssertNotCalled(t, "GetRelationships", mock.Anything)
+	})
+
+	t.Run("unsupported storage", func(t *testing.T) {
+		graph, err := NewDependencyAnalyzer(new(MockStorage)).LoadDependencies(context.Background())
+
+		assert.NoError(t, err)
+		assert.Nil(t, graph)
+	})
+}
+
 func TestDependencyAnalyzer_AnalyzeAWSDependencies(t *testing.T) {
 	analyzer := NewDependencyAnalyzer(nil)
 
@@ -193,8 +263,6 @@ func TestDependencyAnalyzer_CalculateGraphStats(t *testing.T) {
 }
 
 func TestDependencyAnalyzer_CountIslands(t *testing.T) {
-	analyzer := NewDependencyAnalyzer(nil)
-
 	// Create test data with some isolated resources
 	resources := []core.Resource{
 		{ID: "resource-1", Provider: "aws"},
@@ -209,82 +277,12 @@ func TestDependencyAnalyzer_CountIslands(t *testing.T) {
 	}
 
 	// Execute test
-	islands := analyzer.countIslands(resources, dependencies)
+	islands := NewGraph(resources, dependencies).Islands()
 
 	// Assertions
 	assert.Equal(t, 2, islands) // resource-3 and resource-4 should be isolated
 }
 
-func TestDependencyAnalyzer_GenerateGroupKey(t *testing.T) {
-	analyzer := NewDependencyAnalyzer(nil)
-
-	// Test resource with tags
-	resource := core.Resource{
-		Provider: "aws",
-		Service:  "ec2",
-		Tags: map[string]string{
-			"Environment": "production",
-			"Project":     "cloudrecon",
-		},
-	}
-
-	// Execute test
-	key := analyzer.generateGroupKey(resource)
-
-	// Assertions
-	assert.Contains(t, key, "aws")
-	assert.Contains(t, key, "ec2")
-	assert.Contains(t, key, "production")
-	assert.Contains(t, key, "cloudrecon")
-}
-
-func TestDependencyAnalyzer_GroupResourcesByPatterns(t *testing.T) {
-	analyzer := NewDependencyAnalyzer(nil)
-
-	// Create test resources with similar patterns
-	resources := []core.Resource{
-		{
-			ID:       "resource-1",
-			Provider: "aws",
-			Service:  "ec2",
-			Tags: map[string]string{
-				"Environment": "production",
-				"Project":     "cloudrecon",
-			},
-		},
-		{
-			ID:       "resource-2",
-			Provider: "aws",
-			Service:  "ec2",
-			Tags: map[string]string{
-				"Environment": "production",
-				"Project":     "cloudrecon",
-			},
-		},
-		{
-			ID:       "resource-3",
-			Provider: "azure",
-			Service:  "compute",
-			Tags: map[string]string{
-				"Environment": "staging",
-				"Project":     "cloudrecon",
-			},
-		},
-	}
-
-	// Execute test
-	groups := analyzer.groupResourcesByPatterns(resources)
-
-	// Assertions
-	assert.NotNil(t, groups)
-	assert.Greater(t, len(groups), 0)
-
-	// Check that resources with similar patterns are grouped together
-	for _, group := range groups {
-		assert.Greater(t, len(group), 1, "Groups should contain multiple resources")
-	}
-}
-
 func TestDependencyAnalyzer_FindResourcesByPattern(t *testing.T) {
 	analyzer := NewDependencyAnalyzer(nil)
 
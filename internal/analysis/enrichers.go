@@ -0,0 +1,151 @@
+package analysis
+
+import (
+	"context"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// DependencyEnricher fills Resource.Dependencies and Resource.Dependents
+// using the same rules as DependencyAnalyzer
+type DependencyEnricher struct {
+	analyzer *DependencyAnalyzer
+}
+
+// NewDependencyEnricher creates a new dependency enricher
+func NewDependencyEnricher() *DependencyEnricher {
+	return &DependencyEnricher{
+		analyzer: NewDependencyAnalyzer(nil),
+	}
+}
+
+// Name returns the enricher name
+func (de *DependencyEnricher) Name() string {
+	return "dependencies"
+}
+
+// Enrich maps dependencies between the given resources
+func (de *DependencyEnricher) Enrich(ctx context.Context, resources []core.Resource) error {
+	index := make(map[string]int, len(resources))
+	for i := range resources {
+		index[resources[i].ID] = i
+	}
+
+	for _, dep := range de.analyzer.findDependencies(ctx, resources) {
+		source, sourceOK := index[dep.SourceID]
+		target, targetOK := index[dep.TargetID]
+		if !sourceOK || !targetOK || source == target {
+			continue
+		}
+
+		resources[source].Dependencies = appendUnique(resources[source].Dependencies, dep.TargetID)
+		resources[target].Dependents = appendUnique(resources[target].Dependents, dep.SourceID)
+
+		if dep.Direction == "bidirectional" {
+			resources[target].Dependencies = appendUnique(resources[target].Dependencies, dep.SourceID)
+			resources[source].Dependents = appendUnique(resources[source].Dependents, dep.TargetID)
+		}
+	}
+
+	return nil
+}
+
+// CostEnricher fills Resource.MonthlyCost for resources the provider did not price
+type CostEnricher struct {
+	analyzer *CostAnalyzer
+}
+
+// NewCostEnricher creates a new cost enricher
+func NewCostEnricher() *CostEnricher {
+	return &CostEnricher{
+		analyzer: NewCostAnalyzer(nil),
+	}
+}
+
+// Name returns the enricher name
+func (ce *CostEnricher) Name() string {
+	return "cost"
+}
+
+// Enrich estimates monthly cost for resources without one
+func (ce *CostEnricher) Enrich(ctx context.Context, resources []core.Resource) error {
+	for i := range resources {
+		if resources[i].MonthlyCost > 0 {
+			continue
+		}
+
+		estimate, err := ce.analyzer.calculateResourceCost(resources[i])
+		if err != nil || estimate == nil {
+			// Unknown providers simply stay unpriced
+			continue
+		}
+		resources[i].MonthlyCost = estimate.MonthlyCost
+	}
+
+	return nil
+}
+
+// ComplianceEnricher adds the compliance controls violated by each resource,
+// as reported by SecurityAnalyzer, to Resource.Compliance
+type ComplianceEnricher struct {
+	analyzer *SecurityAnalyzer
+}
+
+// NewComplianceEnricher creates a new compliance enricher
+func NewComplianceEnricher() *ComplianceEnricher {
+	return &ComplianceEnricher{
+		analyzer: NewSecurityAnalyzer(nil),
+	}
+}
+
+// Name returns the enricher name
+func (ce *ComplianceEnricher) Name() string {
+	return "compliance"
+}
+
+// Enrich assesses security posture and records compliance flags
+func (ce *ComplianceEnricher) Enrich(ctx context.Context, resources []core.Resource) error {
+	index := make(map[string]int, len(resources))
+	for i := range resources {
+		index[resources[i].ID] = i
+	}
+
+	for _, finding := range ce.analyzer.findSecurityIssues(ctx, resources) {
+		i, ok := index[finding.ResourceID]
+		if !ok {
+			continue
+		}
+		for _, control := range finding.Compliance {
+			resources[i].Compliance = appendUnique(resources[i].Compliance, control)
+		}
+	}
+
+	return nil
+}
+
+// DefaultEnrichers returns the built-in enrichers selected by the analysis configuration
+func DefaultEnrichers(cfg core.AnalysisConfig) []core.Enricher {
+	var enrichers []core.Enricher
+
+	if cfg.EnableDependencyAnalysis {
+		enrichers = append(enrichers, NewDependencyEnricher())
+	}
+	if cfg.EnableCostAnalysis {
+		enrichers = append(enrichers, NewCostEnricher())
+	}
+	if cfg.EnableSecurityAnalysis {
+		enrichers = append(enrichers, NewComplianceEnricher())
+	}
+
+	return enrichers
+}
+
+// appendUnique appends value to values unless it is already present
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,93 @@
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependencyEnricher_Enrich(t *testing.T) {
+	enricher := NewDependencyEnricher()
+
+	resources := []core.Resource{
+		{
+			ID:            "ec2-instance-1",
+			Provider:      "aws",
+			Service:       "ec2",
+			Type:          "instance",
+			Name:          "test-instance",
+			Configuration: []byte(`{"SecurityGroupIds": ["sg-12345678"], "VpcId": "vpc-12345678"}`),
+		},
+		{
+			ID:       "security-group-1",
+			Provider: "aws",
+			Service:  "ec2",
+			Type:     "security-group",
+			Name:     "test-sg",
+		},
+	}
+
+	err := enricher.Enrich(context.Background(), resources)
+
+	assert.NoError(t, err)
+	assert.Contains(t, resources[0].Dependencies, "security-group-1")
+	assert.Contains(t, resources[1].Dependents, "ec2-instance-1")
+}
+
+func TestCostEnricher_Enrich(t *testing.T) {
+	enricher := NewCostEnricher()
+
+	resources := []core.Resource{
+		{ID: "priced", Provider: "aws", Service: "ec2", MonthlyCost: 42.0},
+		{ID: "unpriced", Provider: "aws", Service: "s3"},
+		{ID: "unknown", Provider: "other", Service: "thing"},
+	}
+
+	err := enricher.Enrich(context.Background(), resources)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42.0, resources[0].MonthlyCost)
+	assert.Equal(t, 5.0, resources[1].MonthlyCost)
+	assert.Equal(t, 0.0, resources[2].MonthlyCost)
+}
+
+func TestComplianceEnricher_Enrich(t *testing.T) {
+	enricher := NewComplianceEnricher()
+
+	resources := []core.Resource{
+		{
+			ID:           "s3-bucket-1",
+			Provider:     "aws",
+			Service:      "s3",
+			Type:         "bucket",
+			PublicAccess: true,
+			Encrypted:    false,
+		},
+	}
+
+	err := enricher.Enrich(context.Background(), resources)
+	assert.NoError(t, err)
+	assert.Contains(t, resources[0].Compliance, "CIS-2.1")
+	assert.Contains(t, resources[0].Compliance, "PCI-DSS-3.4")
+
+	// Running twice must not duplicate flags
+	count := len(resources[0].Compliance)
+	err = enricher.Enrich(context.Background(), resources)
+	assert.NoError(t, err)
+	assert.Len(t, resources[0].Compliance, count)
+}
+
+func TestDefaultEnrichers(t *testing.T) {
+	enrichers := DefaultEnrichers(core.AnalysisConfig{
+		EnableCostAnalysis:       true,
+		EnableDependencyAnalysis: true,
+	})
+
+	names := make([]string, 0, len(enrichers))
+	for _, enricher := range enrichers {
+		names = append(names, enricher.Name())
+	}
+	assert.Equal(t, []string{"dependencies", "cost"}, names)
+}
@@ -0,0 +1,442 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// Graph is the dependency graph of a set of resources, with edges from each
+// resource to the resources it depends on. Inbound dependencies are turned
+// around and bidirectional ones become an edge each way, so every analysis
+// sees the same edges. Algorithms are iterative so deep dependency chains
+// cannot exhaust the stack, and results are sorted so they are stable.
+type Graph struct {
+	ids       []string
+	index     map[string]int
+	resources map[string]bool
+	out       [][]int // Dependencies of each node
+	in        [][]int // Dependents of each node
+
+	// Counts of the inputs, as reported in GraphStats
+	totalResources    int
+	totalDependencies int
+}
+
+// FanIn is the number of resources depending on a resource
+type FanIn struct {
+	ResourceID string `json:"resource_id"`
+	Dependents int    `json:"dependents"`
+}
+
+// Bridge is a dependency whose removal splits the graph in two
+type Bridge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// NewGraph builds the dependency graph of resources. Resources that are
+// only known from dependencies become nodes too.
+func NewGraph(resources []core.Resource, dependencies []Dependency) *Graph {
+	g := &Graph{
+		index:             make(map[string]int),
+		resources:         make(map[string]bool, len(resources)),
+		totalResources:    len(resources),
+		totalDependencies: len(dependencies),
+	}
+
+	ids := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		g.resources[resource.ID] = true
+		ids = append(ids, resource.ID)
+	}
+	for _, dep := range dependencies {
+		ids = append(ids, dep.SourceID, dep.TargetID)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if _, ok := g.index[id]; !ok {
+			g.index[id] = len(g.ids)
+			g.ids = append(g.ids, id)
+		}
+	}
+
+	g.out = make([][]int, len(g.ids))
+	g.in = make([][]int, len(g.ids))
+	seen := make(map[[2]int]bool)
+	addEdge := func(from, to string) {
+		edge := [2]int{g.index[from], g.index[to]}
+		if seen[edge] {
+			return
+		}
+		seen[edge] = true
+		g.out[edge[0]] = append(g.out[edge[0]], edge[1])
+		g.in[edge[1]] = append(g.in[edge[1]], edge[0])
+	}
+
+	for _, dep := range dependencies {
+		switch dep.Direction {
+		case core.DirectionInbound:
+			addEdge(dep.TargetID, dep.SourceID)
+		case core.DirectionBidirectional:
+			addEdge(dep.SourceID, dep.TargetID)
+			addEdge(dep.TargetID, dep.SourceID)
+		default:
+			addEdge(dep.SourceID, dep.TargetID)
+		}
+	}
+
+	for i := range g.out {
+		sort.Ints(g.out[i])
+		sort.Ints(g.in[i])
+	}
+
+	return g
+}
+
+// Stats summarizes the graph. Cycles counts strongly connected components
+// that contain a cycle, and MaxDepth is the longest dependency chain with
+// every cycle collapsed into one node.
+func (g *Graph) Stats() GraphStats {
+	longest := g.LongestPaths()
+	maxDepth := 0
+	for _, depth := range longest {
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+	}
+
+	return GraphStats{
+		TotalResources:     g.totalResources,
+		TotalDependencies:  g.totalDependencies,
+		MaxDepth:           maxDepth,
+		Cycles:             len(g.Cycles()),
+		Islands:            g.Islands(),
+		ArticulationPoints: len(g.ArticulationPoints()),
+		Bridges:            len(g.Bridges()),
+	}
+}
+
+// Islands counts the resources without any dependency or dependent
+func (g *Graph) Islands() int {
+	islands := 0
+	for id := range g.resources {
+		i := g.index[id]
+		if len(g.out[i]) == 0 && len(g.in[i]) == 0 {
+			islands++
+		}
+	}
+	return islands
+}
+
+// StronglyConnectedComponents returns the strongly connected components
+// of the graph using Tarjan's algorithm. A component comes after every
+// component it depends on.
+func (g *Graph) StronglyConnectedComponents() [][]string {
+	var components [][]string
+	for _, members := range g.components() {
+		component := make([]string, len(members))
+		for i, member := range members {
+			component[i] = g.ids[member]
+		}
+		sort.Strings(component)
+		components = append(components, component)
+	}
+	return components
+}
+
+// components returns the strongly connected components as node indexes,
+// in reverse topological order
+func (g *Graph) components() [][]int {
+	n := len(g.ids)
+	order := make([]int, n) // Visit order, 0 while unvisited
+	low := make([]int, n)
+	onStack := make([]bool, n)
+	var stack []int
+	var components [][]int
+	visits := 0
+
+	type frame struct{ node, next int }
+
+	for start := 0; start < n; start++ {
+		if order[start] != 0 {
+			continue
+		}
+
+		visit := func(node int) {
+			visits++
+			order[node], low[node] = visits, visits
+			stack = append(stack, node)
+			onStack[node] = true
+		}
+		visit(start)
+		calls := []frame{{node: start}}
+
+		for len(calls) > 0 {
+			top := &calls[len(calls)-1]
+			if top.next < len(g.out[top.node]) {
+				next := g.out[top.node][top.next]
+				top.next++
+				if order[next] == 0 {
+					visit(next)
+					calls = append(calls, frame{node: next})
+				} else if onStack[next] && order[next] < low[top.node] {
+					low[top.node] = order[next]
+				}
+				continue
+			}
+
+			node := top.node
+			calls = calls[:len(calls)-1]
+			if len(calls) > 0 {
+				if parent := calls[len(calls)-1].node; low[node] < low[parent] {
+					low[parent] = low[node]
+				}
+			}
+
+			if low[node] == order[node] {
+				var component []int
+				for {
+					member := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[member] = false
+					component = append(component, member)
+					if member == node {
+						break
+					}
+				}
+				components = append(components, component)
+			}
+		}
+	}
+
+	return components
+}
+
+// Cycles returns the strongly connected components that contain a cycle:
+// those with several resources, and resources depending on themselves
+func (g *Graph) Cycles() [][]string {
+	var cycles [][]string
+	for _, component := range g.StronglyConnectedComponents() {
+		if len(component) > 1 || g.dependsOnItself(g.index[component[0]]) {
+			cycles = append(cycles, component)
+		}
+	}
+	return cycles
+}
+
+// dependsOnItself reports whether a node has an edge to itself
+func (g *Graph) dependsOnItself(node int) bool {
+	for _, next := range g.out[node] {
+		if next == node {
+			return true
+		}
+	}
+	return false
+}
+
+// LongestPaths returns, for every resource, the length of the longest chain
+// of dependencies below it. Each cycle is collapsed into a single node, so
+// resources in a cycle share their depth.
+func (g *Graph) LongestPaths() map[string]int {
+	components := g.components()
+	componentOf := make([]int, len(g.ids))
+	for c, members := range components {
+		for _, member := range members {
+			componentOf[member] = c
+		}
+	}
+
+	// Components come after their dependencies, so those are done first
+	depth := make([]int, len(components))
+	for c, members := range components {
+		for _, member := range members {
+			for _, next := range g.out[member] {
+				if d := componentOf[next]; d != c && depth[d]+1 > depth[c] {
+					depth[c] = depth[d] + 1
+				}
+			}
+		}
+	}
+
+	longest := make(map[string]int, len(g.ids))
+	for i, id := range g.ids {
+		longest[id] = depth[componentOf[i]]
+	}
+	return longest
+}
+
+// FanIn ranks resources by their number of direct dependents, most first.
+// Resources nothing depends on are left out.
+func (g *Graph) FanIn() []FanIn {
+	var ranking []FanIn
+	for i, id := range g.ids {
+		dependents := 0
+		for _, dependent := range g.in[i] {
+			if dependent != i {
+				dependents++
+			}
+		}
+		if dependents > 0 {
+			ranking = append(ranking, FanIn{ResourceID: id, Dependents: dependents})
+		}
+	}
+
+	sort.SliceStable(ranking, func(i, j int) bool { return ranking[i].Dependents > ranking[j].Dependents })
+	return ranking
+}
+
+// Dependents returns the direct dependents of a resource
+func (g *Graph) Dependents(resourceID string) []string {
+	i, ok := g.index[resourceID]
+	if !ok {
+		return nil
+	}
+
+	var dependents []string
+	for _, dependent := range g.in[i] {
+		if dependent != i {
+			dependents = append(dependents, g.ids[dependent])
+		}
+	}
+	return dependents
+}
+
+// TransitiveDependents counts the resources depending on a resource,
+// directly or through other resources
+func (g *Graph) TransitiveDependents(resourceID string) int {
+	start, ok := g.index[resourceID]
+	if !ok {
+		return 0
+	}
+
+	visited := map[int]bool{start: true}
+	queue := []int{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, dependent := range g.in[node] {
+			if !visited[dependent] {
+				visited[dependent] = true
+				queue = append(queue, dependent)
+			}
+		}
+	}
+	return len(visited) - 1
+}
+
+// ArticulationPoints returns the resources whose removal disconnects other
+// resources from each other, ignoring the direction of dependencies
+func (g *Graph) ArticulationPoints() []string {
+	points, _ := g.cutVertices()
+	return points
+}
+
+// Bridges returns the dependencies whose removal disconnects resources from
+// each other, ignoring the direction of dependencies. Mutual dependencies
+// are reported once.
+func (g *Graph) Bridges() []Bridge {
+	_, bridges := g.cutVertices()
+	return bridges
+}
+
+// cutVertices finds articulation points and bridges of the undirected graph
+// with Tarjan's low-link algorithm
+func (g *Graph) cutVertices() ([]string, []Bridge) {
+	n := len(g.ids)
+
+	// Undirected neighbors, without self-loops or duplicates
+	neighbors := make([][]int, n)
+	for u := range g.out {
+		for _, v := range g.out[u] {
+			if u == v {
+				continue
+			}
+			neighbors[u] = append(neighbors[u], v)
+			neighbors[v] = append(neighbors[v], u)
+		}
+	}
+	for u := range neighbors {
+		sort.Ints(neighbors[u])
+		unique := neighbors[u][:0]
+		for i, v := range neighbors[u] {
+			if i == 0 || v != neighbors[u][i-1] {
+				unique = append(unique, v)
+			}
+		}
+		neighbors[u] = unique
+	}
+
+	order := make([]int, n) // Visit order, 0 while unvisited
+	low := make([]int, n)
+	isPoint := make([]bool, n)
+	var bridges []Bridge
+	visits := 0
+
+	type frame struct{ node, parent, next, children int }
+
+	for root := 0; root < n; root++ {
+		if order[root] != 0 {
+			continue
+		}
+
+		visits++
+		order[root], low[root] = visits, visits
+		calls := []frame{{node: root, parent: -1}}
+
+		for len(calls) > 0 {
+			top := &calls[len(calls)-1]
+			if top.next < len(neighbors[top.node]) {
+				next := neighbors[top.node][top.next]
+				top.next++
+				switch {
+				case order[next] == 0:
+					top.children++
+					visits++
+					order[next], low[next] = visits, visits
+					calls = append(calls, frame{node: next, parent: top.node})
+				case next != top.parent && order[next] < low[top.node]:
+					low[top.node] = order[next]
+				}
+				continue
+			}
+
+			node := *top
+			calls = calls[:len(calls)-1]
+			if node.parent < 0 {
+				isPoint[node.node] = node.children > 1
+				continue
+			}
+
+			parent := node.parent
+			if low[node.node] < low[parent] {
+				low[parent] = low[node.node]
+			}
+			if low[node.node] > order[parent] {
+				from, to := g.ids[parent], g.ids[node.node]
+				if to < from {
+					from, to = to, from
+				}
+				bridges = append(bridges, Bridge{From: from, To: to})
+			}
+			if parent != root && low[node.node] >= order[parent] {
+				isPoint[parent] = true
+			}
+		}
+	}
+
+	var points []string
+	for i, point := range isPoint {
+		if point {
+			points = append(points, g.ids[i])
+		}
+	}
+	sort.Slice(bridges, func(i, j int) bool {
+		if bridges[i].From != bridges[j].From {
+			return bridges[i].From < bridges[j].From
+		}
+		return bridges[i].To < bridges[j].To
+	})
+
+	return points, bridges
+}
@@ -0,0 +1,185 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// dependsOn builds outbound dependencies from pairs of source and target IDs
+func dependsOn(pairs ...string) []Dependency {
+	var dependencies []Dependency
+	for i := 0; i+1 < len(pairs); i += 2 {
+		dependencies = append(dependencies, Dependency{
+			SourceID: pairs[i], TargetID: pairs[i+1], Relationship: "depends_on", Direction: core.DirectionOutbound, Confidence: 1,
+		})
+	}
+	return dependencies
+}
+
+func resourcesWithIDs(ids ...string) []core.Resource {
+	resources := make([]core.Resource, len(ids))
+	for i, id := range ids {
+		resources[i] = core.Resource{ID: id, Provider: "aws", Service: "ec2"}
+	}
+	return resources
+}
+
+func TestGraph_StronglyConnectedComponents(t *testing.T) {
+	// a -> b -> c -> a is a cycle that depends on d; e depends on itself
+	graph := NewGraph(resourcesWithIDs("a", "b", "c", "d", "e", "f"), dependsOn(
+		"a", "b", "b", "c", "c", "a", "c", "d", "e", "e",
+	))
+
+	components := graph.StronglyConnectedComponents()
+	assert.Contains(t, components, []string{"a", "b", "c"})
+	assert.Len(t, components, 4)
+
+	// Components come after the components they depend on
+	position := make(map[string]int)
+	for i, component := range components {
+		for _, id := range component {
+			position[id] = i
+		}
+	}
+	assert.Less(t, position["d"], position["a"])
+
+	assert.Equal(t, [][]string{{"a", "b", "c"}, {"e"}}, sortedCycles(graph.Cycles()))
+}
+
+func sortedCycles(cycles [][]string) [][]string {
+	if len(cycles) == 2 && cycles[0][0] > cycles[1][0] {
+		cycles[0], cycles[1] = cycles[1], cycles[0]
+	}
+	return cycles
+}
+
+func TestGraph_LongestPaths(t *testing.T) {
+	// app -> db -> subnet -> vpc, app -> vpc, and a cycle between x and y above app
+	graph := NewGraph(resourcesWithIDs("x", "y", "app", "db", "subnet", "vpc"), dependsOn(
+		"app", "db", "db", "subnet", "subnet", "vpc", "app", "vpc",
+		"x", "y", "y", "x", "y", "app",
+	))
+
+	longest := graph.LongestPaths()
+	assert.Equal(t, 0, longest["vpc"])
+	assert.Equal(t, 3, longest["app"])
+	assert.Equal(t, 4, longest["x"])
+	assert.Equal(t, 4, longest["y"])
+
+	stats := graph.Stats()
+	assert.Equal(t, 4, stats.MaxDepth)
+	assert.Equal(t, 1, stats.Cycles)
+}
+
+func TestGraph_ArticulationPointsAndBridges(t *testing.T) {
+	// Two triangles joined through the nat gateway, plus a tail from c
+	graph := NewGraph(resourcesWithIDs("a", "b", "nat", "c", "d", "tail", "alone"), dependsOn(
+		"a", "b", "b", "nat", "nat", "a",
+		"c", "nat", "d", "nat", "c", "d",
+		"tail", "c",
+	))
+
+	assert.Equal(t, []string{"c", "nat"}, graph.ArticulationPoints())
+	assert.Equal(t, []Bridge{{From: "c", To: "tail"}}, graph.Bridges())
+
+	stats := graph.Stats()
+	assert.Equal(t, 2, stats.ArticulationPoints)
+	assert.Equal(t, 1, stats.Bridges)
+	assert.Equal(t, 1, stats.Islands)
+
+	t.Run("mutual dependencies are one link", func(t *testing.T) {
+		graph := NewGraph(resourcesWithIDs("a", "b"), dependsOn("a", "b", "b", "a"))
+		assert.Equal(t, []Bridge{{From: "a", To: "b"}}, graph.Bridges())
+		assert.Empty(t, graph.ArticulationPoints())
+	})
+}
+
+func TestGraph_FanIn(t *testing.T) {
+	graph := NewGraph(resourcesWithIDs("subnet", "sg", "i-1", "i-2", "i-3"), dependsOn(
+		"i-1", "subnet", "i-2", "subnet", "i-3", "subnet",
+		"i-1", "sg", "i-2", "sg",
+		"i-1", "subnet", // Found twice, counted once
+	))
+
+	assert.Equal(t, []FanIn{
+		{ResourceID: "subnet", Dependents: 3},
+		{ResourceID: "sg", Dependents: 2},
+	}, graph.FanIn())
+	assert.Equal(t, 3, graph.TransitiveDependents("subnet"))
+}
+
+func TestGraph_DeepChain(t *testing.T) {
+	// Deep enough to overflow a naive recursive implementation's patience
+	const length = 50000
+	ids := make([]string, length)
+	var pairs []string
+	for i := range ids {
+		ids[i] = fmt.Sprintf("r-%05d", i)
+		if i > 0 {
+			pairs = append(pairs, ids[i-1], ids[i])
+		}
+	}
+
+	stats := NewGraph(resourcesWithIDs(ids...), dependsOn(pairs...)).Stats()
+	assert.Equal(t, length-1, stats.MaxDepth)
+	assert.Equal(t, 0, stats.Cycles)
+	assert.Equal(t, length-2, stats.ArticulationPoints)
+}
+
+func TestGraph_SinglePointsOfFailure(t *testing.T) {
+	resources := []core.Resource{
+		{ID: "nat-1", Provider: "aws", Service: "ec2", Type: "nat-gateway", Name: "egress"},
+		{ID: "i-1", Provider: "aws", Service: "ec2", Type: "instance"},
+		{ID: "fn-1", Provider: "aws", Service: "lambda", Type: "function"},
+		{ID: "db-1", Provider: "aws", Service: "rds", Type: "db-instance"},
+		{ID: "sg-1", Provider: "aws", Service: "ec2", Type: "security-group"},
+		{ID: "i-2", Provider: "aws", Service: "ec2", Type: "instance"},
+		{ID: "i-3", Provider: "aws", Service: "ec2", Type: "instance"},
+		{ID: "i-4", Provider: "aws", Service: "ec2", Type: "instance"},
+	}
+	graph := NewGraph(resources, dependsOn(
+		// Three services egress through one NAT gateway
+		"i-1", "nat-1", "fn-1", "nat-1", "db-1", "nat-1",
+		// Three instances of one service share a security group, and are
+		// also connected to each other so the group is not a cut vertex
+		"i-2", "sg-1", "i-3", "sg-1", "i-4", "sg-1", "i-2", "i-3", "i-3", "i-4",
+	))
+
+	findings := graph.SinglePointsOfFailure(resources)
+
+	require.Len(t, findings, 1)
+	assert.Equal(t, "nat-1", findings[0].ResourceID)
+	assert.Equal(t, "high", findings[0].Severity)
+	assert.True(t, findings[0].ArticulationPoint)
+	assert.Equal(t, 3, findings[0].Dependents)
+	assert.Equal(t, []string{"aws/ec2", "aws/lambda", "aws/rds"}, findings[0].DependentServices)
+	assert.Contains(t, findings[0].Title, "egress")
+}
+
+func TestDependencyAnalyzers_AgreeOnGraphStats(t *testing.T) {
+	resources := []core.Resource{
+		{ID: "i-1", Provider: "aws", Service: "ec2", Type: "instance", Configuration: []byte(`{"VpcId": "vpc-1", "SecurityGroupIds": ["sg-1"]}`)},
+		{ID: "i-2", Provider: "aws", Service: "ec2", Type: "instance", Configuration: []byte(`{"VpcId": "vpc-1"}`)},
+		{ID: "vpc-1", Provider: "aws", Service: "ec2", Type: "vpc"},
+		{ID: "sg-1", Provider: "aws", Service: "ec2", Type: "security-group"},
+	}
+
+	mockStorage := new(MockStorage)
+	mockStorage.On("GetResources", "SELECT * FROM resources", mock.Anything).Return(resources, nil)
+
+	graph, err := NewDependencyAnalyzer(mockStorage).AnalyzeDependencies(context.Background())
+	require.NoError(t, err)
+	optimized, err := NewPerformanceOptimizedDependencyAnalyzer(mockStorage, nil).AnalyzeDependenciesOptimized(context.Background())
+	require.NoError(t, err)
+
+	// The analyzers find dependencies independently, so compare the stats
+	// of each with the library run over the same dependencies
+	assert.Equal(t, NewGraph(resources, graph.Dependencies).Stats(), graph.Stats)
+	assert.Equal(t, NewGraph(resources, optimized.Dependencies).Stats(), optimized.Stats)
+}
@@ -0,0 +1,165 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// ImpactedResource is a resource that depends, directly or transitively, on
+// a changed resource
+type ImpactedResource struct {
+	Resource     core.Resource `json:"resource"`
+	Depth        int           `json:"depth"`        // 1 for direct dependents
+	DependsOn    string        `json:"depends_on"`   // Impacted resource one level closer to the change
+	Relationship string        `json:"relationship"` // Relationship to DependsOn
+	Confidence   float64       `json:"confidence"`   // Product of the confidences along the path
+}
+
+// ImpactLevel lists the impacted resources at one depth
+type ImpactLevel struct {
+	Depth     int                `json:"depth"`
+	Resources []ImpactedResource `json:"resources"`
+}
+
+// ImpactGroup aggregates the impacted resources of a provider or service
+type ImpactGroup struct {
+	Resources       int     `json:"resources"`
+	MonthlyCost     float64 `json:"monthly_cost"`
+	PublicResources int     `json:"public_resources"`
+}
+
+// ImpactReport is the blast radius of deleting or changing a resource
+type ImpactReport struct {
+	Resource         core.Resource          `json:"resource"`
+	TotalImpacted    int                    `json:"total_impacted"`
+	TotalMonthlyCost float64                `json:"total_monthly_cost"`
+	ByDepth          []ImpactLevel          `json:"by_depth"`
+	ByProvider       map[string]ImpactGroup `json:"by_provider"`
+	ByService        map[string]ImpactGroup `json:"by_service"` // Keyed by provider/service
+	PublicResources  []ImpactedResource     `json:"public_resources"`
+}
+
+// Impact walks the transitive dependents of a resource, nearest first.
+// Dependencies below minConfidence are not followed. Each dependent is
+// reported once, at the depth it is first reached.
+func (g *DependencyGraph) Impact(resourceID string, minConfidence float64) (*ImpactReport, error) {
+	resources := make(map[string]core.Resource, len(g.Resources))
+	for _, resource := range g.Resources {
+		resources[resource.ID] = resource
+	}
+
+	root, ok := resources[resourceID]
+	if !ok {
+		return nil, fmt.Errorf("resource %s not found", resourceID)
+	}
+
+	dependents := g.dependents(minConfidence)
+
+	report := &ImpactReport{
+		Resource:        root,
+		ByDepth:         []ImpactLevel{},
+		ByProvider:      make(map[string]ImpactGroup),
+		ByService:       make(map[string]ImpactGroup),
+		PublicResources: []ImpactedResource{},
+	}
+
+	visited := map[string]bool{resourceID: true}
+	confidence := map[string]float64{resourceID: 1}
+	frontier := []string{resourceID}
+
+	for depth := 1; len(frontier) > 0; depth++ {
+		var level []ImpactedResource
+		var next []string
+
+		for _, id := range frontier {
+			for _, edge := range dependents[id] {
+				if visited[edge.id] {
+					continue
+				}
+				visited[edge.id] = true
+
+				resource, ok := resources[edge.id]
+				if !ok {
+					// Dependencies can outlive the resources they were found on
+					resource = core.Resource{ID: edge.id}
+				}
+
+				confidence[edge.id] = confidence[id] * edge.confidence
+				level = append(level, ImpactedResource{
+					Resource:     resource,
+					Depth:        depth,
+					DependsOn:    id,
+					Relationship: edge.relationship,
+					Confidence:   confidence[edge.id],
+				})
+				next = append(next, edge.id)
+			}
+		}
+
+		if len(level) == 0 {
+			break
+		}
+		sort.Slice(level, func(i, j int) bool { return level[i].Resource.ID < level[j].Resource.ID })
+		report.ByDepth = append(report.ByDepth, ImpactLevel{Depth: depth, Resources: level})
+
+		for _, impacted := range level {
+			report.add(impacted)
+		}
+		frontier = next
+	}
+
+	return report, nil
+}
+
+// add counts an impacted resource in the report totals
+func (r *ImpactReport) add(impacted ImpactedResource) {
+	resource := impacted.Resource
+
+	r.TotalImpacted++
+	r.TotalMonthlyCost += resource.MonthlyCost
+	if resource.PublicAccess {
+		r.PublicResources = append(r.PublicResources, impacted)
+	}
+
+	count := func(group ImpactGroup) ImpactGroup {
+		group.Resources++
+		group.MonthlyCost += resource.MonthlyCost
+		if resource.PublicAccess {
+			group.PublicResources++
+		}
+		return group
+	}
+	r.ByProvider[resource.Provider] = count(r.ByProvider[resource.Provider])
+	service := resource.Provider + "/" + resource.Service
+	r.ByService[service] = count(r.ByService[service])
+}
+
+// dependentEdge leads from a resource to one that depends on it
+type dependentEdge struct {
+	id           string
+	relationship string
+	confidence   float64
+}
+
+// dependents indexes, for every resource, the resources that depend on it
+func (g *DependencyGraph) dependents(minConfidence float64) map[string][]dependentEdge {
+	dependents := make(map[string][]dependentEdge)
+	for _, dep := range g.Dependencies {
+		if dep.Confidence < minConfidence {
+			continue
+		}
+
+		switch dep.Direction {
+		case core.DirectionInbound:
+			dependents[dep.SourceID] = append(dependents[dep.SourceID], dependentEdge{dep.TargetID, dep.Relationship, dep.Confidence})
+		case core.DirectionBidirectional:
+			dependents[dep.SourceID] = append(dependents[dep.SourceID], dependentEdge{dep.TargetID, dep.Relationship, dep.Confidence})
+			dependents[dep.TargetID] = append(dependents[dep.TargetID], dependentEdge{dep.SourceID, dep.Relationship, dep.Confidence})
+		default:
+			dependents[dep.TargetID] = append(dependents[dep.TargetID], dependentEdge{dep.SourceID, dep.Relationship, dep.Confidence})
+		}
+	}
+	return dependents
+}
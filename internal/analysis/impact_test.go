@@ -0,0 +1,77 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependencyGraph_Impact(t *testing.T) {
+	graph := &DependencyGraph{
+		Resources: []core.Resource{
+			{ID: "vpc-1", Provider: "aws", Service: "ec2", Type: "vpc"},
+			{ID: "sg-1", Provider: "aws", Service: "ec2", Type: "security-group"},
+			{ID: "i-1", Provider: "aws", Service: "ec2", Type: "instance", MonthlyCost: 30},
+			{ID: "db-1", Provider: "aws", Service: "rds", Type: "db-instance", MonthlyCost: 120},
+			{ID: "lb-1", Provider: "aws", Service: "elb", Type: "load-balancer", MonthlyCost: 20, PublicAccess: true},
+			{ID: "dns-1", Provider: "gcp", Service: "dns", Type: "record"},
+			{ID: "bucket-1", Provider: "aws", Service: "s3", Type: "bucket"},
+		},
+		Dependencies: []Dependency{
+			{SourceID: "sg-1", TargetID: "vpc-1", Relationship: "in_vpc", Direction: core.DirectionOutbound, Confidence: 1},
+			{SourceID: "i-1", TargetID: "vpc-1", Relationship: "runs_in_vpc", Direction: core.DirectionOutbound, Confidence: 0.9},
+			{SourceID: "i-1", TargetID: "sg-1", Relationship: "uses_security_group", Direction: core.DirectionOutbound, Confidence: 0.9},
+			// Stored from the database side: the instance depends on the database
+			{SourceID: "db-1", TargetID: "i-1", Relationship: "serves", Direction: core.DirectionInbound, Confidence: 0.8},
+			{SourceID: "lb-1", TargetID: "i-1", Relationship: "routes_to", Direction: core.DirectionOutbound, Confidence: 0.5},
+			{SourceID: "lb-1", TargetID: "dns-1", Relationship: "cross_cloud_related", Direction: core.DirectionBidirectional, Confidence: 0.6},
+			{SourceID: "bucket-1", TargetID: "db-1", Relationship: "backs_up", Direction: core.DirectionOutbound, Confidence: 1},
+		},
+	}
+
+	report, err := graph.Impact("vpc-1", 0)
+	require.NoError(t, err)
+
+	require.Len(t, report.ByDepth, 3)
+	assert.Equal(t, []string{"i-1", "sg-1"}, impactedIDs(report.ByDepth[0]))
+	assert.Equal(t, []string{"lb-1"}, impactedIDs(report.ByDepth[1]))
+	assert.Equal(t, []string{"dns-1"}, impactedIDs(report.ByDepth[2]))
+	assert.Equal(t, "lb-1", report.ByDepth[2].Resources[0].DependsOn)
+	assert.InDelta(t, 0.9*0.5*0.6, report.ByDepth[2].Resources[0].Confidence, 1e-9)
+
+	assert.Equal(t, 4, report.TotalImpacted)
+	assert.Equal(t, 50.0, report.TotalMonthlyCost)
+	require.Len(t, report.PublicResources, 1)
+	assert.Equal(t, "lb-1", report.PublicResources[0].Resource.ID)
+	assert.Equal(t, ImpactGroup{Resources: 3, MonthlyCost: 50, PublicResources: 1}, report.ByProvider["aws"])
+	assert.Equal(t, ImpactGroup{Resources: 1}, report.ByProvider["gcp"])
+	assert.Equal(t, ImpactGroup{Resources: 2, MonthlyCost: 30}, report.ByService["aws/ec2"])
+
+	t.Run("inbound dependencies", func(t *testing.T) {
+		report, err := graph.Impact("db-1", 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bucket-1", "i-1"}, impactedIDs(report.ByDepth[0]))
+		assert.Equal(t, "serves", report.ByDepth[0].Resources[1].Relationship)
+	})
+
+	t.Run("minimum confidence", func(t *testing.T) {
+		report, err := graph.Impact("vpc-1", 0.7)
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.TotalImpacted)
+	})
+
+	t.Run("unknown resource", func(t *testing.T) {
+		_, err := graph.Impact("missing", 0)
+		assert.Error(t, err)
+	})
+}
+
+func impactedIDs(level ImpactLevel) []string {
+	ids := []string{}
+	for _, impacted := range level.Resources {
+		ids = append(ids, impacted.Resource.ID)
+	}
+	return ids
+}
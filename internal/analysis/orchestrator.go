@@ -211,6 +211,9 @@ func (ao *AnalysisOrchestrator) GetAnalysisInsights(ctx context.Context) ([]stri
 			insights = append(insights, fmt.Sprintf("%d resources appear to be isolated with no dependencies",
 				report.DependencyGraph.Stats.Islands))
 		}
+		if spofs := len(report.DependencyGraph.SinglePointsOfFailure); spofs > 0 {
+			insights = append(insights, fmt.Sprintf("%d resources are single points of failure for their dependents", spofs))
+		}
 	}
 
 	// Security insights
@@ -229,6 +229,9 @@ func (poao *PerformanceOptimizedAnalysisOrchestrator) generateKeyFindingsOptimiz
 		if report.Dependencies.Stats.Islands > 0 {
 			findings = append(findings, "Isolated resources found that may be unused")
 		}
+		if len(report.Dependencies.SinglePointsOfFailure) > 0 {
+			findings = append(findings, "Single points of failure detected in infrastructure")
+		}
 	}
 
 	// Security findings
@@ -278,6 +281,9 @@ func (poao *PerformanceOptimizedAnalysisOrchestrator) generateRecommendationsOpt
 	if report.Dependencies != nil && report.Dependencies.Stats.Cycles > 0 {
 		recommendations = append(recommendations, "Resolve circular dependencies to improve infrastructure stability")
 	}
+	if report.Dependencies != nil && len(report.Dependencies.SinglePointsOfFailure) > 0 {
+		recommendations = append(recommendations, "Add redundancy to single points of failure")
+	}
 
 	return recommendations
 }
@@ -3,7 +3,6 @@ package analysis
 import (
 	"context"
 	"runtime"
-	"strings"
 	"sync"
 	"time"
 
@@ -66,11 +65,7 @@ func (poda *PerformanceOptimizedDependencyAnalyzer) AnalyzeDependenciesOptimized
 	}
 
 	if len(resources) == 0 {
-		return &DependencyGraph{
-			Resources:    []core.Resource{},
-			Dependencies: []Dependency{},
-			Stats:        GraphStats{},
-		}, nil
+		return newDependencyGraph([]core.Resource{}, []Dependency{}), nil
 	}
 
 	// Group resources by provider for parallel processing
@@ -107,21 +102,11 @@ func (poda *PerformanceOptimizedDependencyAnalyzer) AnalyzeDependenciesOptimized
 	// Wait for all workers to complete
 	wg.Wait()
 
-	// Analyze cross-provider dependencies in parallel
-	crossProviderDeps, err := poda.analyzeCrossProviderDependenciesParallel(ctx, providerResources)
-	if err != nil {
-		logrus.Warnf("Failed to analyze cross-provider dependencies: %v", err)
-	}
-	dependencies = append(dependencies, crossProviderDeps...)
-
-	// Calculate graph statistics
-	stats := poda.calculateGraphStatsOptimized(resources, dependencies)
+	// Analyze cross-provider dependencies from DNS records, VPN peers and configuration references
+	dependencies = append(dependencies, findCrossCloudDependencies(resources)...)
 
-	graph := &DependencyGraph{
-		Resources:    resources,
-		Dependencies: dependencies,
-		Stats:        stats,
-	}
+	// Calculate graph statistics and single points of failure
+	graph := newDependencyGraph(resources, dependencies)
 
 	duration := time.Since(start)
 	logrus.Infof("Optimized dependency analysis completed: %d resources, %d dependencies in %v",
@@ -196,218 +181,6 @@ func (poda *PerformanceOptimizedDependencyAnalyzer) groupResourcesByProvider(res
 	return providerResources
 }
 
-// analyzeCrossProviderDependenciesParallel analyzes cross-provider dependencies in parallel
-func (poda *PerformanceOptimizedDependencyAnalyzer) analyzeCrossProviderDependenciesParallel(ctx context.Context, providerResources map[string][]core.Resource) ([]Dependency, error) {
-	if len(providerResources) < 2 {
-		return []Dependency{}, nil
-	}
-
-	// Create all possible provider pairs
-	providers := make([]string, 0, len(providerResources))
-	for provider := range providerResources {
-		providers = append(providers, provider)
-	}
-
-	var dependencies []Dependency
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-
-	// Process provider pairs in parallel
-	for i := 0; i < len(providers); i++ {
-		for j := i + 1; j < len(providers); j++ {
-			wg.Add(1)
-			go func(provider1, provider2 string) {
-				defer wg.Done()
-
-				deps := poda.analyzeProviderPairDependencies(ctx, provider1, provider2, providerResources)
-
-				mu.Lock()
-				dependencies = append(dependencies, deps...)
-				mu.Unlock()
-			}(providers[i], providers[j])
-		}
-	}
-
-	wg.Wait()
-	return dependencies, nil
-}
-
-// analyzeProviderPairDependencies analyzes dependencies between two providers
-func (poda *PerformanceOptimizedDependencyAnalyzer) analyzeProviderPairDependencies(ctx context.Context, provider1, provider2 string, providerResources map[string][]core.Resource) []Dependency {
-	var dependencies []Dependency
-
-	resources1 := providerResources[provider1]
-	resources2 := providerResources[provider2]
-
-	// Create lookup maps for efficient searching
-	map1 := make(map[string]core.Resource)
-	map2 := make(map[string]core.Resource)
-
-	for _, resource := range resources1 {
-		map1[resource.ID] = resource
-	}
-	for _, resource := range resources2 {
-		map2[resource.ID] = resource
-	}
-
-	// Analyze cross-provider dependencies
-	for _, resource1 := range resources1 {
-		for _, resource2 := range resources2 {
-			if dep := poda.findCrossProviderDependency(resource1, resource2); dep != nil {
-				dependencies = append(dependencies, *dep)
-			}
-		}
-	}
-
-	return dependencies
-}
-
-// findCrossProviderDependency finds dependencies between resources from different providers
-func (poda *PerformanceOptimizedDependencyAnalyzer) findCrossProviderDependency(resource1, resource2 core.Resource) *Dependency {
-	// Check for common patterns in cross-provider dependencies
-	// This is a simplified implementation - in practice, you'd have more sophisticated logic
-
-	// Only check for explicit references in configuration
-	config1 := string(resource1.Configuration)
-	if len(config1) > 0 && (strings.Contains(config1, resource2.ID) || strings.Contains(config1, resource2.ARN)) {
-		return &Dependency{
-			SourceID:     resource1.ID,
-			TargetID:     resource2.ID,
-			SourceARN:    resource1.ARN,
-			TargetARN:    resource2.ARN,
-			Relationship: "cross_provider_reference",
-			Direction:    "outbound",
-			Confidence:   0.7,
-			Metadata: map[string]interface{}{
-				"source_provider": resource1.Provider,
-				"target_provider": resource2.Provider,
-			},
-		}
-	}
-
-	return nil
-}
-
-// calculateGraphStatsOptimized calculates graph statistics efficiently
-func (poda *PerformanceOptimizedDependencyAnalyzer) calculateGraphStatsOptimized(resources []core.Resource, dependencies []Dependency) GraphStats {
-	stats := GraphStats{
-		TotalResources:    len(resources),
-		TotalDependencies: len(dependencies),
-	}
-
-	if len(dependencies) == 0 {
-		stats.Islands = len(resources)
-		return stats
-	}
-
-	// Create dependency graph for analysis
-	graph := make(map[string][]string)
-	incoming := make(map[string]int)
-
-	for _, dep := range dependencies {
-		graph[dep.SourceID] = append(graph[dep.SourceID], dep.TargetID)
-		incoming[dep.TargetID]++
-	}
-
-	// Calculate islands (resources with no dependencies)
-	stats.Islands = 0
-	for _, resource := range resources {
-		if len(graph[resource.ID]) == 0 && incoming[resource.ID] == 0 {
-			stats.Islands++
-		}
-	}
-
-	// Calculate max depth using BFS
-	stats.MaxDepth = poda.calculateMaxDepth(graph, resources)
-
-	// Calculate cycles using DFS
-	stats.Cycles = poda.calculateCycles(graph, resources)
-
-	return stats
-}
-
-// calculateMaxDepth calculates the maximum depth of the dependency graph
-func (poda *PerformanceOptimizedDependencyAnalyzer) calculateMaxDepth(graph map[string][]string, resources []core.Resource) int {
-	maxDepth := 0
-	visited := make(map[string]bool)
-
-	for _, resource := range resources {
-		if !visited[resource.ID] {
-			depth := poda.bfsDepth(graph, resource.ID, visited)
-			if depth > maxDepth {
-				maxDepth = depth
-			}
-		}
-	}
-
-	return maxDepth
-}
-
-// bfsDepth calculates depth using BFS
-func (poda *PerformanceOptimizedDependencyAnalyzer) bfsDepth(graph map[string][]string, start string, visited map[string]bool) int {
-	queue := []string{start}
-	depth := 0
-
-	for len(queue) > 0 {
-		levelSize := len(queue)
-		depth++
-
-		for i := 0; i < levelSize; i++ {
-			node := queue[0]
-			queue = queue[1:]
-
-			if visited[node] {
-				continue
-			}
-			visited[node] = true
-
-			for _, neighbor := range graph[node] {
-				if !visited[neighbor] {
-					queue = append(queue, neighbor)
-				}
-			}
-		}
-	}
-
-	return depth
-}
-
-// calculateCycles calculates the number of cycles in the dependency graph
-func (poda *PerformanceOptimizedDependencyAnalyzer) calculateCycles(graph map[string][]string, resources []core.Resource) int {
-	cycles := 0
-	visited := make(map[string]bool)
-	recStack := make(map[string]bool)
-
-	for _, resource := range resources {
-		if !visited[resource.ID] {
-			if poda.hasCycleDFS(graph, resource.ID, visited, recStack) {
-				cycles++
-			}
-		}
-	}
-
-	return cycles
-}
-
-// hasCycleDFS detects cycles using DFS
-func (poda *PerformanceOptimizedDependencyAnalyzer) hasCycleDFS(graph map[string][]string, node string, visited, recStack map[string]bool) bool {
-	visited[node] = true
-	recStack[node] = true
-
-	for _, neighbor := range graph[node] {
-		if !visited[neighbor] {
-			if poda.hasCycleDFS(graph, neighbor, visited, recStack) {
-				return true
-			}
-		} else if recStack[neighbor] {
-			return true
-		}
-	}
-
-	recStack[node] = false
-	return false
-}
-
 // ClearCache clears the analysis cache
 func (poda *PerformanceOptimizedDependencyAnalyzer) ClearCache() {
 	poda.cacheMutex.Lock()
@@ -0,0 +1,84 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// Subgraph returns the part of the graph within depth dependencies of a
+// resource, following dependencies and dependents alike. A depth of zero or
+// less has no limit, returning everything connected to the resource.
+func (g *DependencyGraph) Subgraph(resourceID string, depth int) (*DependencyGraph, error) {
+	found := false
+	for _, resource := range g.Resources {
+		if resource.ID == resourceID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("resource %s not found", resourceID)
+	}
+
+	neighbors := make(map[string][]string)
+	for _, dep := range g.Dependencies {
+		neighbors[dep.SourceID] = append(neighbors[dep.SourceID], dep.TargetID)
+		neighbors[dep.TargetID] = append(neighbors[dep.TargetID], dep.SourceID)
+	}
+
+	distance := map[string]int{resourceID: 0}
+	queue := []string{resourceID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if depth > 0 && distance[id] >= depth {
+			continue
+		}
+		for _, neighbor := range neighbors[id] {
+			if _, ok := distance[neighbor]; !ok {
+				distance[neighbor] = distance[id] + 1
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+
+	return g.filter(func(resource core.Resource) bool {
+		_, ok := distance[resource.ID]
+		return ok
+	}), nil
+}
+
+// WithTag returns the part of the graph made of the resources carrying a
+// tag, given as "key" for any value or "key=value", and the dependencies
+// between them
+func (g *DependencyGraph) WithTag(tag string) *DependencyGraph {
+	key, value, hasValue := strings.Cut(tag, "=")
+	return g.filter(func(resource core.Resource) bool {
+		actual, ok := resource.Tags[key]
+		return ok && (!hasValue || actual == value)
+	})
+}
+
+// filter keeps the resources matching keep and the dependencies between
+// them, recomputing statistics and single points of failure for the result
+func (g *DependencyGraph) filter(keep func(core.Resource) bool) *DependencyGraph {
+	kept := make(map[string]bool)
+	resources := []core.Resource{}
+	for _, resource := range g.Resources {
+		if keep(resource) {
+			kept[resource.ID] = true
+			resources = append(resources, resource)
+		}
+	}
+
+	dependencies := []Dependency{}
+	for _, dep := range g.Dependencies {
+		if kept[dep.SourceID] && kept[dep.TargetID] {
+			dependencies = append(dependencies, dep)
+		}
+	}
+
+	return newDependencyGraph(resources, dependencies)
+}
@@ -0,0 +1,55 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependencyGraph_Subgraph(t *testing.T) {
+	// lb -> app -> db -> vpc, with an unrelated bucket
+	resources := resourcesWithIDs("lb", "app", "db", "vpc", "bucket")
+	graph := newDependencyGraph(resources, dependsOn("lb", "app", "app", "db", "db", "vpc"))
+
+	subgraph, err := graph.Subgraph("db", 1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"app", "db", "vpc"}, graphResourceIDs(subgraph))
+	assert.Len(t, subgraph.Dependencies, 2)
+	assert.Equal(t, 3, subgraph.Stats.TotalResources)
+	assert.Equal(t, 2, subgraph.Stats.MaxDepth)
+
+	subgraph, err = graph.Subgraph("db", 0)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"lb", "app", "db", "vpc"}, graphResourceIDs(subgraph))
+
+	_, err = graph.Subgraph("missing", 1)
+	assert.Error(t, err)
+}
+
+func TestDependencyGraph_WithTag(t *testing.T) {
+	resources := []core.Resource{
+		{ID: "app", Tags: map[string]string{"Environment": "prod"}},
+		{ID: "db", Tags: map[string]string{"Environment": "prod"}},
+		{ID: "test-db", Tags: map[string]string{"Environment": "test"}},
+		{ID: "vpc"},
+	}
+	graph := newDependencyGraph(resources, dependsOn("app", "db", "app", "test-db", "db", "vpc"))
+
+	prod := graph.WithTag("Environment=prod")
+	assert.ElementsMatch(t, []string{"app", "db"}, graphResourceIDs(prod))
+	require.Len(t, prod.Dependencies, 1)
+	assert.Equal(t, "db", prod.Dependencies[0].TargetID)
+
+	assert.ElementsMatch(t, []string{"app", "db", "test-db"}, graphResourceIDs(graph.WithTag("Environment")))
+	assert.Empty(t, graph.WithTag("Team").Resources)
+}
+
+func graphResourceIDs(graph *DependencyGraph) []string {
+	ids := []string{}
+	for _, resource := range graph.Resources {
+		ids = append(ids, resource.ID)
+	}
+	return ids
+}
@@ -68,6 +68,27 @@ func (sa *SecurityAnalyzer) AnalyzeSecurity(ctx context.Context) (*SecurityRepor
 		return nil, fmt.Errorf("failed to get resources: %w", err)
 	}
 
+	findings := sa.findSecurityIssues(ctx, resources)
+
+	// Calculate summary and scores
+	summary := sa.calculateSecuritySummary(findings)
+	complianceScore := sa.calculateComplianceScore(findings)
+	riskScore := sa.calculateRiskScore(findings)
+
+	report := &SecurityReport{
+		Findings:        findings,
+		Summary:         summary,
+		ComplianceScore: complianceScore,
+		RiskScore:       riskScore,
+	}
+
+	logrus.Infof("Security analysis completed: %d findings", len(findings))
+
+	return report, nil
+}
+
+// findSecurityIssues runs provider-specific and cross-provider checks over resources
+func (sa *SecurityAnalyzer) findSecurityIssues(ctx context.Context, resources []core.Resource) []SecurityFinding {
 	var findings []SecurityFinding
 
 	// Group resources by provider for provider-specific analysis
@@ -94,21 +115,7 @@ func (sa *SecurityAnalyzer) AnalyzeSecurity(ctx context.Context) (*SecurityRepor
 	}
 	findings = append(findings, crossProviderFindings...)
 
-	// Calculate summary and scores
-	summary := sa.calculateSecuritySummary(findings)
-	complianceScore := sa.calculateComplianceScore(findings)
-	riskScore := sa.calculateRiskScore(findings)
-
-	report := &SecurityReport{
-		Findings:        findings,
-		Summary:         summary,
-		ComplianceScore: complianceScore,
-		RiskScore:       riskScore,
-	}
-
-	logrus.Infof("Security analysis completed: %d findings", len(findings))
-
-	return report, nil
+	return findings
 }
 
 // analyzeProviderSecurity analyzes security for a specific provider
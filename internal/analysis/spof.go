@@ -0,0 +1,106 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// spofMinDependents is the number of direct dependents from which a
+// resource is considered as a single point of failure
+const spofMinDependents = 3
+
+// SinglePointOfFailure is a resource that many others depend on with no
+// alternative, such as the only NAT gateway or subnet of a workload
+type SinglePointOfFailure struct {
+	ID                   string   `json:"id"`
+	ResourceID           string   `json:"resource_id"`
+	ResourceARN          string   `json:"resource_arn"`
+	Provider             string   `json:"provider"`
+	Service              string   `json:"service"`
+	Type                 string   `json:"type"`
+	Name                 string   `json:"name"`
+	Severity             string   `json:"severity"` // "high", "medium"
+	Title                string   `json:"title"`
+	Description          string   `json:"description"`
+	Recommendation       string   `json:"recommendation"`
+	Dependents           int      `json:"dependents"`
+	TransitiveDependents int      `json:"transitive_dependents"`
+	DependentServices    []string `json:"dependent_services"`
+	ArticulationPoint    bool     `json:"articulation_point"` // Its loss disconnects its dependents from the rest of the graph
+}
+
+// SinglePointsOfFailure finds the resources that at least three others
+// depend on, and whose loss either cuts the graph apart or affects several
+// services. Articulation points are reported as high severity. Findings are
+// ranked by their number of dependents.
+func (g *Graph) SinglePointsOfFailure(resources []core.Resource) []SinglePointOfFailure {
+	byID := make(map[string]core.Resource, len(resources))
+	for _, resource := range resources {
+		byID[resource.ID] = resource
+	}
+
+	articulation := make(map[string]bool)
+	for _, id := range g.ArticulationPoints() {
+		articulation[id] = true
+	}
+
+	findings := []SinglePointOfFailure{}
+	for _, fanIn := range g.FanIn() {
+		if fanIn.Dependents < spofMinDependents {
+			break
+		}
+
+		resource, ok := byID[fanIn.ResourceID]
+		if !ok {
+			continue
+		}
+
+		services := make(map[string]bool)
+		for _, dependent := range g.Dependents(resource.ID) {
+			if d, ok := byID[dependent]; ok {
+				services[d.Provider+"/"+d.Service] = true
+			}
+		}
+		dependentServices := make([]string, 0, len(services))
+		for service := range services {
+			dependentServices = append(dependentServices, service)
+		}
+		sort.Strings(dependentServices)
+
+		if !articulation[resource.ID] && len(dependentServices) < 2 {
+			continue
+		}
+
+		severity := "medium"
+		if articulation[resource.ID] {
+			severity = "high"
+		}
+
+		name := resource.Name
+		if name == "" {
+			name = resource.ID
+		}
+
+		findings = append(findings, SinglePointOfFailure{
+			ID:                   fmt.Sprintf("spof-%s", resource.ID),
+			ResourceID:           resource.ID,
+			ResourceARN:          resource.ARN,
+			Provider:             resource.Provider,
+			Service:              resource.Service,
+			Type:                 resource.Type,
+			Name:                 resource.Name,
+			Severity:             severity,
+			Title:                fmt.Sprintf("Single point of failure: %s %s", resource.Type, name),
+			Description:          fmt.Sprintf("%d resources across %d services depend directly on %s", fanIn.Dependents, len(dependentServices), name),
+			Recommendation:       "Add redundancy, such as a second instance in another availability zone, so dependents can fail over",
+			Dependents:           fanIn.Dependents,
+			TransitiveDependents: g.TransitiveDependents(resource.ID),
+			DependentServices:    dependentServices,
+			ArticulationPoint:    articulation[resource.ID],
+		})
+	}
+
+	return findings
+}
@@ -45,7 +45,47 @@ func (m *MockStorage) GetResourceSummary() (*core.ResourceSummary, error) {
 	return args.Get(0).(*core.ResourceSummary), args.Error(1)
 }
 
+func (m *MockStorage) GetResourceHistory(resourceID string) (*core.ResourceHistory, error) {
+	args := m.Called(resourceID)
+	return args.Get(0).(*core.ResourceHistory), args.Error(1)
+}
+
 func (m *MockStorage) Close() error {
 	args := m.Called()
 	return args.Error(0)
 }
+
+// MockRelationshipStorage is a mock storage that also persists dependency analysis results
+type MockRelationshipStorage struct {
+	MockStorage
+}
+
+func (m *MockRelationshipStorage) StoreRelationships(relationships []core.Relationship) error {
+	args := m.Called(relationships)
+	return args.Error(0)
+}
+
+func (m *MockRelationshipStorage) RelationshipsCurrent() (bool, error) {
+	args := m.Called()
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRelationshipStorage) GetRelationships(types ...string) ([]core.Relationship, error) {
+	args := m.Called(types)
+	return args.Get(0).([]core.Relationship), args.Error(1)
+}
+
+func (m *MockRelationshipStorage) GetNeighbors(resourceID string, types ...string) ([]core.Relationship, error) {
+	args := m.Called(resourceID, types)
+	return args.Get(0).([]core.Relationship), args.Error(1)
+}
+
+func (m *MockRelationshipStorage) GetUpstream(resourceID string, types ...string) ([]core.RelatedResource, error) {
+	args := m.Called(resourceID, types)
+	return args.Get(0).([]core.RelatedResource), args.Error(1)
+}
+
+func (m *MockRelationshipStorage) GetDownstream(resourceID string, types ...string) ([]core.RelatedResource, error) {
+	args := m.Called(resourceID, types)
+	return args.Get(0).([]core.RelatedResource), args.Error(1)
+}
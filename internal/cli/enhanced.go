@@ -414,6 +414,11 @@ func (e *EnhancedCLI) displayDependencyResults(graph *analysis.DependencyGraph,
 	fmt.Printf("🔄 Cycles: %d\n", graph.Stats.Cycles)
 	fmt.Printf("  Islands: %d\n", graph.Stats.Islands)
 	fmt.Printf("📏 Max Depth: %d\n", graph.Stats.MaxDepth)
+	fmt.Printf("  Articulation Points: %d\n", graph.Stats.ArticulationPoints)
+	fmt.Printf("  Single Points of Failure: %d\n", len(graph.SinglePointsOfFailure))
+	for _, spof := range graph.SinglePointsOfFailure {
+		fmt.Printf("   • %s (%d dependents)\n", spof.Title, spof.Dependents)
+	}
 	fmt.Println()
 }
 
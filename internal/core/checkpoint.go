@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Discovery run statuses recorded in storage
+const (
+	RunStatusRunning     = "running"
+	RunStatusInterrupted = "interrupted"
+	RunStatusPartial     = "partial"
+	RunStatusCompleted   = "completed"
+)
+
+// Checkpoint records a discovery unit that finished successfully.
+// An empty Region marks a whole account as complete.
+type Checkpoint struct {
+	RunID         int64
+	Provider      string
+	AccountID     string
+	Region        string
+	ResourceCount int
+	CompletedAt   time.Time
+}
+
+// CheckpointStorage is implemented by storage backends that can persist
+// a discovery run incrementally so it can be resumed after an interruption
+type CheckpointStorage interface {
+	CreateRun(result *DiscoveryResult) (int64, error)
+	GetRun(runID int64) (*DiscoveryStatus, error)
+	GetCheckpoints(runID int64) ([]Checkpoint, error)
+	GetCheckpointResources(runID int64) ([]Resource, error)
+	SaveCheckpoint(checkpoint Checkpoint, resources []Resource) error
+}
+
+// Checkpointer tracks completed discovery units within a run
+type Checkpointer interface {
+	IsComplete(account Account, region string) bool
+	Complete(account Account, region string, resources []Resource) error
+}
+
+// AccountCompleted reports whether an account was already completed in the run being resumed
+func (o DiscoveryOptions) AccountCompleted(account Account) bool {
+	return o.RegionCompleted(account, "")
+}
+
+// CompleteAccount persists the resources of a finished account and records its checkpoint
+func (o DiscoveryOptions) CompleteAccount(ctx context.Context, account Account, resources []Resource) error {
+	return o.CompleteRegion(ctx, account, "", resources)
+}
+
+// RegionCompleted reports whether a region was already completed in the run being resumed
+func (o DiscoveryOptions) RegionCompleted(account Account, region string) bool {
+	return o.Checkpointer != nil && o.Checkpointer.IsComplete(account, region)
+}
+
+// CompleteRegion persists the resources of a finished region and records its checkpoint.
+// Nothing is recorded once ctx is done, since the region may have been cut short.
+func (o DiscoveryOptions) CompleteRegion(ctx context.Context, account Account, region string, resources []Resource) error {
+	if o.Checkpointer == nil || ctx.Err() != nil {
+		return nil
+	}
+	return o.Checkpointer.Complete(account, region, resources)
+}
+
+// runCheckpointer records checkpoints for a single discovery run
+type runCheckpointer struct {
+	storage   CheckpointStorage
+	runID     int64
+	mu        sync.Mutex
+	completed map[string]bool
+}
+
+// newRunCheckpointer creates a checkpointer seeded with the run's existing checkpoints
+func newRunCheckpointer(storage CheckpointStorage, runID int64, existing []Checkpoint) *runCheckpointer {
+	c := &runCheckpointer{
+		storage:   storage,
+		runID:     runID,
+		completed: make(map[string]bool),
+	}
+	for _, checkpoint := range existing {
+		c.completed[checkpointKey(checkpoint.Provider, checkpoint.AccountID, checkpoint.Region)] = true
+	}
+	return c
+}
+
+// IsComplete reports whether the unit has a checkpoint
+func (c *runCheckpointer) IsComplete(account Account, region string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.completed[checkpointKey(account.Provider, account.ID, region)]
+}
+
+// Complete stores the unit's resources together with its checkpoint
+func (c *runCheckpointer) Complete(account Account, region string, resources []Resource) error {
+	checkpoint := Checkpoint{
+		RunID:         c.runID,
+		Provider:      account.Provider,
+		AccountID:     account.ID,
+		Region:        region,
+		ResourceCount: len(resources),
+		CompletedAt:   time.Now(),
+	}
+	if err := c.storage.SaveCheckpoint(checkpoint, resources); err != nil {
+		return NewStorageError("failed to save checkpoint", err).
+			WithContext("account", account.ID).
+			WithContext("region", region)
+	}
+
+	c.mu.Lock()
+	c.completed[checkpointKey(account.Provider, account.ID, region)] = true
+	c.mu.Unlock()
+	return nil
+}
+
+// checkpointKey builds the map key for a discovery unit
+func checkpointKey(provider, accountID, region string) string {
+	return provider + "/" + accountID + "/" + region
+}
+
+// startRun registers the run with checkpoint-capable storage, or loads the
+// state of the run being resumed. It returns nil when the storage backend
+// cannot checkpoint and no resume was requested.
+func (d *DiscoveryOrchestrator) startRun(result *DiscoveryResult) (*runCheckpointer, error) {
+	store, ok := d.storage.(CheckpointStorage)
+	if !ok {
+		if d.options.ResumeRunID != 0 {
+			return nil, NewStorageError("storage backend does not support resuming runs", nil)
+		}
+		return nil, nil
+	}
+
+	if d.options.ResumeRunID == 0 {
+		runID, err := store.CreateRun(result)
+		if err != nil {
+			return nil, NewStorageError("failed to create discovery run", err)
+		}
+		result.RunID = runID
+		return newRunCheckpointer(store, runID, nil), nil
+	}
+
+	runID := d.options.ResumeRunID
+	run, err := store.GetRun(runID)
+	if err != nil {
+		return nil, NewStorageError(fmt.Sprintf("failed to load discovery run %d", runID), err)
+	}
+	if run.Status == RunStatusCompleted {
+		return nil, NewValidationError(fmt.Sprintf("discovery run %d already completed", runID), nil)
+	}
+
+	checkpoints, err := store.GetCheckpoints(runID)
+	if err != nil {
+		return nil, NewStorageError("failed to load checkpoints", err).WithContext("run_id", runID)
+	}
+
+	// Resources from completed units take part in enrichment and the final store
+	resources, err := store.GetCheckpointResources(runID)
+	if err != nil {
+		return nil, NewStorageError("failed to load checkpointed resources", err).WithContext("run_id", runID)
+	}
+
+	result.RunID = runID
+	result.StartTime = run.LastRun
+	result.Resources = append(result.Resources, resources...)
+	return newRunCheckpointer(store, runID, checkpoints), nil
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"sync"
+)
+
+// Scope identifies a provider/account/region/service combination
+type Scope struct {
+	Provider  string `json:"provider"`
+	AccountID string `json:"account_id"`
+	Region    string `json:"region"`
+	Service   string `json:"service"`
+}
+
+// scopeCoverage collects the scopes a discovery run fully covered
+type scopeCoverage struct {
+	mu     sync.Mutex
+	scopes []Scope
+}
+
+// add records a fully covered scope
+func (c *scopeCoverage) add(scope Scope) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scopes = append(c.scopes, scope)
+}
+
+// list returns the recorded scopes
+func (c *scopeCoverage) list() []Scope {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Scope(nil), c.scopes...)
+}
+
+type scopeErrorsKey struct{}
+
+// scopeErrors remembers the first error reported for a service scope
+type scopeErrors struct {
+	mu    sync.Mutex
+	first error
+}
+
+// withScopeErrors attaches a fresh error recorder to ctx
+func withScopeErrors(ctx context.Context) (context.Context, *scopeErrors) {
+	recorder := &scopeErrors{}
+	return context.WithValue(ctx, scopeErrorsKey{}, recorder), recorder
+}
+
+// err returns the first recorded error
+func (r *scopeErrors) err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.first
+}
+
+// RecordScopeError marks the service being discovered with ctx as failed.
+// Providers call it for errors they log and skip, so that a service that was
+// only partially listed is never treated as fully covered.
+func RecordScopeError(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	recorder, ok := ctx.Value(scopeErrorsKey{}).(*scopeErrors)
+	if !ok {
+		return
+	}
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if recorder.first == nil {
+		recorder.first = err
+	}
+}
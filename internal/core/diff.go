@@ -0,0 +1,276 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Kinds of resource changes between two inventories
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// FieldChange is a changed field of a modified resource. Tags are reported
+// as tags.<key> and configuration values by their JSON path, such as
+// configuration.SecurityGroups[0].GroupId.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// ResourceDiff is a resource added, removed or modified between two inventories
+type ResourceDiff struct {
+	ID        string        `json:"id"`
+	Provider  string        `json:"provider"`
+	AccountID string        `json:"account_id"`
+	Region    string        `json:"region"`
+	Service   string        `json:"service"`
+	Type      string        `json:"type"`
+	Name      string        `json:"name"`
+	Change    string        `json:"change"`
+	Fields    []FieldChange `json:"fields,omitempty"`
+}
+
+// InventoryDiff lists the resource changes between two discovery runs
+type InventoryDiff struct {
+	FromRun  int64          `json:"from_run"`
+	ToRun    int64          `json:"to_run"`
+	Added    []ResourceDiff `json:"added"`
+	Removed  []ResourceDiff `json:"removed"`
+	Modified []ResourceDiff `json:"modified"`
+}
+
+// DiffFilter restricts a diff to some providers, accounts and services.
+// Empty lists match everything.
+type DiffFilter struct {
+	Providers []string
+	Accounts  []string
+	Services  []string
+}
+
+// Matches reports whether a resource passes the filter
+func (f DiffFilter) Matches(resource Resource) bool {
+	return matchesAny(f.Providers, resource.Provider) &&
+		matchesAny(f.Accounts, resource.AccountID) &&
+		matchesAny(f.Services, resource.Service)
+}
+
+// matchesAny reports whether a value is in a list, or the list is empty
+func matchesAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty reports whether nothing changed
+func (d *InventoryDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// DiffInventories compares two inventories resource by resource. Discovery
+// and update times are ignored since they change without the resource
+// changing.
+func DiffInventories(from, to []Resource, filter DiffFilter) *InventoryDiff {
+	diff := &InventoryDiff{
+		Added:    []ResourceDiff{},
+		Removed:  []ResourceDiff{},
+		Modified: []ResourceDiff{},
+	}
+
+	before := make(map[string]Resource, len(from))
+	for _, resource := range from {
+		if filter.Matches(resource) {
+			before[resource.ID] = resource
+		}
+	}
+
+	after := make(map[string]bool, len(to))
+	for _, resource := range to {
+		if !filter.Matches(resource) {
+			continue
+		}
+		after[resource.ID] = true
+
+		old, ok := before[resource.ID]
+		if !ok {
+			diff.Added = append(diff.Added, newResourceDiff(resource, ChangeAdded, nil))
+			continue
+		}
+		if fields := DiffResources(old, resource); len(fields) > 0 {
+			diff.Modified = append(diff.Modified, newResourceDiff(resource, ChangeModified, fields))
+		}
+	}
+
+	for _, resource := range before {
+		if !after[resource.ID] {
+			diff.Removed = append(diff.Removed, newResourceDiff(resource, ChangeRemoved, nil))
+		}
+	}
+
+	for _, list := range [][]ResourceDiff{diff.Added, diff.Removed, diff.Modified} {
+		sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	}
+
+	return diff
+}
+
+// newResourceDiff describes a changed resource
+func newResourceDiff(resource Resource, change string, fields []FieldChange) ResourceDiff {
+	return ResourceDiff{
+		ID:        resource.ID,
+		Provider:  resource.Provider,
+		AccountID: resource.AccountID,
+		Region:    resource.Region,
+		Service:   resource.Service,
+		Type:      resource.Type,
+		Name:      resource.Name,
+		Change:    change,
+		Fields:    fields,
+	}
+}
+
+// DiffResources returns the fields that differ between two versions of a
+// resource, in the same form as the modified resources of a diff
+func DiffResources(previous, current Resource) []FieldChange {
+	var fields []FieldChange
+	add := func(field string, oldValue, newValue interface{}) {
+		if !reflect.DeepEqual(oldValue, newValue) {
+			fields = append(fields, FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	add("name", previous.Name, current.Name)
+	add("region", previous.Region, current.Region)
+	add("public_access", previous.PublicAccess, current.PublicAccess)
+	add("encrypted", previous.Encrypted, current.Encrypted)
+	add("monthly_cost", previous.MonthlyCost, current.MonthlyCost)
+
+	// Tags key by key
+	keys := make(map[string]bool)
+	for key := range previous.Tags {
+		keys[key] = true
+	}
+	for key := range current.Tags {
+		keys[key] = true
+	}
+	for _, key := range sortedKeys(keys) {
+		oldValue, oldOK := previous.Tags[key]
+		newValue, newOK := current.Tags[key]
+		if oldOK != newOK || oldValue != newValue {
+			fields = append(fields, FieldChange{Field: "tags." + key, Old: optional(oldValue, oldOK), New: optional(newValue, newOK)})
+		}
+	}
+
+	fields = append(fields, diffConfiguration(previous.Configuration, current.Configuration)...)
+
+	add("compliance", stringsOrEmpty(previous.Compliance), stringsOrEmpty(current.Compliance))
+	add("dependencies", stringsOrEmpty(previous.Dependencies), stringsOrEmpty(current.Dependencies))
+
+	return fields
+}
+
+// diffConfiguration compares configuration documents by JSON path.
+// Documents that are not JSON are compared as a whole.
+func diffConfiguration(previous, current json.RawMessage) []FieldChange {
+	if bytes.Equal(previous, current) {
+		return nil
+	}
+
+	var oldValue, newValue interface{}
+	oldErr := decodeConfiguration(previous, &oldValue)
+	newErr := decodeConfiguration(current, &newValue)
+	if oldErr != nil || newErr != nil {
+		return []FieldChange{{Field: "configuration", Old: string(previous), New: string(current)}}
+	}
+
+	var fields []FieldChange
+	diffValues("configuration", oldValue, newValue, &fields)
+	return fields
+}
+
+// decodeConfiguration decodes a configuration document; empty documents are null
+func decodeConfiguration(raw json.RawMessage, value *interface{}) error {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		*value = nil
+		return nil
+	}
+	return json.Unmarshal(raw, value)
+}
+
+// diffValues records the differences between two decoded JSON values
+func diffValues(path string, previous, current interface{}, fields *[]FieldChange) {
+	oldObject, oldIsObject := previous.(map[string]interface{})
+	newObject, newIsObject := current.(map[string]interface{})
+	if oldIsObject && newIsObject {
+		keys := make(map[string]bool)
+		for key := range oldObject {
+			keys[key] = true
+		}
+		for key := range newObject {
+			keys[key] = true
+		}
+		for _, key := range sortedKeys(keys) {
+			diffValues(path+"."+key, oldObject[key], newObject[key], fields)
+		}
+		return
+	}
+
+	oldArray, oldIsArray := previous.([]interface{})
+	newArray, newIsArray := current.([]interface{})
+	if oldIsArray && newIsArray {
+		for i := 0; i < len(oldArray) || i < len(newArray); i++ {
+			var oldItem, newItem interface{}
+			if i < len(oldArray) {
+				oldItem = oldArray[i]
+			}
+			if i < len(newArray) {
+				newItem = newArray[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), oldItem, newItem, fields)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(previous, current) {
+		*fields = append(*fields, FieldChange{Field: path, Old: previous, New: current})
+	}
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// optional returns a value, or nil when it is absent
+func optional(value string, ok bool) interface{} {
+	if !ok {
+		return nil
+	}
+	return value
+}
+
+// stringsOrEmpty treats nil and empty lists alike
+func stringsOrEmpty(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
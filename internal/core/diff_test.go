@@ -0,0 +1,94 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffInventories(t *testing.T) {
+	base := Resource{
+		ID:            "i-1",
+		Provider:      "aws",
+		AccountID:     "123456789012",
+		Region:        "us-east-1",
+		Service:       "ec2",
+		Type:          "instance",
+		Name:          "web",
+		Tags:          map[string]string{"Environment": "prod", "Owner": "alice"},
+		Configuration: json.RawMessage(`{"InstanceType":"t3.micro","SecurityGroups":[{"GroupId":"sg-1"}]}`),
+		MonthlyCost:   7.5,
+		DiscoveredAt:  time.Now().Add(-24 * time.Hour),
+	}
+
+	modified := base
+	modified.Tags = map[string]string{"Environment": "prod", "Team": "web"}
+	modified.Configuration = json.RawMessage(`{"InstanceType":"t3.large","SecurityGroups":[{"GroupId":"sg-1"},{"GroupId":"sg-2"}]}`)
+	modified.PublicAccess = true
+	modified.MonthlyCost = 60
+	modified.DiscoveredAt = time.Now()
+
+	removed := Resource{ID: "bucket-1", Provider: "aws", AccountID: "123456789012", Service: "s3", Type: "bucket"}
+	added := Resource{ID: "fn-1", Provider: "aws", AccountID: "123456789012", Service: "lambda", Type: "function"}
+
+	unchanged := Resource{ID: "vpc-1", Provider: "aws", AccountID: "123456789012", Service: "ec2", Type: "vpc"}
+	rediscovered := unchanged
+	rediscovered.DiscoveredAt = time.Now()
+
+	diff := DiffInventories(
+		[]Resource{base, removed, unchanged},
+		[]Resource{modified, added, rediscovered},
+		DiffFilter{},
+	)
+
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, "fn-1", diff.Added[0].ID)
+	assert.Equal(t, ChangeAdded, diff.Added[0].Change)
+
+	require.Len(t, diff.Removed, 1)
+	assert.Equal(t, "bucket-1", diff.Removed[0].ID)
+
+	require.Len(t, diff.Modified, 1)
+	assert.Equal(t, []FieldChange{
+		{Field: "public_access", Old: false, New: true},
+		{Field: "monthly_cost", Old: 7.5, New: 60.0},
+		{Field: "tags.Owner", Old: "alice", New: nil},
+		{Field: "tags.Team", Old: nil, New: "web"},
+		{Field: "configuration.InstanceType", Old: "t3.micro", New: "t3.large"},
+		{Field: "configuration.SecurityGroups[1]", Old: nil, New: map[string]interface{}{"GroupId": "sg-2"}},
+	}, diff.Modified[0].Fields)
+}
+
+func TestDiffInventories_Filter(t *testing.T) {
+	from := []Resource{
+		{ID: "i-1", Provider: "aws", AccountID: "111111111111", Service: "ec2"},
+		{ID: "vm-1", Provider: "azure", AccountID: "sub-1", Service: "compute"},
+	}
+	to := []Resource{
+		{ID: "i-2", Provider: "aws", AccountID: "111111111111", Service: "ec2"},
+		{ID: "i-3", Provider: "aws", AccountID: "222222222222", Service: "ec2"},
+	}
+
+	diff := DiffInventories(from, to, DiffFilter{Providers: []string{"aws"}, Accounts: []string{"111111111111"}})
+
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, "i-2", diff.Added[0].ID)
+	require.Len(t, diff.Removed, 1)
+	assert.Equal(t, "i-1", diff.Removed[0].ID)
+	assert.Empty(t, diff.Modified)
+}
+
+func TestDiffInventories_NonJSONConfiguration(t *testing.T) {
+	from := []Resource{{ID: "r-1", Configuration: json.RawMessage("plain")}}
+	to := []Resource{{ID: "r-1", Configuration: json.RawMessage("changed")}}
+
+	diff := DiffInventories(from, to, DiffFilter{})
+
+	require.Len(t, diff.Modified, 1)
+	assert.Equal(t, []FieldChange{{Field: "configuration", Old: "plain", New: "changed"}}, diff.Modified[0].Fields)
+	assert.False(t, diff.Empty())
+	assert.True(t, DiffInventories(to, to, DiffFilter{}).Empty())
+}
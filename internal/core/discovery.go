@@ -12,6 +12,7 @@ type DiscoveryOrchestrator struct {
 	providers map[string]CloudProvider
 	storage   Storage
 	options   DiscoveryOptions
+	enrichers []Enricher
 }
 
 // NewDiscoveryOrchestrator creates a new discovery orchestrator
@@ -38,8 +39,25 @@ func (d *DiscoveryOrchestrator) Discover(ctx context.Context) (*DiscoveryResult,
 		Errors:    make([]error, 0),
 	}
 
+	// Progress events are serialized and stamped with run-wide counters
+	// before they reach the caller's handler
+	tracker := newProgressTracker(d.options.ProgressHandler)
+	opts := d.options
+	opts.ProgressHandler = tracker.report
+
+	// Register the run so finished units are persisted as they complete
+	checkpointer, err := d.startRun(result)
+	if err != nil {
+		result.Errors = append(result.Errors, err)
+		return result, err
+	}
+	if checkpointer != nil {
+		opts.Checkpointer = checkpointer
+	}
+	opts.coverage = &scopeCoverage{}
+
 	// Phase 1: Enumerate accounts across all providers
-	accounts, err := d.discoverAccounts(ctx)
+	accounts, err := d.discoverAccounts(ctx, opts)
 	if err != nil {
 		result.Errors = append(result.Errors, fmt.Errorf("account discovery failed: %w", err))
 		return result, err
@@ -59,11 +77,39 @@ func (d *DiscoveryOrchestrator) Discover(ctx context.Context) (*DiscoveryResult,
 			sem <- struct{}{}        // Acquire semaphore
 			defer func() { <-sem }() // Release semaphore
 
-			resources, err := d.discoverAccountResources(ctx, acc)
+			// Accounts finished by the run being resumed are already stored
+			if opts.AccountCompleted(acc) {
+				opts.ReportProgress(DiscoveryProgress{
+					Event:           ProgressAccountFinished,
+					CurrentProvider: acc.Provider,
+					CurrentAccount:  acc.ID,
+				})
+				return
+			}
+
+			opts.ReportProgress(DiscoveryProgress{
+				Event:           ProgressAccountStarted,
+				CurrentProvider: acc.Provider,
+				CurrentAccount:  acc.ID,
+			})
+
+			resources, err := d.discoverAccountResources(ctx, acc, opts)
+
+			opts.ReportProgress(DiscoveryProgress{
+				Event:           ProgressAccountFinished,
+				CurrentProvider: acc.Provider,
+				CurrentAccount:  acc.ID,
+				ResourceCount:   len(resources),
+				Error:           err,
+			})
+
 			if err != nil {
 				errorsChan <- fmt.Errorf("account %s: %w", acc.ID, err)
 				return
 			}
+			if err := opts.CompleteAccount(ctx, acc, resources); err != nil {
+				errorsChan <- fmt.Errorf("account %s: %w", acc.ID, err)
+			}
 			resourcesChan <- resources
 		}(account)
 	}
@@ -81,13 +127,28 @@ func (d *DiscoveryOrchestrator) Discover(ctx context.Context) (*DiscoveryResult,
 	}
 
 	// Collect errors
+	failedAccounts := 0
 	for err := range errorsChan {
 		result.Errors = append(result.Errors, err)
+		failedAccounts++
 	}
 
+	// Only scopes that were fully listed may have resources tombstoned
+	result.CoveredScopes = opts.coverage.list()
+
 	// Phase 3: Enrich with relationships and metadata
 	if d.options.Mode >= StandardMode {
-		d.enrichResources(ctx, result.Resources)
+		result.Errors = append(result.Errors, d.enrichResources(ctx, result.Resources)...)
+	}
+
+	// Interrupted and partial runs can be resumed to retry the missing units
+	switch {
+	case ctx.Err() != nil:
+		result.Status = RunStatusInterrupted
+	case failedAccounts > 0:
+		result.Status = RunStatusPartial
+	default:
+		result.Status = RunStatusCompleted
 	}
 
 	// Phase 4: Store in database
@@ -101,7 +162,7 @@ func (d *DiscoveryOrchestrator) Discover(ctx context.Context) (*DiscoveryResult,
 }
 
 // discoverAccounts discovers all accounts across all providers
-func (d *DiscoveryOrchestrator) discoverAccounts(ctx context.Context) ([]Account, error) {
+func (d *DiscoveryOrchestrator) discoverAccounts(ctx context.Context, opts DiscoveryOptions) ([]Account, error) {
 	var allAccounts []Account
 	var wg sync.WaitGroup
 	var mu sync.Mutex
@@ -111,12 +172,31 @@ func (d *DiscoveryOrchestrator) discoverAccounts(ctx context.Context) ([]Account
 		go func(name string, p CloudProvider) {
 			defer wg.Done()
 
+			opts.ReportProgress(DiscoveryProgress{
+				Event:           ProgressProviderStarted,
+				CurrentProvider: name,
+			})
+
 			accounts, err := p.DiscoverAccounts(ctx)
 			if err != nil {
-				// Log error but continue with other providers
+				// Report error but continue with other providers
+				opts.ReportProgress(DiscoveryProgress{
+					Event:           ProgressProviderFinished,
+					CurrentProvider: name,
+					Error:           err,
+				})
 				return
 			}
 
+			// Narrow to the requested accounts before any resource scanning
+			accounts = opts.FilterAccounts(accounts)
+
+			opts.ReportProgress(DiscoveryProgress{
+				Event:           ProgressAccountsDiscovered,
+				CurrentProvider: name,
+				TotalAccounts:   len(accounts),
+			})
+
 			mu.Lock()
 			allAccounts = append(allAccounts, accounts...)
 			mu.Unlock()
@@ -131,43 +211,72 @@ func (d *DiscoveryOrchestrator) discoverAccounts(ctx context.Context) ([]Account
 func (d *DiscoveryOrchestrator) discoverAccountResources(
 	ctx context.Context,
 	account Account,
+	opts DiscoveryOptions,
 ) ([]Resource, error) {
 	provider := d.providers[account.Provider]
 
 	// Try native tools first
-	if d.options.UseNativeTools {
+	if opts.UseNativeTools {
 		if nativeProvider, ok := provider.(NativeToolProvider); ok {
 			if available, _ := nativeProvider.IsNativeToolAvailable(ctx, account); available {
-				return nativeProvider.DiscoverWithNativeTool(ctx, account)
+				resources, err := nativeProvider.DiscoverWithNativeTool(ctx, account)
+				if err != nil {
+					return nil, err
+				}
+				return opts.ResourceSelector(account.Provider, nil).Filter(resources), nil
 			}
 		}
 		// Fall through to direct API discovery if native tools are not available
 	}
 
 	// Fall back to direct API discovery
-	return provider.DiscoverResources(ctx, account, d.options)
+	return provider.DiscoverResources(ctx, account, opts)
 }
 
-// enrichResources enriches resources with relationships and metadata
-func (d *DiscoveryOrchestrator) enrichResources(ctx context.Context, resources []Resource) {
-	// TODO: Implement resource enrichment
-	// - Map dependencies between resources
-	// - Calculate costs
-	// - Assess security posture
-	// - Check compliance status
+// RegisterEnricher adds an enricher to the enrichment pipeline.
+// Enrichers run in registration order after resources are collected.
+func (d *DiscoveryOrchestrator) RegisterEnricher(enricher Enricher) {
+	d.enrichers = append(d.enrichers, enricher)
+}
+
+// enrichResources runs the registered enrichers over the discovered resources
+func (d *DiscoveryOrchestrator) enrichResources(ctx context.Context, resources []Resource) []error {
+	var errs []error
+
+	for _, enricher := range d.enrichers {
+		if ctx.Err() != nil {
+			errs = append(errs, fmt.Errorf("enrichment cancelled: %w", ctx.Err()))
+			break
+		}
+
+		// A failing enricher must not prevent the others from running
+		if err := enricher.Enrich(ctx, resources); err != nil {
+			errs = append(errs, fmt.Errorf("enricher %s: %w", enricher.Name(), err))
+		}
+	}
+
+	return errs
 }
 
 // DiscoveryProgress represents progress during discovery
 type DiscoveryProgress struct {
+	Event             ProgressEvent
 	ResourcesFound    int
 	AccountsProcessed int
+	TotalAccounts     int
 	CurrentAccount    string
 	CurrentProvider   string
+	CurrentRegion     string
+	CurrentService    string
+	ResourceCount     int
+	Error             error
 	ElapsedTime       time.Duration
 }
 
 // DiscoveryResult contains the results of a discovery run
 type DiscoveryResult struct {
+	RunID     int64
+	Status    string
 	StartTime time.Time
 	EndTime   time.Time
 	Resources []Resource
@@ -175,6 +284,10 @@ type DiscoveryResult struct {
 	Providers []string
 	Mode      DiscoveryMode
 	Errors    []error
+
+	// CoveredScopes lists the scopes that were discovered completely.
+	// Stored resources in these scopes that were not seen are tombstoned.
+	CoveredScopes []Scope
 }
 
 // DiscoveryStatus represents the status of discovery
@@ -2,6 +2,8 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -41,6 +43,326 @@ func TestDiscoveryOrchestrator_Discover(t *testing.T) {
 	assert.NotNil(t, result)
 }
 
+func TestDiscoveryOrchestrator_Enrichers(t *testing.T) {
+	storage := &MockStorage{}
+	opts := DiscoveryOptions{
+		Mode:        StandardMode,
+		MaxParallel: 5,
+	}
+
+	orchestrator := NewDiscoveryOrchestrator(map[string]CloudProvider{}, storage, opts)
+	first := &mockEnricher{name: "first", err: fmt.Errorf("boom")}
+	second := &mockEnricher{name: "second"}
+	orchestrator.RegisterEnricher(first)
+	orchestrator.RegisterEnricher(second)
+
+	resources := []Resource{{ID: "r1"}}
+	errs := orchestrator.enrichResources(context.Background(), resources)
+
+	assert.True(t, first.called)
+	assert.True(t, second.called, "a failing enricher must not stop the pipeline")
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "first")
+	assert.Equal(t, "second", resources[0].Name)
+}
+
+func TestDiscoveryOrchestrator_ProgressEvents(t *testing.T) {
+	var events []DiscoveryProgress
+	opts := DiscoveryOptions{
+		Mode:        QuickMode,
+		MaxParallel: 2,
+		ProgressHandler: func(progress DiscoveryProgress) {
+			events = append(events, progress)
+		},
+	}
+
+	providers := map[string]CloudProvider{
+		"mock": &mockProvider{
+			name:     "mock",
+			accounts: []Account{{ID: "a1", Provider: "mock"}, {ID: "a2", Provider: "mock"}},
+		},
+		"broken": &mockProvider{name: "broken", err: fmt.Errorf("no credentials")},
+	}
+
+	orchestrator := NewDiscoveryOrchestrator(providers, &MockStorage{}, opts)
+	result, err := orchestrator.Discover(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Resources, 2)
+
+	counts := make(map[ProgressEvent]int)
+	for _, event := range events {
+		counts[event.Event]++
+	}
+	assert.Equal(t, 2, counts[ProgressProviderStarted])
+	assert.Equal(t, 2, counts[ProgressProviderFinished])
+	assert.Equal(t, 1, counts[ProgressAccountsDiscovered])
+	assert.Equal(t, 2, counts[ProgressAccountStarted])
+	assert.Equal(t, 2, counts[ProgressAccountFinished])
+	assert.Equal(t, 2, counts[ProgressServiceStarted])
+	assert.Equal(t, 2, counts[ProgressServiceFinished])
+
+	var providerErr error
+	for _, event := range events {
+		if event.Event == ProgressProviderFinished && event.CurrentProvider == "broken" {
+			providerErr = event.Error
+		}
+	}
+	assert.EqualError(t, providerErr, "no credentials")
+
+	last := events[len(events)-1]
+	assert.Equal(t, ProgressProviderFinished, last.Event)
+	assert.Equal(t, "mock", last.CurrentProvider)
+	assert.Equal(t, 2, last.AccountsProcessed)
+	assert.Equal(t, 2, last.TotalAccounts)
+	assert.Equal(t, 2, last.ResourcesFound)
+}
+
+func TestDiscoveryOrchestrator_Resume(t *testing.T) {
+	storage := newCheckpointMockStorage()
+	provider := &mockProvider{
+		name:         "mock",
+		accounts:     []Account{{ID: "a1", Provider: "mock"}, {ID: "a2", Provider: "mock"}},
+		failAccounts: map[string]bool{"a2": true},
+	}
+	opts := DiscoveryOptions{Mode: QuickMode, MaxParallel: 2}
+
+	// First run: a1 completes and is checkpointed, a2 fails
+	first, err := NewDiscoveryOrchestrator(map[string]CloudProvider{"mock": provider}, storage, opts).
+		Discover(context.Background())
+	assert.NoError(t, err)
+	assert.NotZero(t, first.RunID)
+	assert.Len(t, storage.checkpoints, 1)
+	assert.Contains(t, storage.stored, "a1-vm")
+	assert.Equal(t, RunStatusPartial, first.Status)
+
+	// Resume: only a2 is discovered again, a1 comes from the checkpoint
+	provider.failAccounts = nil
+	provider.calls = nil
+	opts.ResumeRunID = first.RunID
+
+	second, err := NewDiscoveryOrchestrator(map[string]CloudProvider{"mock": provider}, storage, opts).
+		Discover(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, first.RunID, second.RunID)
+	assert.Equal(t, []string{"a2"}, provider.calls)
+	assert.Len(t, second.Resources, 2)
+	assert.Equal(t, RunStatusCompleted, storage.runs[first.RunID].Status)
+
+	// A completed run cannot be resumed
+	_, err = NewDiscoveryOrchestrator(map[string]CloudProvider{"mock": provider}, storage, opts).
+		Discover(context.Background())
+	assert.Error(t, err)
+}
+
+func TestDiscoveryOrchestrator_ResumeRequiresCheckpointStorage(t *testing.T) {
+	opts := DiscoveryOptions{Mode: QuickMode, MaxParallel: 1, ResumeRunID: 7}
+
+	_, err := NewDiscoveryOrchestrator(map[string]CloudProvider{}, &MockStorage{}, opts).
+		Discover(context.Background())
+	assert.Error(t, err)
+}
+
+func TestDiscoveryOrchestrator_CoveredScopes(t *testing.T) {
+	provider := &mockProvider{
+		name:         "mock",
+		accounts:     []Account{{ID: "a1", Provider: "mock"}, {ID: "a2", Provider: "mock"}},
+		failAccounts: map[string]bool{"a2": true},
+	}
+	opts := DiscoveryOptions{Mode: StandardMode, MaxParallel: 2}
+
+	result, err := NewDiscoveryOrchestrator(map[string]CloudProvider{"mock": provider}, &MockStorage{}, opts).
+		Discover(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Scope{{Provider: "mock", AccountID: "a1", Region: "global", Service: "compute"}},
+		result.CoveredScopes)
+}
+
+func TestDiscoveryOrchestrator_AccountFilter(t *testing.T) {
+	provider := &mockProvider{
+		name: "mock",
+		accounts: []Account{
+			{ID: "a1", Provider: "mock", Name: "prod"},
+			{ID: "a2", Provider: "mock", Name: "dev"},
+			{ID: "a3", Provider: "mock", Name: "sandbox"},
+		},
+	}
+	opts := DiscoveryOptions{Mode: StandardMode, MaxParallel: 2, Accounts: []string{"a1", "sandbox"}}
+
+	result, err := NewDiscoveryOrchestrator(map[string]CloudProvider{"mock": provider}, &MockStorage{}, opts).
+		Discover(context.Background())
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a1", "a3"}, provider.calls)
+	assert.Len(t, result.Accounts, 2)
+}
+
+func TestDiscoveryOptions_TrackService(t *testing.T) {
+	opts := DiscoveryOptions{coverage: &scopeCoverage{}}
+	account := Account{ID: "a1", Provider: "mock"}
+	found := []Resource{{ID: "r1"}}
+
+	// A skipped listing error marks the service as failed
+	resources := opts.TrackService(context.Background(), account, "r", "skipped", func(ctx context.Context) ([]Resource, error) {
+		RecordScopeError(ctx, fmt.Errorf("throttled"))
+		return found, nil
+	})
+	assert.Empty(t, resources)
+
+	// Partial listings are returned but never cover the service
+	resources = opts.TrackPartialService(context.Background(), account, "r", "partial", func(ctx context.Context) ([]Resource, error) {
+		return found, nil
+	})
+	assert.Equal(t, found, resources)
+
+	// A cancelled discovery never covers the service
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	resources = opts.TrackService(ctx, account, "r", "cancelled", func(ctx context.Context) ([]Resource, error) {
+		return nil, nil
+	})
+	assert.Empty(t, resources)
+
+	resources = opts.TrackService(context.Background(), account, "r", "complete", func(ctx context.Context) ([]Resource, error) {
+		return found, nil
+	})
+	assert.Equal(t, found, resources)
+	assert.Equal(t, []Scope{{Provider: "mock", AccountID: "a1", Region: "r", Service: "complete"}}, opts.coverage.list())
+}
+
+// mockProvider returns one resource per account through TrackService
+type mockProvider struct {
+	name         string
+	accounts     []Account
+	err          error
+	failAccounts map[string]bool
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (m *mockProvider) Name() string {
+	return m.name
+}
+
+func (m *mockProvider) DiscoverAccounts(ctx context.Context) ([]Account, error) {
+	return m.accounts, m.err
+}
+
+func (m *mockProvider) DiscoverResources(ctx context.Context, account Account, opts DiscoveryOptions) ([]Resource, error) {
+	m.mu.Lock()
+	m.calls = append(m.calls, account.ID)
+	m.mu.Unlock()
+
+	if m.failAccounts[account.ID] {
+		return nil, fmt.Errorf("access denied")
+	}
+	return opts.TrackService(ctx, account, "global", "compute", func(ctx context.Context) ([]Resource, error) {
+		return []Resource{{ID: account.ID + "-vm", Provider: m.name, AccountID: account.ID}}, nil
+	}), nil
+}
+
+func (m *mockProvider) ValidateCredentials(ctx context.Context) error {
+	return m.err
+}
+
+// checkpointMockStorage keeps runs and checkpoints in memory
+type checkpointMockStorage struct {
+	MockStorage
+
+	mu          sync.Mutex
+	runs        map[int64]*DiscoveryStatus
+	checkpoints []Checkpoint
+	stored      map[string]Resource
+	unitIDs     map[int64][]string
+}
+
+func newCheckpointMockStorage() *checkpointMockStorage {
+	return &checkpointMockStorage{
+		runs:    make(map[int64]*DiscoveryStatus),
+		stored:  make(map[string]Resource),
+		unitIDs: make(map[int64][]string),
+	}
+}
+
+func (m *checkpointMockStorage) CreateRun(result *DiscoveryResult) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := int64(len(m.runs) + 1)
+	m.runs[id] = &DiscoveryStatus{LastRun: result.StartTime, Status: RunStatusRunning}
+	return id, nil
+}
+
+func (m *checkpointMockStorage) GetRun(runID int64) (*DiscoveryStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run, ok := m.runs[runID]
+	if !ok {
+		return nil, fmt.Errorf("run %d not found", runID)
+	}
+	return run, nil
+}
+
+func (m *checkpointMockStorage) GetCheckpoints(runID int64) ([]Checkpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var checkpoints []Checkpoint
+	for _, checkpoint := range m.checkpoints {
+		if checkpoint.RunID == runID {
+			checkpoints = append(checkpoints, checkpoint)
+		}
+	}
+	return checkpoints, nil
+}
+
+func (m *checkpointMockStorage) GetCheckpointResources(runID int64) ([]Resource, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var resources []Resource
+	for _, id := range m.unitIDs[runID] {
+		resources = append(resources, m.stored[id])
+	}
+	return resources, nil
+}
+
+func (m *checkpointMockStorage) SaveCheckpoint(checkpoint Checkpoint, resources []Resource) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoints = append(m.checkpoints, checkpoint)
+	for _, resource := range resources {
+		m.stored[resource.ID] = resource
+		m.unitIDs[checkpoint.RunID] = append(m.unitIDs[checkpoint.RunID], resource.ID)
+	}
+	return nil
+}
+
+func (m *checkpointMockStorage) StoreDiscovery(result *DiscoveryResult) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[result.RunID].Status = result.Status
+	return nil
+}
+
+// mockEnricher records invocations and tags resources with its name
+type mockEnricher struct {
+	name   string
+	err    error
+	called bool
+}
+
+func (m *mockEnricher) Name() string {
+	return m.name
+}
+
+func (m *mockEnricher) Enrich(ctx context.Context, resources []Resource) error {
+	m.called = true
+	for i := range resources {
+		resources[i].Name = m.name
+	}
+	return m.err
+}
+
 // MockStorage for testing
 type MockStorage struct{}
 
@@ -124,6 +446,10 @@ func (m *MockStorage) GetResourceSummary() (*ResourceSummary, error) {
 	return &ResourceSummary{}, nil
 }
 
+func (m *MockStorage) GetResourceHistory(resourceID string) (*ResourceHistory, error) {
+	return &ResourceHistory{ResourceID: resourceID}, nil
+}
+
 func (m *MockStorage) GetDiscoveryStatus() (*DiscoveryStatus, error) {
 	return &DiscoveryStatus{}, nil
 }
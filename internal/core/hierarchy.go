@@ -0,0 +1,71 @@
+package core
+
+// Kinds of organization hierarchy nodes
+const (
+	OrgNodeOrganization       = "organization"
+	OrgNodeOrganizationalUnit = "organizational_unit"
+	OrgNodeManagementGroup    = "management_group"
+	OrgNodeFolder             = "folder"
+	OrgNodeAccount            = "account"
+)
+
+// OrgNode is a node of a cloud organization hierarchy: an AWS organization
+// or OU, an Azure management group, a GCP organization or folder, or an
+// account below them. IDs are unique per provider.
+type OrgNode struct {
+	ID       string `json:"id"`
+	Provider string `json:"provider"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+// OrgNodes returns the hierarchy nodes of accounts, followed by the accounts
+// themselves attached to their direct parent. Nodes shared by several
+// accounts are returned once.
+func OrgNodes(accounts []Account) []OrgNode {
+	var nodes []OrgNode
+	seen := make(map[[2]string]bool)
+
+	add := func(node OrgNode) {
+		key := [2]string{node.Provider, node.ID}
+		if node.ID == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		nodes = append(nodes, node)
+	}
+
+	for _, account := range accounts {
+		for _, node := range account.Hierarchy {
+			if node.Provider == "" {
+				node.Provider = account.Provider
+			}
+			add(node)
+		}
+	}
+
+	for _, account := range accounts {
+		node := OrgNode{
+			ID:       account.ID,
+			Provider: account.Provider,
+			Kind:     OrgNodeAccount,
+			Name:     account.Name,
+		}
+		if len(account.Hierarchy) > 0 {
+			node.ParentID = account.Hierarchy[len(account.Hierarchy)-1].ID
+		}
+		add(node)
+	}
+
+	return nodes
+}
+
+// OrgNodeSummary rolls up the resources of the accounts below a hierarchy node
+type OrgNodeSummary struct {
+	Node            OrgNode `json:"node"`
+	Path            string  `json:"path"` // Node names from the root down, joined by "/"
+	ResourceCount   int     `json:"resource_count"`
+	MonthlyCost     float64 `json:"monthly_cost"`
+	PublicResources int     `json:"public_resources"`
+}
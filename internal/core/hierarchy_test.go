@@ -0,0 +1,39 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgNodes(t *testing.T) {
+	organization := OrgNode{ID: "o-example", Kind: OrgNodeOrganization, Name: "o-example"}
+	security := OrgNode{ID: "ou-sec", Kind: OrgNodeOrganizationalUnit, Name: "Security", ParentID: "o-example"}
+	workloads := OrgNode{ID: "ou-work", Kind: OrgNodeOrganizationalUnit, Name: "Workloads", ParentID: "o-example"}
+
+	accounts := []Account{
+		{ID: "111111111111", Provider: "aws", Name: "audit", Hierarchy: []OrgNode{organization, security}},
+		{ID: "222222222222", Provider: "aws", Name: "prod", Hierarchy: []OrgNode{organization, workloads}},
+		{ID: "333333333333", Provider: "aws", Name: "standalone"},
+	}
+
+	nodes := OrgNodes(accounts)
+
+	assert.Equal(t, []OrgNode{
+		{ID: "o-example", Provider: "aws", Kind: OrgNodeOrganization, Name: "o-example"},
+		{ID: "ou-sec", Provider: "aws", Kind: OrgNodeOrganizationalUnit, Name: "Security", ParentID: "o-example"},
+		{ID: "ou-work", Provider: "aws", Kind: OrgNodeOrganizationalUnit, Name: "Workloads", ParentID: "o-example"},
+		{ID: "111111111111", Provider: "aws", Kind: OrgNodeAccount, Name: "audit", ParentID: "ou-sec"},
+		{ID: "222222222222", Provider: "aws", Kind: OrgNodeAccount, Name: "prod", ParentID: "ou-work"},
+		{ID: "333333333333", Provider: "aws", Kind: OrgNodeAccount, Name: "standalone"},
+	}, nodes)
+}
+
+func TestOrgNodes_SameIDAcrossProviders(t *testing.T) {
+	accounts := []Account{
+		{ID: "root", Provider: "azure", Name: "Tenant Root Group"},
+		{ID: "root", Provider: "gcp", Name: "root"},
+	}
+
+	assert.Len(t, OrgNodes(accounts), 2)
+}
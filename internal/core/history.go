@@ -0,0 +1,36 @@
+package core
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Resource lifecycle events, as recorded in resource_changes
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
+// ResourceEvent is a change in the life of a resource. Updates list the
+// changed fields; creation and deletion carry the configuration the
+// resource was found with or last had.
+type ResourceEvent struct {
+	RunID            int64           `json:"run_id,omitempty"` // Zero for changes stored outside a discovery run
+	Time             time.Time       `json:"time"`
+	Change           string          `json:"change"`
+	Fields           []FieldChange   `json:"fields,omitempty"`
+	OldConfiguration json.RawMessage `json:"old_configuration,omitempty"`
+	NewConfiguration json.RawMessage `json:"new_configuration,omitempty"`
+}
+
+// ResourceHistory is the timeline of a resource from the first time it was
+// seen to its deletion
+type ResourceHistory struct {
+	ResourceID string          `json:"resource_id"`
+	Resource   *Resource       `json:"resource,omitempty"` // Latest known state
+	FirstSeen  time.Time       `json:"first_seen"`         // Zero when the resource predates change tracking
+	Deleted    bool            `json:"deleted"`
+	DeletedAt  *time.Time      `json:"deleted_at,omitempty"`
+	Events     []ResourceEvent `json:"events"`
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ProgressEvent identifies what a DiscoveryProgress update describes
+type ProgressEvent string
+
+const (
+	ProgressProviderStarted    ProgressEvent = "provider_started"
+	ProgressProviderFinished   ProgressEvent = "provider_finished"
+	ProgressAccountsDiscovered ProgressEvent = "accounts_discovered"
+	ProgressAccountStarted     ProgressEvent = "account_started"
+	ProgressAccountFinished    ProgressEvent = "account_finished"
+	ProgressRegionStarted      ProgressEvent = "region_started"
+	ProgressRegionFinished     ProgressEvent = "region_finished"
+	ProgressServiceStarted     ProgressEvent = "service_started"
+	ProgressServiceFinished    ProgressEvent = "service_finished"
+)
+
+// ReportProgress sends a progress update to the configured handler, if any
+func (o DiscoveryOptions) ReportProgress(progress DiscoveryProgress) {
+	if o.ProgressHandler != nil {
+		o.ProgressHandler(progress)
+	}
+}
+
+// TrackService wraps the complete discovery of a service in a region with start
+// and finish events. When it succeeds the service is recorded as fully covered,
+// which allows resources that were not seen to be tombstoned.
+// A failing service is reported through the finish event and yields no resources.
+func (o DiscoveryOptions) TrackService(
+	ctx context.Context,
+	account Account,
+	region, service string,
+	discover func(ctx context.Context) ([]Resource, error),
+) []Resource {
+	return o.trackService(ctx, account, region, service, true, discover)
+}
+
+// TrackPartialService is like TrackService for discoveries that only return a
+// subset of the service's resources, so the service is never recorded as covered
+func (o DiscoveryOptions) TrackPartialService(
+	ctx context.Context,
+	account Account,
+	region, service string,
+	discover func(ctx context.Context) ([]Resource, error),
+) []Resource {
+	return o.trackService(ctx, account, region, service, false, discover)
+}
+
+// trackService runs a service discovery and reports its outcome
+func (o DiscoveryOptions) trackService(
+	ctx context.Context,
+	account Account,
+	region, service string,
+	complete bool,
+	discover func(ctx context.Context) ([]Resource, error),
+) []Resource {
+	o.ReportProgress(DiscoveryProgress{
+		Event:           ProgressServiceStarted,
+		CurrentProvider: account.Provider,
+		CurrentAccount:  account.ID,
+		CurrentRegion:   region,
+		CurrentService:  service,
+	})
+
+	scopeCtx, recorder := withScopeErrors(ctx)
+	resources, err := discover(scopeCtx)
+	if err == nil {
+		err = recorder.err()
+	}
+	if err == nil {
+		// A cancelled discovery may have returned early without an error
+		err = ctx.Err()
+	}
+
+	o.ReportProgress(DiscoveryProgress{
+		Event:           ProgressServiceFinished,
+		CurrentProvider: account.Provider,
+		CurrentAccount:  account.ID,
+		CurrentRegion:   region,
+		CurrentService:  service,
+		ResourceCount:   len(resources),
+		Error:           err,
+	})
+
+	if err != nil {
+		return nil
+	}
+
+	if complete && o.coverage != nil {
+		o.coverage.add(Scope{
+			Provider:  account.Provider,
+			AccountID: account.ID,
+			Region:    region,
+			Service:   service,
+		})
+	}
+	return resources
+}
+
+// progressTracker serializes progress events, fills in run-wide counters and
+// emits provider_finished once every account of a provider has been processed
+type progressTracker struct {
+	mu                sync.Mutex
+	handler           func(DiscoveryProgress)
+	startTime         time.Time
+	resourcesFound    int
+	accountsProcessed int
+	totalAccounts     int
+	pendingAccounts   map[string]int
+}
+
+// newProgressTracker creates a tracker for a discovery run
+func newProgressTracker(handler func(DiscoveryProgress)) *progressTracker {
+	return &progressTracker{
+		handler:         handler,
+		startTime:       time.Now(),
+		pendingAccounts: make(map[string]int),
+	}
+}
+
+// report updates the run-wide counters and forwards the event to the handler
+func (t *progressTracker) report(progress DiscoveryProgress) {
+	if t.handler == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	providerDone := false
+	switch progress.Event {
+	case ProgressAccountsDiscovered:
+		t.totalAccounts += progress.TotalAccounts
+		t.pendingAccounts[progress.CurrentProvider] = progress.TotalAccounts
+		providerDone = progress.TotalAccounts == 0
+	case ProgressAccountFinished:
+		t.accountsProcessed++
+		t.resourcesFound += progress.ResourceCount
+		t.pendingAccounts[progress.CurrentProvider]--
+		providerDone = t.pendingAccounts[progress.CurrentProvider] == 0
+	}
+
+	t.emit(progress)
+
+	if providerDone {
+		t.emit(DiscoveryProgress{
+			Event:           ProgressProviderFinished,
+			CurrentProvider: progress.CurrentProvider,
+		})
+	}
+}
+
+// emit stamps the run-wide counters on an event and calls the handler.
+// The caller must hold t.mu.
+func (t *progressTracker) emit(progress DiscoveryProgress) {
+	// accounts_discovered carries the per-provider account count,
+	// every other event carries the run-wide total
+	if progress.Event != ProgressAccountsDiscovered {
+		progress.TotalAccounts = t.totalAccounts
+	}
+	progress.ResourcesFound = t.resourcesFound
+	progress.AccountsProcessed = t.accountsProcessed
+	progress.ElapsedTime = time.Since(t.startTime)
+
+	t.handler(progress)
+}
@@ -31,6 +31,16 @@ type NativeToolProvider interface {
 	DiscoverWithNativeTool(ctx context.Context, account Account) ([]Resource, error)
 }
 
+// Enricher augments discovered resources with derived metadata such as
+// relationships, cost estimates and compliance flags
+type Enricher interface {
+	// Name returns the enricher name
+	Name() string
+
+	// Enrich updates resources in place
+	Enrich(ctx context.Context, resources []Resource) error
+}
+
 // Storage interface for persisting discovery results
 type Storage interface {
 	// Initialize sets up the storage backend
@@ -54,6 +64,9 @@ type Storage interface {
 	// GetResourceSummary returns aggregated resource statistics
 	GetResourceSummary() (*ResourceSummary, error)
 
+	// GetResourceHistory returns the timeline of changes of a resource
+	GetResourceHistory(resourceID string) (*ResourceHistory, error)
+
 	// Close closes the storage connection
 	Close() error
 }
@@ -89,6 +102,10 @@ type Account struct {
 	Region      string            `json:"region,omitempty"`
 	Tags        map[string]string `json:"tags,omitempty"`
 	Credentials Credentials       `json:"-"`
+
+	// Hierarchy lists the organization nodes above the account, from the
+	// root down to its direct parent
+	Hierarchy []OrgNode `json:"hierarchy,omitempty"`
 }
 
 // Credentials represents cloud provider credentials
@@ -118,11 +135,16 @@ type DiscoveryOptions struct {
 	Providers       []string // aws, azure, gcp
 	Accounts        []string // Specific accounts to scan
 	Regions         []string // Specific regions (empty = all)
+	ExcludeRegions  []string // Regions skipped when scanning all regions
 	ResourceTypes   []string // Specific resource types
 	UseNativeTools  bool     // Prefer cloud-native tools
 	MaxParallel     int      // Max parallel operations
 	Timeout         time.Duration
 	ProgressHandler func(DiscoveryProgress)
+	ResumeRunID     int64        // Resume an interrupted run (0 = start a new run)
+	Checkpointer    Checkpointer // Set by the orchestrator when storage supports checkpoints
+
+	coverage *scopeCoverage // Scopes fully covered by the current run
 }
 
 // Config represents the application configuration
@@ -131,9 +153,15 @@ type Config struct {
 	AWS       AWSConfig       `yaml:"aws"`
 	Azure     AzureConfig     `yaml:"azure"`
 	GCP       GCPConfig       `yaml:"gcp"`
+	File      FileConfig      `yaml:"file"`
+	Terraform TerraformConfig `yaml:"terraform"`
 	Discovery DiscoveryConfig `yaml:"discovery"`
 	Analysis  AnalysisConfig  `yaml:"analysis"`
 	Logging   LoggingConfig   `yaml:"logging"`
+
+	// Providers holds the settings of providers registered outside this
+	// repository, keyed by provider name
+	Providers map[string]map[string]interface{} `yaml:"providers" mapstructure:"providers"`
 }
 
 // StorageConfig represents storage configuration
@@ -146,16 +174,28 @@ type StorageConfig struct {
 
 // AWSConfig represents AWS configuration
 type AWSConfig struct {
-	Regions    []string `yaml:"regions" mapstructure:"regions"`
-	MaxRetries int      `yaml:"max_retries" mapstructure:"max_retries"`
-	Timeout    string   `yaml:"timeout" mapstructure:"timeout"`
+	Regions             []string            `yaml:"regions" mapstructure:"regions"`
+	MaxRetries          int                 `yaml:"max_retries" mapstructure:"max_retries"`
+	Timeout             string              `yaml:"timeout" mapstructure:"timeout"`
+	IncludeOptInRegions bool                `yaml:"include_opt_in_regions" mapstructure:"include_opt_in_regions"`
+	AssumeRole          AWSAssumeRoleConfig `yaml:"assume_role" mapstructure:"assume_role"`
+}
+
+// AWSAssumeRoleConfig configures the role assumed in organization member accounts
+type AWSAssumeRoleConfig struct {
+	RoleName        string `yaml:"role_name" mapstructure:"role_name"`               // Role name, e.g. CloudReconReadOnly
+	RoleARN         string `yaml:"role_arn" mapstructure:"role_arn"`                 // ARN template with an {account_id} placeholder
+	ExternalID      string `yaml:"external_id" mapstructure:"external_id"`           // Optional external ID required by the role trust policy
+	SessionDuration string `yaml:"session_duration" mapstructure:"session_duration"` // Optional session duration, e.g. 1h
+	SessionName     string `yaml:"session_name" mapstructure:"session_name"`         // Optional role session name
 }
 
 // AzureConfig represents Azure configuration
 type AzureConfig struct {
-	Subscriptions []string `yaml:"subscriptions" mapstructure:"subscriptions"`
+	Subscriptions []string `yaml:"subscriptions" mapstructure:"subscriptions"` // Optional allow-list, all accessible subscriptions when empty
 	MaxRetries    int      `yaml:"max_retries" mapstructure:"max_retries"`
 	Timeout       string   `yaml:"timeout" mapstructure:"timeout"`
+	Endpoint      string   `yaml:"endpoint" mapstructure:"endpoint"` // Azure Resource Manager endpoint, defaults to the public cloud
 }
 
 // GCPConfig represents GCP configuration
@@ -164,16 +204,28 @@ type GCPConfig struct {
 	OrganizationID   string   `yaml:"organization_id" mapstructure:"organization_id"`
 	CredentialsPath  string   `yaml:"credentials_path" mapstructure:"credentials_path"`
 	Projects         []string `yaml:"projects" mapstructure:"projects"`
+	Folders          []string `yaml:"folders" mapstructure:"folders"` // Scan every project below these folders
 	MaxRetries       int      `yaml:"max_retries" mapstructure:"max_retries"`
 	Timeout          string   `yaml:"timeout" mapstructure:"timeout"`
 	DiscoveryMethods []string `yaml:"discovery_methods" mapstructure:"discovery_methods"`
 }
 
+// FileConfig represents offline fixture provider configuration
+type FileConfig struct {
+	Path string `yaml:"path" mapstructure:"path"` // File or directory of JSON/YAML inventory documents
+}
+
+// TerraformConfig represents Terraform state provider configuration
+type TerraformConfig struct {
+	StatePaths []string `yaml:"state_paths" mapstructure:"state_paths"` // State files or directories searched for *.tfstate
+}
+
 // DiscoveryConfig represents discovery configuration
 type DiscoveryConfig struct {
-	MaxParallel    int    `yaml:"max_parallel" mapstructure:"max_parallel"`
-	Timeout        string `yaml:"timeout" mapstructure:"timeout"`
-	UseNativeTools bool   `yaml:"use_native_tools" mapstructure:"use_native_tools"`
+	MaxParallel    int      `yaml:"max_parallel" mapstructure:"max_parallel"`
+	Timeout        string   `yaml:"timeout" mapstructure:"timeout"`
+	UseNativeTools bool     `yaml:"use_native_tools" mapstructure:"use_native_tools"`
+	ExcludeRegions []string `yaml:"exclude_regions" mapstructure:"exclude_regions"`
 }
 
 // AnalysisConfig represents analysis configuration
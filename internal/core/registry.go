@@ -0,0 +1,151 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ProviderFactory creates a provider from the application configuration
+type ProviderFactory func(config *Config) (CloudProvider, error)
+
+// ProviderCapabilities describes what a provider supports
+type ProviderCapabilities struct {
+	NativeTool   string   // Cloud-native discovery tool, empty if the provider has none
+	Regions      []string // Regions scanned by default (empty = not region scoped)
+	AccountTypes []string // Kinds of accounts the provider discovers (account, subscription, project)
+}
+
+// SupportsNativeTool reports whether the provider can use a cloud-native discovery tool
+func (c ProviderCapabilities) SupportsNativeTool() bool {
+	return c.NativeTool != ""
+}
+
+// ProviderRegistration describes a provider known to the registry
+type ProviderRegistration struct {
+	Name          string
+	Description   string
+	ConfigSection string // Configuration file section holding the provider's settings
+	Factory       ProviderFactory
+	Capabilities  ProviderCapabilities
+	OptIn         bool // Only created when requested by name, e.g. offline sources
+}
+
+// ProviderRegistry holds the providers available to discovery
+type ProviderRegistry struct {
+	mu        sync.RWMutex
+	providers map[string]ProviderRegistration
+}
+
+// NewProviderRegistry creates an empty provider registry
+func NewProviderRegistry() *ProviderRegistry {
+	return &ProviderRegistry{
+		providers: make(map[string]ProviderRegistration),
+	}
+}
+
+// Register adds a provider to the registry
+func (r *ProviderRegistry) Register(registration ProviderRegistration) error {
+	if registration.Name == "" {
+		return NewValidationError("provider name is required", nil)
+	}
+	if registration.Factory == nil {
+		return NewValidationError("provider factory is required", nil).WithContext("provider", registration.Name)
+	}
+	if registration.ConfigSection == "" {
+		registration.ConfigSection = "providers." + registration.Name
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.providers[registration.Name]; exists {
+		return NewValidationError(fmt.Sprintf("provider %s is already registered", registration.Name), nil)
+	}
+	r.providers[registration.Name] = registration
+	return nil
+}
+
+// Get returns the registration of a provider
+func (r *ProviderRegistry) Get(name string) (ProviderRegistration, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	registration, ok := r.providers[name]
+	return registration, ok
+}
+
+// List returns all registrations sorted by name
+func (r *ProviderRegistry) List() []ProviderRegistration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	registrations := make([]ProviderRegistration, 0, len(r.providers))
+	for _, registration := range r.providers {
+		registrations = append(registrations, registration)
+	}
+	sort.Slice(registrations, func(i, j int) bool {
+		return registrations[i].Name < registrations[j].Name
+	})
+	return registrations
+}
+
+// Names returns the names of all registered providers sorted by name
+func (r *ProviderRegistry) Names() []string {
+	registrations := r.List()
+	names := make([]string, len(registrations))
+	for i, registration := range registrations {
+		names[i] = registration.Name
+	}
+	return names
+}
+
+// Create initializes the named providers, or every provider that is not
+// opt-in when names is empty. Providers that are unknown or fail to
+// initialize are reported in the returned errors and left out of the map.
+func (r *ProviderRegistry) Create(names []string, config *Config) (map[string]CloudProvider, []error) {
+	if len(names) == 0 {
+		for _, registration := range r.List() {
+			if !registration.OptIn {
+				names = append(names, registration.Name)
+			}
+		}
+	}
+
+	providers := make(map[string]CloudProvider)
+	var errs []error
+
+	for _, name := range names {
+		registration, ok := r.Get(name)
+		if !ok {
+			errs = append(errs, NewConfigError(fmt.Sprintf("unknown provider %s", name), nil))
+			continue
+		}
+
+		provider, err := registration.Factory(config)
+		if err != nil {
+			errs = append(errs, NewProviderError(fmt.Sprintf("failed to initialize %s provider", name), err))
+			continue
+		}
+		providers[name] = provider
+	}
+
+	return providers, errs
+}
+
+// defaultRegistry is the registry providers add themselves to at init time
+var defaultRegistry = NewProviderRegistry()
+
+// DefaultProviderRegistry returns the process-wide provider registry
+func DefaultProviderRegistry() *ProviderRegistry {
+	return defaultRegistry
+}
+
+// RegisterProvider adds a provider to the default registry.
+// It panics on invalid or duplicate registrations, so it is meant to be
+// called from a provider package's init function.
+func RegisterProvider(registration ProviderRegistration) {
+	if err := defaultRegistry.Register(registration); err != nil {
+		panic(err)
+	}
+}
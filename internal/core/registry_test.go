@@ -0,0 +1,54 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderRegistry_Register(t *testing.T) {
+	registry := NewProviderRegistry()
+	factory := func(config *Config) (CloudProvider, error) {
+		return &mockProvider{name: "mock"}, nil
+	}
+
+	assert.NoError(t, registry.Register(ProviderRegistration{Name: "mock", Factory: factory}))
+	assert.Error(t, registry.Register(ProviderRegistration{Name: "mock", Factory: factory}))
+	assert.Error(t, registry.Register(ProviderRegistration{Name: "nofactory"}))
+	assert.Error(t, registry.Register(ProviderRegistration{Factory: factory}))
+
+	registration, ok := registry.Get("mock")
+	assert.True(t, ok)
+	assert.Equal(t, "providers.mock", registration.ConfigSection)
+	assert.False(t, registration.Capabilities.SupportsNativeTool())
+}
+
+func TestProviderRegistry_Create(t *testing.T) {
+	registry := NewProviderRegistry()
+	assert.NoError(t, registry.Register(ProviderRegistration{
+		Name: "beta",
+		Factory: func(config *Config) (CloudProvider, error) {
+			return nil, fmt.Errorf("no credentials")
+		},
+	}))
+	assert.NoError(t, registry.Register(ProviderRegistration{
+		Name: "alpha",
+		Factory: func(config *Config) (CloudProvider, error) {
+			return &mockProvider{name: "alpha"}, nil
+		},
+		Capabilities: ProviderCapabilities{NativeTool: "inventory"},
+	}))
+
+	assert.Equal(t, []string{"alpha", "beta"}, registry.Names())
+
+	// Every provider is created when none are named
+	providers, errs := registry.Create(nil, &Config{})
+	assert.Len(t, providers, 1)
+	assert.Contains(t, providers, "alpha")
+	assert.Len(t, errs, 1)
+
+	providers, errs = registry.Create([]string{"alpha", "unknown"}, &Config{})
+	assert.Len(t, providers, 1)
+	assert.Len(t, errs, 1)
+}
@@ -0,0 +1,40 @@
+package core
+
+// Relationship directions. Dependencies point from the resource that
+// depends to the resource it depends on.
+const (
+	DirectionOutbound      = "outbound"      // The source depends on the target
+	DirectionInbound       = "inbound"       // The target depends on the source
+	DirectionBidirectional = "bidirectional" // Each depends on the other
+)
+
+// Relationship is a dependency between two resources found by dependency analysis
+type Relationship struct {
+	SourceID   string                 `json:"source_id"`
+	TargetID   string                 `json:"target_id"`
+	Type       string                 `json:"type"`
+	Direction  string                 `json:"direction"`
+	Confidence float64                `json:"confidence"`       // 0.0 to 1.0
+	RunID      int64                  `json:"run_id,omitempty"` // Discovery run whose inventory was analyzed
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// RelatedResource is a resource reached by following dependencies
+type RelatedResource struct {
+	ResourceID   string `json:"resource_id"`
+	Depth        int    `json:"depth"`        // Number of dependencies followed, 1 for direct ones
+	Relationship string `json:"relationship"` // Type of the last dependency followed
+}
+
+// RelationshipStorage is implemented by storage backends that persist the
+// results of dependency analysis. Upstream resources are those a resource
+// depends on, directly or transitively; downstream resources depend on it.
+// Every lookup can be restricted to some relationship types.
+type RelationshipStorage interface {
+	StoreRelationships(relationships []Relationship) error
+	RelationshipsCurrent() (bool, error)
+	GetRelationships(types ...string) ([]Relationship, error)
+	GetNeighbors(resourceID string, types ...string) ([]Relationship, error)
+	GetUpstream(resourceID string, types ...string) ([]RelatedResource, error)
+	GetDownstream(resourceID string, types ...string) ([]RelatedResource, error)
+}
@@ -79,6 +79,9 @@ type ResourceSummary struct {
 	CostByProvider   map[string]float64 `json:"cost_by_provider"`
 	SecurityIssues   int                `json:"security_issues"`
 	ComplianceIssues int                `json:"compliance_issues"`
+
+	// ByOrgNode rolls up resources by organization hierarchy node
+	ByOrgNode []OrgNodeSummary `json:"by_org_node,omitempty"`
 }
 
 // ResourceQuery represents a query for resources
@@ -0,0 +1,279 @@
+package core
+
+import (
+	"context"
+	"strings"
+)
+
+// ResourceSelector narrows discovery to the services and resource types
+// requested through DiscoveryOptions.ResourceTypes. A filter may be a native
+// type name (AWS::EC2::Instance, Microsoft.Compute/virtualMachines,
+// compute.googleapis.com/Instance), a whole service (ec2, AWS::EC2) or a
+// service:type pair (ec2:instance). An empty selector selects everything.
+type ResourceSelector struct {
+	provider string
+	filters  []typeFilter
+	aliases  map[string]string
+}
+
+// typeFilter is a parsed resource type filter
+type typeFilter struct {
+	provider     string // Empty when the filter applies to every provider
+	service      string
+	resourceType string // Empty selects every type of the service
+}
+
+// NewResourceSelector parses resource type filters for a provider.
+// serviceAliases maps native service names (e.g. elasticloadbalancingv2) to
+// the service names the provider uses in Resource.Service (e.g. elbv2).
+func NewResourceSelector(provider string, resourceTypes []string, serviceAliases map[string]string) ResourceSelector {
+	selector := ResourceSelector{
+		provider: strings.ToLower(provider),
+		aliases:  make(map[string]string, len(serviceAliases)),
+	}
+	for native, service := range serviceAliases {
+		selector.aliases[normalizeTypeName(native)] = normalizeTypeName(service)
+	}
+
+	for _, resourceType := range resourceTypes {
+		if filter, ok := parseTypeFilter(resourceType); ok {
+			selector.filters = append(selector.filters, filter)
+		}
+	}
+
+	return selector
+}
+
+// ResourceSelector returns the selector for a provider's discovery
+func (o DiscoveryOptions) ResourceSelector(provider string, serviceAliases map[string]string) ResourceSelector {
+	return NewResourceSelector(provider, o.ResourceTypes, serviceAliases)
+}
+
+// parseTypeFilter splits a filter into provider, service and type
+func parseTypeFilter(value string) (typeFilter, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return typeFilter{}, false
+	}
+
+	var filter typeFilter
+	switch {
+	case strings.Contains(value, "::"):
+		// AWS::EC2::Instance
+		parts := strings.Split(value, "::")
+		filter.provider = parts[0]
+		filter.service = parts[1]
+		if len(parts) > 2 {
+			filter.resourceType = parts[2]
+		}
+
+	case strings.HasPrefix(value, "microsoft."):
+		// Microsoft.Compute/virtualMachines
+		service, resourceType, _ := strings.Cut(strings.TrimPrefix(value, "microsoft."), "/")
+		filter.provider = "azure"
+		filter.service = service
+		filter.resourceType = resourceType
+
+	case strings.Contains(value, ".googleapis.com"):
+		// compute.googleapis.com/Instance
+		service, resourceType, _ := strings.Cut(value, "/")
+		filter.provider = "gcp"
+		filter.service = strings.TrimSuffix(service, ".googleapis.com")
+		filter.resourceType = resourceType
+
+	default:
+		// ec2 or ec2:instance
+		service, resourceType, _ := strings.Cut(value, ":")
+		filter.service = service
+		filter.resourceType = resourceType
+	}
+
+	if filter.resourceType == "*" {
+		filter.resourceType = ""
+	}
+	filter.service = normalizeTypeName(filter.service)
+	filter.resourceType = normalizeTypeName(filter.resourceType)
+
+	return filter, filter.service != ""
+}
+
+// normalizeTypeName lowercases a name and drops separators, so that
+// SecurityGroup, security-group and security_group compare equal
+func normalizeTypeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// All reports whether no filters are set
+func (s ResourceSelector) All() bool {
+	return len(s.filters) == 0
+}
+
+// Selects reports whether a resource type of a service is selected
+func (s ResourceSelector) Selects(service, resourceType string) bool {
+	if s.All() {
+		return true
+	}
+
+	resourceType = normalizeTypeName(resourceType)
+	for _, filter := range s.filters {
+		if s.matchesService(filter, service) && (filter.resourceType == "" || filter.resourceType == resourceType) {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectsService reports whether every resource type of a service is selected
+func (s ResourceSelector) SelectsService(service string) bool {
+	if s.All() {
+		return true
+	}
+
+	for _, filter := range s.filters {
+		if s.matchesService(filter, service) && filter.resourceType == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the selected resources
+func (s ResourceSelector) Filter(resources []Resource) []Resource {
+	if s.All() {
+		return resources
+	}
+
+	selected := make([]Resource, 0, len(resources))
+	for _, resource := range resources {
+		if s.Selects(resource.Service, resource.Type) {
+			selected = append(selected, resource)
+		}
+	}
+	return selected
+}
+
+// matchesService reports whether a filter applies to the provider's service
+func (s ResourceSelector) matchesService(filter typeFilter, service string) bool {
+	if filter.provider != "" && filter.provider != s.provider {
+		return false
+	}
+
+	filterService := filter.service
+	if alias, ok := s.aliases[filterService]; ok {
+		filterService = alias
+	}
+	return filterService == normalizeTypeName(service)
+}
+
+// ServiceDiscoverer groups the discoverers of one service. All of them run
+// under a single TrackService call, so the service only counts as covered
+// when every selected discoverer succeeded.
+type ServiceDiscoverer struct {
+	Service string
+	Types   []TypeDiscoverer
+}
+
+// TypeDiscoverer discovers one or more resource types of a service
+type TypeDiscoverer struct {
+	ResourceTypes []string
+	Discover      func(ctx context.Context) ([]Resource, error)
+}
+
+// DiscoverServices runs the discoverers picked by the selector for a region.
+// Services whose types are only partly selected, and every service in quick
+// mode, are tracked as partial so they never lead to tombstoning.
+func (o DiscoveryOptions) DiscoverServices(
+	ctx context.Context,
+	account Account,
+	region string,
+	selector ResourceSelector,
+	services []ServiceDiscoverer,
+) []Resource {
+	var resources []Resource
+
+	for _, sd := range services {
+		var selected []TypeDiscoverer
+		for _, td := range sd.Types {
+			for _, resourceType := range td.ResourceTypes {
+				if selector.Selects(sd.Service, resourceType) {
+					selected = append(selected, td)
+					break
+				}
+			}
+		}
+		if len(selected) == 0 {
+			continue
+		}
+
+		track := o.TrackService
+		if o.Mode == QuickMode || !selector.SelectsService(sd.Service) {
+			track = o.TrackPartialService
+		}
+
+		resources = append(resources, track(ctx, account, region, sd.Service, func(ctx context.Context) ([]Resource, error) {
+			var found []Resource
+			for _, td := range selected {
+				typeResources, err := td.Discover(ctx)
+				if err != nil {
+					return nil, err
+				}
+				// A discoverer returning several types may return unselected ones
+				if len(td.ResourceTypes) > 1 {
+					typeResources = selector.Filter(typeResources)
+				}
+				found = append(found, typeResources...)
+			}
+			return found, nil
+		})...)
+	}
+
+	return resources
+}
+
+// FilterAccounts keeps the accounts requested through DiscoveryOptions.Accounts,
+// matched by ID or name. All accounts are kept when no filter is set.
+func (o DiscoveryOptions) FilterAccounts(accounts []Account) []Account {
+	if len(o.Accounts) == 0 {
+		return accounts
+	}
+
+	wanted := make(map[string]bool, len(o.Accounts))
+	for _, id := range o.Accounts {
+		wanted[strings.TrimSpace(id)] = true
+	}
+
+	filtered := make([]Account, 0, len(accounts))
+	for _, account := range accounts {
+		if wanted[account.ID] || (account.Name != "" && wanted[account.Name]) {
+			filtered = append(filtered, account)
+		}
+	}
+	return filtered
+}
+
+// FilterRegions drops the regions listed in ExcludeRegions from an
+// enumerated region list
+func (o DiscoveryOptions) FilterRegions(regions []string) []string {
+	if len(o.ExcludeRegions) == 0 {
+		return regions
+	}
+
+	excluded := make(map[string]bool, len(o.ExcludeRegions))
+	for _, region := range o.ExcludeRegions {
+		excluded[strings.TrimSpace(region)] = true
+	}
+
+	filtered := make([]string, 0, len(regions))
+	for _, region := range regions {
+		if !excluded[region] {
+			filtered = append(filtered, region)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceSelector_Selects(t *testing.T) {
+	aliases := map[string]string{"elasticloadbalancingv2": "elbv2"}
+
+	tests := []struct {
+		name          string
+		provider      string
+		filters       []string
+		service       string
+		resourceType  string
+		selected      bool
+		wholeSelected bool
+	}{
+		{"no filters", "aws", nil, "ec2", "instance", true, true},
+		{"native type", "aws", []string{"AWS::EC2::Instance"}, "ec2", "instance", true, false},
+		{"native type other type", "aws", []string{"AWS::EC2::Instance"}, "ec2", "volume", false, false},
+		{"camel case type", "aws", []string{"AWS::EC2::SecurityGroup"}, "ec2", "security-group", true, false},
+		{"native service", "aws", []string{"AWS::S3"}, "s3", "bucket", true, true},
+		{"native wildcard", "aws", []string{"AWS::RDS::*"}, "rds", "db-cluster", true, true},
+		{"service alias", "aws", []string{"AWS::ElasticLoadBalancingV2::LoadBalancer"}, "elbv2", "load-balancer", true, false},
+		{"other provider", "gcp", []string{"AWS::EC2::Instance"}, "compute", "instance", false, false},
+		{"plain service", "gcp", []string{"compute"}, "compute", "disk", true, true},
+		{"service and type", "aws", []string{"iam:role"}, "iam", "role", true, false},
+		{"azure type", "azure", []string{"Microsoft.Compute/virtualMachines"}, "compute", "virtual-machines", true, false},
+		{"gcp type", "gcp", []string{"compute.googleapis.com/Instance"}, "compute", "instance", true, false},
+		{"gcp type on azure", "azure", []string{"compute.googleapis.com/Instance"}, "compute", "instance", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector := NewResourceSelector(tt.provider, tt.filters, aliases)
+			assert.Equal(t, tt.selected, selector.Selects(tt.service, tt.resourceType))
+			assert.Equal(t, tt.wholeSelected, selector.SelectsService(tt.service))
+		})
+	}
+}
+
+func TestResourceSelector_Filter(t *testing.T) {
+	selector := NewResourceSelector("aws", []string{"AWS::EC2::Instance", "AWS::S3::Bucket"}, nil)
+	resources := []Resource{
+		{ID: "i-1", Service: "ec2", Type: "instance"},
+		{ID: "vol-1", Service: "ec2", Type: "volume"},
+		{ID: "bucket", Service: "s3", Type: "bucket"},
+	}
+
+	filtered := selector.Filter(resources)
+
+	assert.Len(t, filtered, 2)
+	assert.Equal(t, "i-1", filtered[0].ID)
+	assert.Equal(t, "bucket", filtered[1].ID)
+}
+
+func TestDiscoveryOptions_DiscoverServices(t *testing.T) {
+	var called []string
+	discoverer := func(resourceType string, returned ...string) TypeDiscoverer {
+		return TypeDiscoverer{
+			ResourceTypes: append([]string{resourceType}, returned...),
+			Discover: func(ctx context.Context) ([]Resource, error) {
+				called = append(called, resourceType)
+				resources := []Resource{{ID: resourceType, Service: "ec2", Type: resourceType}}
+				for _, extra := range returned {
+					resources = append(resources, Resource{ID: extra, Service: "ec2", Type: extra})
+				}
+				return resources, nil
+			},
+		}
+	}
+	services := []ServiceDiscoverer{
+		{Service: "ec2", Types: []TypeDiscoverer{discoverer("instance", "volume"), discoverer("vpc")}},
+		{Service: "s3", Types: []TypeDiscoverer{{
+			ResourceTypes: []string{"bucket"},
+			Discover: func(ctx context.Context) ([]Resource, error) {
+				return nil, fmt.Errorf("access denied")
+			},
+		}}},
+	}
+	account := Account{ID: "a1", Provider: "aws"}
+
+	// A partly selected service is discovered but not covered
+	opts := DiscoveryOptions{Mode: StandardMode, ResourceTypes: []string{"AWS::EC2::Instance"}, coverage: &scopeCoverage{}}
+	resources := opts.DiscoverServices(context.Background(), account, "us-east-1", opts.ResourceSelector("aws", nil), services)
+
+	assert.Equal(t, []string{"instance"}, called)
+	assert.Equal(t, []Resource{{ID: "instance", Service: "ec2", Type: "instance"}}, resources)
+	assert.Empty(t, opts.coverage.list())
+
+	// Without filters every service runs and successful ones are covered
+	called = nil
+	opts = DiscoveryOptions{Mode: StandardMode, coverage: &scopeCoverage{}}
+	resources = opts.DiscoverServices(context.Background(), account, "us-east-1", opts.ResourceSelector("aws", nil), services)
+
+	assert.Equal(t, []string{"instance", "vpc"}, called)
+	assert.Len(t, resources, 3)
+	assert.Equal(t, []Scope{{Provider: "aws", AccountID: "a1", Region: "us-east-1", Service: "ec2"}}, opts.coverage.list())
+}
+
+func TestDiscoveryOptions_FilterRegions(t *testing.T) {
+	regions := []string{"us-east-1", "ap-south-1", "sa-east-1"}
+
+	assert.Equal(t, regions, DiscoveryOptions{}.FilterRegions(regions))
+	assert.Equal(t, []string{"us-east-1"},
+		DiscoveryOptions{ExcludeRegions: []string{"ap-south-1", "sa-east-1"}}.FilterRegions(regions))
+}
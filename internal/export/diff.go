@@ -0,0 +1,120 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// WriteDiff writes an inventory diff as text, JSON or Markdown
+func (e *Exporter) WriteDiff(w io.Writer, diff *core.InventoryDiff, format string) error {
+	switch strings.ToLower(format) {
+	case "text":
+		return writeDiffText(w, diff)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	case "markdown", "md":
+		return writeDiffMarkdown(w, diff)
+	default:
+		return fmt.Errorf("unsupported diff format: %s", format)
+	}
+}
+
+// writeDiffText writes a diff for the terminal
+func writeDiffText(w io.Writer, diff *core.InventoryDiff) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Changes from run %d to run %d: %d added, %d removed, %d modified\n",
+		diff.FromRun, diff.ToRun, len(diff.Added), len(diff.Removed), len(diff.Modified))
+
+	for _, resource := range diff.Added {
+		fmt.Fprintf(&b, "\n+ %s\n", describeDiffResource(resource))
+	}
+	for _, resource := range diff.Removed {
+		fmt.Fprintf(&b, "\n- %s\n", describeDiffResource(resource))
+	}
+	for _, resource := range diff.Modified {
+		fmt.Fprintf(&b, "\n~ %s\n", describeDiffResource(resource))
+		for _, field := range resource.Fields {
+			fmt.Fprintf(&b, "    %s: %s -> %s\n", field.Field, formatDiffValue(field.Old), formatDiffValue(field.New))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeDiffMarkdown writes a diff for change review notes
+func writeDiffMarkdown(w io.Writer, diff *core.InventoryDiff) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Inventory changes: run %d → run %d\n\n", diff.FromRun, diff.ToRun)
+	fmt.Fprintf(&b, "| Added | Removed | Modified |\n|---:|---:|---:|\n| %d | %d | %d |\n",
+		len(diff.Added), len(diff.Removed), len(diff.Modified))
+
+	writeSection := func(title string, resources []core.ResourceDiff) {
+		if len(resources) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+		b.WriteString("| Provider | Account | Service | Type | Name | ID |\n|---|---|---|---|---|---|\n")
+		for _, resource := range resources {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | `%s` |\n",
+				resource.Provider, resource.AccountID, resource.Service, resource.Type,
+				escapeMarkdown(resource.Name), resource.ID)
+		}
+	}
+	writeSection("Added", diff.Added)
+	writeSection("Removed", diff.Removed)
+
+	if len(diff.Modified) > 0 {
+		b.WriteString("\n## Modified\n")
+		for _, resource := range diff.Modified {
+			fmt.Fprintf(&b, "\n### %s\n\n", escapeMarkdown(describeDiffResource(resource)))
+			b.WriteString("| Field | Before | After |\n|---|---|---|\n")
+			for _, field := range resource.Fields {
+				fmt.Fprintf(&b, "| `%s` | %s | %s |\n", field.Field,
+					escapeMarkdown(formatDiffValue(field.Old)), escapeMarkdown(formatDiffValue(field.New)))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// describeDiffResource names a changed resource
+func describeDiffResource(resource core.ResourceDiff) string {
+	name := resource.Name
+	if name == "" {
+		name = resource.ID
+	}
+	return fmt.Sprintf("%s %s/%s %s (%s, %s)", resource.Provider, resource.Service, resource.Type,
+		name, resource.AccountID, resource.ID)
+}
+
+// formatDiffValue renders a field value; absent values are shown as (none)
+func formatDiffValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "(none)"
+	case string:
+		return fmt.Sprintf("%q", v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
+
+// escapeMarkdown keeps values from breaking table cells
+func escapeMarkdown(value string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(value)
+}
@@ -0,0 +1,541 @@
+package export
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/cloudrecon/cloudrecon/internal/analysis"
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// Node colors by provider, shared by every diagram format
+var providerColors = map[string]string{
+	"aws":       "#FF9900",
+	"azure":     "#0078D4",
+	"gcp":       "#34A853",
+	"terraform": "#7B42BC",
+}
+
+const (
+	defaultNodeColor     = "#D9D9D9"
+	publicBorderColor    = "#D62728"
+	encryptedBorderColor = "#2CA02C"
+)
+
+// graphNode is a node of an exported dependency graph. Resources that are
+// only known from a dependency have no provider.
+type graphNode struct {
+	ID       string
+	Resource core.Resource
+}
+
+// graphEdge is a dependency drawn from the dependent resource to the
+// resource it depends on
+type graphEdge struct {
+	From          string
+	To            string
+	Relationship  string
+	Confidence    float64
+	Bidirectional bool
+}
+
+// WriteGraph writes a dependency graph as a Graphviz DOT, GraphML, Mermaid
+// flowchart or Cytoscape.js diagram, or as JSON. Scope the graph with
+// DependencyGraph.Subgraph or WithTag before exporting part of it.
+func (e *Exporter) WriteGraph(w io.Writer, graph *analysis.DependencyGraph, format string) error {
+	switch strings.ToLower(format) {
+	case "dot", "graphviz":
+		return writeGraphDOT(w, graph)
+	case "graphml":
+		return writeGraphML(w, graph)
+	case "mermaid":
+		return writeGraphMermaid(w, graph)
+	case "cytoscape":
+		return writeGraphCytoscape(w, graph)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(graph)
+	default:
+		return fmt.Errorf("unsupported graph format: %s", format)
+	}
+}
+
+// graphNodes lists the nodes of a graph by provider, service, type and ID
+func graphNodes(graph *analysis.DependencyGraph) []graphNode {
+	known := make(map[string]bool, len(graph.Resources))
+	nodes := make([]graphNode, 0, len(graph.Resources))
+	for _, resource := range graph.Resources {
+		if !known[resource.ID] {
+			known[resource.ID] = true
+			nodes = append(nodes, graphNode{ID: resource.ID, Resource: resource})
+		}
+	}
+	for _, dep := range graph.Dependencies {
+		for _, id := range []string{dep.SourceID, dep.TargetID} {
+			if !known[id] {
+				known[id] = true
+				nodes = append(nodes, graphNode{ID: id, Resource: core.Resource{ID: id}})
+			}
+		}
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		a, b := nodes[i].Resource, nodes[j].Resource
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+	return nodes
+}
+
+// graphEdges lists the dependencies of a graph as edges, once each
+func graphEdges(graph *analysis.DependencyGraph) []graphEdge {
+	seen := make(map[graphEdge]bool)
+	var edges []graphEdge
+	for _, dep := range graph.Dependencies {
+		edge := graphEdge{From: dep.SourceID, To: dep.TargetID, Relationship: dep.Relationship}
+		switch dep.Direction {
+		case core.DirectionInbound:
+			edge.From, edge.To = dep.TargetID, dep.SourceID
+		case core.DirectionBidirectional:
+			edge.Bidirectional = true
+		}
+
+		if seen[edge] {
+			continue
+		}
+		seen[edge] = true
+		edge.Confidence = dep.Confidence
+		edges = append(edges, edge)
+	}
+	return edges
+}
+
+// nodeLabel names a node and its kind, with its public and encrypted markers
+func nodeLabel(node graphNode) []string {
+	resource := node.Resource
+	name := resource.Name
+	if name == "" {
+		name = node.ID
+	}
+
+	lines := []string{name}
+	if resource.Provider != "" {
+		lines = append(lines, strings.TrimSpace(fmt.Sprintf("%s/%s %s", resource.Provider, resource.Service, resource.Type)))
+	}
+	var markers []string
+	if resource.PublicAccess {
+		markers = append(markers, "public")
+	}
+	if resource.Encrypted {
+		markers = append(markers, "encrypted")
+	}
+	if len(markers) > 0 {
+		lines = append(lines, strings.Join(markers, ", "))
+	}
+	return lines
+}
+
+// nodeColor is the fill color of a node's provider
+func nodeColor(node graphNode) string {
+	if color, ok := providerColors[node.Resource.Provider]; ok {
+		return color
+	}
+	return defaultNodeColor
+}
+
+// groupNodes groups nodes by provider, then service, in node order. Nodes
+// without a provider are returned apart.
+func groupNodes(nodes []graphNode) (providers []string, services map[string][]string, members map[string][]graphNode, ungrouped []graphNode) {
+	services = make(map[string][]string)
+	members = make(map[string][]graphNode)
+	for _, node := range nodes {
+		provider, service := node.Resource.Provider, node.Resource.Service
+		if provider == "" {
+			ungrouped = append(ungrouped, node)
+			continue
+		}
+		if _, ok := services[provider]; !ok {
+			providers = append(providers, provider)
+			services[provider] = nil
+		}
+		key := provider + "/" + service
+		if _, ok := members[key]; !ok {
+			services[provider] = append(services[provider], service)
+		}
+		members[key] = append(members[key], node)
+	}
+	return providers, services, members, ungrouped
+}
+
+// writeGraphDOT writes a Graphviz digraph with a cluster per provider and
+// service. Render it with `dot -Tsvg`.
+func writeGraphDOT(w io.Writer, graph *analysis.DependencyGraph) error {
+	var b strings.Builder
+
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	writeNode := func(indent string, node graphNode) {
+		attrs := []string{
+			fmt.Sprintf("label=%s", dotQuote(strings.Join(nodeLabel(node), "\n"))),
+			fmt.Sprintf("fillcolor=%s", dotQuote(nodeColor(node))),
+		}
+		switch {
+		case node.Resource.PublicAccess:
+			attrs = append(attrs, fmt.Sprintf("color=%s", dotQuote(publicBorderColor)), "penwidth=2")
+		case node.Resource.Encrypted:
+			attrs = append(attrs, fmt.Sprintf("color=%s", dotQuote(encryptedBorderColor)), "penwidth=2")
+		}
+		if node.Resource.Provider == "" {
+			attrs = append(attrs, "style=\"rounded,dashed\"")
+		}
+		fmt.Fprintf(&b, "%s%s [%s];\n", indent, dotQuote(node.ID), strings.Join(attrs, ", "))
+	}
+
+	providers, services, members, ungrouped := groupNodes(graphNodes(graph))
+	for _, provider := range providers {
+		fmt.Fprintf(&b, "\n  subgraph %s {\n", dotQuote("cluster_"+provider))
+		fmt.Fprintf(&b, "    label=%s;\n", dotQuote(provider))
+		for _, service := range services[provider] {
+			fmt.Fprintf(&b, "    subgraph %s {\n", dotQuote("cluster_"+provider+"_"+service))
+			fmt.Fprintf(&b, "      label=%s;\n", dotQuote(service))
+			for _, node := range members[provider+"/"+service] {
+				writeNode("      ", node)
+			}
+			b.WriteString("    }\n")
+		}
+		b.WriteString("  }\n")
+	}
+	if len(ungrouped) > 0 {
+		b.WriteString("\n")
+		for _, node := range ungrouped {
+			writeNode("  ", node)
+		}
+	}
+
+	if edges := graphEdges(graph); len(edges) > 0 {
+		b.WriteString("\n")
+		for _, edge := range edges {
+			attrs := []string{fmt.Sprintf("label=%s", dotQuote(edge.Relationship))}
+			if edge.Bidirectional {
+				attrs = append(attrs, "dir=both")
+			}
+			fmt.Fprintf(&b, "  %s -> %s [%s];\n", dotQuote(edge.From), dotQuote(edge.To), strings.Join(attrs, ", "))
+		}
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote quotes a DOT identifier, keeping newlines as line breaks
+func dotQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return `"` + value + `"`
+}
+
+// writeGraphMermaid writes a Mermaid flowchart with a subgraph per provider
+// and service, ready to paste into Markdown
+func writeGraphMermaid(w io.Writer, graph *analysis.DependencyGraph) error {
+	var b strings.Builder
+
+	// Mermaid IDs must be plain words, so nodes are numbered
+	nodes := graphNodes(graph)
+	ids := make(map[string]string, len(nodes))
+	for i, node := range nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+	}
+
+	b.WriteString("flowchart LR\n")
+
+	writeNode := func(indent string, node graphNode) {
+		fmt.Fprintf(&b, "%s%s[\"%s\"]\n", indent, ids[node.ID], mermaidEscape(strings.Join(nodeLabel(node), "\n")))
+	}
+
+	providers, services, members, ungrouped := groupNodes(nodes)
+	for p, provider := range providers {
+		fmt.Fprintf(&b, "  subgraph p%d[\"%s\"]\n", p, mermaidEscape(provider))
+		for s, service := range services[provider] {
+			fmt.Fprintf(&b, "    subgraph p%ds%d[\"%s\"]\n", p, s, mermaidEscape(service))
+			for _, node := range members[provider+"/"+service] {
+				writeNode("      ", node)
+			}
+			b.WriteString("    end\n")
+		}
+		b.WriteString("  end\n")
+	}
+	for _, node := range ungrouped {
+		writeNode("  ", node)
+	}
+
+	for _, edge := range graphEdges(graph) {
+		arrow := "-->"
+		if edge.Bidirectional {
+			arrow = "<-->"
+		}
+		fmt.Fprintf(&b, "  %s %s|\"%s\"| %s\n", ids[edge.From], arrow, mermaidEscape(edge.Relationship), ids[edge.To])
+	}
+
+	// Classes color nodes by provider and outline public and encrypted ones
+	var classNames []string
+	classStyles := make(map[string]string)
+	classMembers := make(map[string][]string)
+	addClass := func(class, style, id string) {
+		if _, ok := classStyles[class]; !ok {
+			classNames = append(classNames, class)
+			classStyles[class] = style
+		}
+		classMembers[class] = append(classMembers[class], id)
+	}
+	for _, node := range nodes {
+		if provider := node.Resource.Provider; provider != "" {
+			addClass("provider_"+mermaidClass(provider), fmt.Sprintf("fill:%s,color:#000", nodeColor(node)), ids[node.ID])
+		}
+		if node.Resource.PublicAccess {
+			addClass("public", fmt.Sprintf("stroke:%s,stroke-width:3px", publicBorderColor), ids[node.ID])
+		}
+		if node.Resource.Encrypted {
+			addClass("encrypted", fmt.Sprintf("stroke:%s,stroke-width:3px", encryptedBorderColor), ids[node.ID])
+		}
+	}
+	for _, class := range classNames {
+		fmt.Fprintf(&b, "  classDef %s %s\n", class, classStyles[class])
+		fmt.Fprintf(&b, "  class %s %s\n", strings.Join(classMembers[class], ","), class)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidEscape escapes text for a quoted Mermaid label
+func mermaidEscape(value string) string {
+	return strings.NewReplacer(
+		`"`, "#quot;",
+		"<", "#lt;",
+		">", "#gt;",
+		"\n", "<br/>",
+	).Replace(value)
+}
+
+// mermaidClass turns a provider name into a class name
+func mermaidClass(value string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, value)
+}
+
+// GraphML document structure
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID       string        `xml:"id,attr"`
+	Source   string        `xml:"source,attr"`
+	Target   string        `xml:"target,attr"`
+	Directed bool          `xml:"directed,attr"`
+	Data     []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// writeGraphML writes a GraphML document, as read by yEd and Gephi
+func writeGraphML(w io.Writer, graph *analysis.DependencyGraph) error {
+	document := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "provider", For: "node", Name: "provider", Type: "string"},
+			{ID: "service", For: "node", Name: "service", Type: "string"},
+			{ID: "type", For: "node", Name: "type", Type: "string"},
+			{ID: "name", For: "node", Name: "name", Type: "string"},
+			{ID: "region", For: "node", Name: "region", Type: "string"},
+			{ID: "public", For: "node", Name: "public", Type: "boolean"},
+			{ID: "encrypted", For: "node", Name: "encrypted", Type: "boolean"},
+			{ID: "color", For: "node", Name: "color", Type: "string"},
+			{ID: "relationship", For: "edge", Name: "relationship", Type: "string"},
+			{ID: "confidence", For: "edge", Name: "confidence", Type: "double"},
+		},
+		Graph: graphMLGraph{ID: "dependencies", EdgeDefault: "directed"},
+	}
+
+	for _, node := range graphNodes(graph) {
+		resource := node.Resource
+		document.Graph.Nodes = append(document.Graph.Nodes, graphMLNode{
+			ID: node.ID,
+			Data: []graphMLData{
+				{Key: "label", Value: strings.Join(nodeLabel(node), "\n")},
+				{Key: "provider", Value: resource.Provider},
+				{Key: "service", Value: resource.Service},
+				{Key: "type", Value: resource.Type},
+				{Key: "name", Value: resource.Name},
+				{Key: "region", Value: resource.Region},
+				{Key: "public", Value: fmt.Sprintf("%t", resource.PublicAccess)},
+				{Key: "encrypted", Value: fmt.Sprintf("%t", resource.Encrypted)},
+				{Key: "color", Value: nodeColor(node)},
+			},
+		})
+	}
+
+	for i, edge := range graphEdges(graph) {
+		document.Graph.Edges = append(document.Graph.Edges, graphMLEdge{
+			ID:       fmt.Sprintf("e%d", i),
+			Source:   edge.From,
+			Target:   edge.To,
+			Directed: !edge.Bidirectional,
+			Data: []graphMLData{
+				{Key: "relationship", Value: edge.Relationship},
+				{Key: "confidence", Value: fmt.Sprintf("%g", edge.Confidence)},
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Cytoscape.js elements and styles, as loaded by cy.json()
+type cytoscapeGraph struct {
+	Elements cytoscapeElements `json:"elements"`
+	Style    []cytoscapeStyle  `json:"style"`
+}
+
+type cytoscapeElements struct {
+	Nodes []cytoscapeElement `json:"nodes"`
+	Edges []cytoscapeElement `json:"edges"`
+}
+
+type cytoscapeElement struct {
+	Data    map[string]interface{} `json:"data"`
+	Classes string                 `json:"classes,omitempty"`
+}
+
+type cytoscapeStyle struct {
+	Selector string            `json:"selector"`
+	Style    map[string]string `json:"style"`
+}
+
+// writeGraphCytoscape writes Cytoscape.js JSON with the styles to render it
+func writeGraphCytoscape(w io.Writer, graph *analysis.DependencyGraph) error {
+	document := cytoscapeGraph{
+		Elements: cytoscapeElements{Nodes: []cytoscapeElement{}, Edges: []cytoscapeElement{}},
+		Style: []cytoscapeStyle{
+			{Selector: "node", Style: map[string]string{
+				"label": "data(label)", "background-color": "data(color)", "shape": "round-rectangle",
+				"text-wrap": "wrap", "text-valign": "center", "font-size": "10px", "width": "label", "height": "label", "padding": "8px",
+			}},
+			{Selector: "node.public", Style: map[string]string{"border-width": "3px", "border-color": publicBorderColor}},
+			{Selector: "node.encrypted", Style: map[string]string{"border-width": "3px", "border-color": encryptedBorderColor}},
+			{Selector: "edge", Style: map[string]string{
+				"label": "data(label)", "curve-style": "bezier", "target-arrow-shape": "triangle", "font-size": "8px",
+			}},
+			{Selector: "edge.bidirectional", Style: map[string]string{"source-arrow-shape": "triangle"}},
+		},
+	}
+
+	for _, node := range graphNodes(graph) {
+		resource := node.Resource
+		var classes []string
+		if resource.Provider != "" {
+			classes = append(classes, resource.Provider, resource.Service)
+		}
+		if resource.PublicAccess {
+			classes = append(classes, "public")
+		}
+		if resource.Encrypted {
+			classes = append(classes, "encrypted")
+		}
+
+		document.Elements.Nodes = append(document.Elements.Nodes, cytoscapeElement{
+			Data: map[string]interface{}{
+				"id":        node.ID,
+				"label":     strings.Join(nodeLabel(node), "\n"),
+				"provider":  resource.Provider,
+				"service":   resource.Service,
+				"type":      resource.Type,
+				"name":      resource.Name,
+				"region":    resource.Region,
+				"public":    resource.PublicAccess,
+				"encrypted": resource.Encrypted,
+				"color":     nodeColor(node),
+			},
+			Classes: strings.Join(classes, " "),
+		})
+	}
+
+	for i, edge := range graphEdges(graph) {
+		element := cytoscapeElement{
+			Data: map[string]interface{}{
+				"id":           fmt.Sprintf("e%d", i),
+				"source":       edge.From,
+				"target":       edge.To,
+				"label":        edge.Relationship,
+				"relationship": edge.Relationship,
+				"confidence":   edge.Confidence,
+			},
+		}
+		if edge.Bidirectional {
+			element.Classes = "bidirectional"
+		}
+		document.Elements.Edges = append(document.Elements.Edges, element)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
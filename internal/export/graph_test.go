@@ -0,0 +1,191 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/cloudrecon/cloudrecon/internal/analysis"
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const siteID = "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Web/sites/api"
+
+// testGraph has names and relationships that need escaping, an inbound and
+// a bidirectional dependency, and a node only known from a dependency
+func testGraph() *analysis.DependencyGraph {
+	return &analysis.DependencyGraph{
+		Resources: []core.Resource{
+			{ID: "i-web", Provider: "aws", Service: "ec2", Type: "instance", Name: `web "frontend"`, PublicAccess: true},
+			{ID: "db[1]", Provider: "aws", Service: "rds", Type: "instance", Name: "orders\ndb", Encrypted: true},
+			{ID: "vpn-1", Provider: "aws", Service: "ec2", Type: "vpn-connection"},
+			{ID: siteID, Provider: "azure", Service: "web", Type: "site", Name: "api<prod>"},
+		},
+		Dependencies: []analysis.Dependency{
+			{SourceID: "i-web", TargetID: "db[1]", Relationship: `connects "tcp"`, Direction: core.DirectionOutbound, Confidence: 1},
+			{SourceID: siteID, TargetID: "i-web", Relationship: "invoked_by", Direction: core.DirectionInbound, Confidence: 0.8},
+			{SourceID: "vpn-1", TargetID: "peer-gw", Relationship: "vpn_peer", Direction: core.DirectionBidirectional, Confidence: 0.9},
+			// The same dependency found twice is drawn once
+			{SourceID: "i-web", TargetID: "db[1]", Relationship: `connects "tcp"`, Direction: core.DirectionOutbound, Confidence: 1},
+		},
+	}
+}
+
+func writeTestGraph(t *testing.T, format string) string {
+	t.Helper()
+
+	var out bytes.Buffer
+	require.NoError(t, NewExporter().WriteGraph(&out, testGraph(), format))
+	return out.String()
+}
+
+func TestWriteGraph_GraphML(t *testing.T) {
+	var document struct {
+		Graph struct {
+			EdgeDefault string `xml:"edgedefault,attr"`
+			Nodes       []struct {
+				ID   string `xml:"id,attr"`
+				Data []struct {
+					Key   string `xml:"key,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"data"`
+			} `xml:"node"`
+			Edges []struct {
+				Source   string `xml:"source,attr"`
+				Target   string `xml:"target,attr"`
+				Directed string `xml:"directed,attr"`
+				Data     []struct {
+					Key   string `xml:"key,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"data"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	require.NoError(t, xml.Unmarshal([]byte(writeTestGraph(t, "graphml")), &document))
+
+	graph := document.Graph
+	assert.Equal(t, "directed", graph.EdgeDefault)
+	require.Len(t, graph.Nodes, 5)
+	require.Len(t, graph.Edges, 3)
+
+	labels := make(map[string]string)
+	for _, node := range graph.Nodes {
+		for _, data := range node.Data {
+			if data.Key == "label" {
+				labels[node.ID] = data.Value
+			}
+		}
+	}
+	assert.Equal(t, "web \"frontend\"\naws/ec2 instance\npublic", labels["i-web"])
+	assert.Equal(t, "api<prod>\nazure/web site", labels[siteID])
+	assert.Equal(t, "peer-gw", labels["peer-gw"])
+
+	edges := make(map[string]string)
+	for _, edge := range graph.Edges {
+		edges[edge.Source+" -> "+edge.Target] = edge.Directed
+	}
+	assert.Equal(t, map[string]string{
+		"i-web -> db[1]":     "true",
+		"i-web -> " + siteID: "true",
+		"vpn-1 -> peer-gw":   "false",
+	}, edges)
+	assert.Equal(t, "relationship", graph.Edges[0].Data[0].Key)
+	assert.Equal(t, `connects "tcp"`, graph.Edges[0].Data[0].Value)
+}
+
+func TestWriteGraph_Cytoscape(t *testing.T) {
+	var document struct {
+		Elements struct {
+			Nodes []struct {
+				Data    map[string]interface{} `json:"data"`
+				Classes string                 `json:"classes"`
+			} `json:"nodes"`
+			Edges []struct {
+				Data    map[string]interface{} `json:"data"`
+				Classes string                 `json:"classes"`
+			} `json:"edges"`
+		} `json:"elements"`
+		Style []struct {
+			Selector string `json:"selector"`
+		} `json:"style"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(writeTestGraph(t, "cytoscape")), &document))
+
+	require.Len(t, document.Elements.Nodes, 5)
+	require.Len(t, document.Elements.Edges, 3)
+	assert.NotEmpty(t, document.Style)
+
+	classes := make(map[string]string)
+	for _, node := range document.Elements.Nodes {
+		classes[node.Data["id"].(string)] = node.Classes
+	}
+	assert.Equal(t, "aws ec2 public", classes["i-web"])
+	assert.Equal(t, "aws rds encrypted", classes["db[1]"])
+	assert.Equal(t, "", classes["peer-gw"])
+
+	edges := make(map[string]string)
+	for _, edge := range document.Elements.Edges {
+		edges[edge.Data["source"].(string)+" -> "+edge.Data["target"].(string)] = edge.Classes
+	}
+	assert.Equal(t, map[string]string{
+		"i-web -> db[1]":     "",
+		"i-web -> " + siteID: "",
+		"vpn-1 -> peer-gw":   "bidirectional",
+	}, edges)
+}
+
+func TestWriteGraph_DOT(t *testing.T) {
+	output := writeTestGraph(t, "dot")
+
+	assert.True(t, strings.HasPrefix(output, "digraph dependencies {\n"))
+	assert.Contains(t, output, `"i-web" [label="web \"frontend\"\naws/ec2 instance\npublic"`)
+	assert.Contains(t, output, `"db[1]" [label="orders\ndb\naws/rds instance\nencrypted"`)
+	assert.Contains(t, output, `"peer-gw" [label="peer-gw", fillcolor="#D9D9D9", style="rounded,dashed"];`)
+	assert.Contains(t, output, `subgraph "cluster_aws" {`)
+	assert.Contains(t, output, `subgraph "cluster_aws_ec2" {`)
+
+	// Inbound dependencies point from the dependent resource
+	assert.Contains(t, output, `"i-web" -> "db[1]" [label="connects \"tcp\""];`)
+	assert.Contains(t, output, `"i-web" -> "`+siteID+`" [label="invoked_by"];`)
+	assert.Contains(t, output, `"vpn-1" -> "peer-gw" [label="vpn_peer", dir=both];`)
+	assert.Equal(t, 3, strings.Count(output, " -> "))
+
+	// Labels never break a quoted string across lines
+	for _, line := range strings.Split(output, "\n") {
+		unescaped := strings.Count(line, `"`) - strings.Count(line, `\"`)
+		assert.Zero(t, unescaped%2, "unbalanced quotes in %q", line)
+	}
+}
+
+func TestWriteGraph_Mermaid(t *testing.T) {
+	output := writeTestGraph(t, "mermaid")
+
+	// Nodes are numbered in provider, service, type and ID order, starting
+	// with those without a provider
+	assert.True(t, strings.HasPrefix(output, "flowchart LR\n"))
+	assert.Contains(t, output, "  n0[\"peer-gw\"]\n")
+	assert.Contains(t, output, `n1["web #quot;frontend#quot;<br/>aws/ec2 instance<br/>public"]`)
+	assert.Contains(t, output, `n3["orders<br/>db<br/>aws/rds instance<br/>encrypted"]`)
+	assert.Contains(t, output, `n4["api#lt;prod#gt;<br/>azure/web site"]`)
+
+	assert.Contains(t, output, `  n1 -->|"connects #quot;tcp#quot;"| n3`)
+	assert.Contains(t, output, `  n1 -->|"invoked_by"| n4`)
+	assert.Contains(t, output, `  n2 <-->|"vpn_peer"| n0`)
+
+	assert.Contains(t, output, "  class n1 public\n")
+	assert.Contains(t, output, "  class n3 encrypted\n")
+	assert.Contains(t, output, "  class n1,n2,n3 provider_aws\n")
+
+	// Resource IDs with brackets and slashes never reach the diagram
+	assert.NotContains(t, output, "db[1]")
+	assert.NotContains(t, output, siteID)
+}
+
+func TestWriteGraph_UnsupportedFormat(t *testing.T) {
+	var out bytes.Buffer
+	assert.Error(t, NewExporter().WriteGraph(&out, testGraph(), "svg"))
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// WriteHistory writes the timeline of a resource as text or JSON
+func (e *Exporter) WriteHistory(w io.Writer, history *core.ResourceHistory, format string) error {
+	switch strings.ToLower(format) {
+	case "text":
+		return writeHistoryText(w, history)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(history)
+	default:
+		return fmt.Errorf("unsupported history format: %s", format)
+	}
+}
+
+// writeHistoryText writes a resource timeline for the terminal
+func writeHistoryText(w io.Writer, history *core.ResourceHistory) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Resource: %s\n", history.ResourceID)
+	if resource := history.Resource; resource != nil {
+		fmt.Fprintf(&b, "  %s %s/%s %s (%s, %s)\n", resource.Provider, resource.Service, resource.Type,
+			resource.Name, resource.AccountID, resource.Region)
+	}
+	if history.FirstSeen.IsZero() {
+		b.WriteString("First seen: before change tracking\n")
+	} else {
+		fmt.Fprintf(&b, "First seen: %s\n", formatHistoryTime(history.FirstSeen))
+	}
+	if history.Deleted && history.DeletedAt != nil {
+		fmt.Fprintf(&b, "Deleted:    %s\n", formatHistoryTime(*history.DeletedAt))
+	} else if history.Deleted {
+		b.WriteString("Deleted:    yes\n")
+	}
+
+	if len(history.Events) == 0 {
+		b.WriteString("\nNo changes recorded\n")
+	}
+	for _, event := range history.Events {
+		fmt.Fprintf(&b, "\n%s  %s", formatHistoryTime(event.Time), event.Change)
+		if event.RunID != 0 {
+			fmt.Fprintf(&b, " (run %d)", event.RunID)
+		}
+		b.WriteString("\n")
+		for _, field := range event.Fields {
+			fmt.Fprintf(&b, "    %s: %s -> %s\n", field.Field, formatDiffValue(field.Old), formatDiffValue(field.New))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatHistoryTime shows a change time in the local zone
+func formatHistoryTime(t time.Time) string {
+	if t.IsZero() {
+		return "unknown time"
+	}
+	return t.Local().Format("2006-01-02 15:04:05 MST")
+}
@@ -0,0 +1,139 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/cloudrecon/cloudrecon/internal/analysis"
+)
+
+// WriteImpact writes the blast radius of a resource as text, JSON or Markdown
+func (e *Exporter) WriteImpact(w io.Writer, report *analysis.ImpactReport, format string) error {
+	switch strings.ToLower(format) {
+	case "text":
+		return writeImpactText(w, report)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "markdown", "md":
+		return writeImpactMarkdown(w, report)
+	default:
+		return fmt.Errorf("unsupported impact format: %s", format)
+	}
+}
+
+// writeImpactText writes a blast radius for the terminal
+func writeImpactText(w io.Writer, report *analysis.ImpactReport) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Impact of %s\n", describeResource(report.Resource.Provider, report.Resource.Service,
+		report.Resource.Type, report.Resource.Name, report.Resource.ID))
+	fmt.Fprintf(&b, "Impacted Resources: %d\n", report.TotalImpacted)
+	fmt.Fprintf(&b, "Monthly Cost: $%.2f\n", report.TotalMonthlyCost)
+	fmt.Fprintf(&b, "Public Resources: %d\n", len(report.PublicResources))
+
+	if report.TotalImpacted == 0 {
+		b.WriteString("\nNothing depends on this resource\n")
+	}
+
+	for _, level := range report.ByDepth {
+		fmt.Fprintf(&b, "\nDepth %d:\n", level.Depth)
+		for _, impacted := range level.Resources {
+			resource := impacted.Resource
+			fmt.Fprintf(&b, "  %s [%s %s, $%.2f/month", describeResource(resource.Provider, resource.Service,
+				resource.Type, resource.Name, resource.ID), impacted.Relationship, impacted.DependsOn, resource.MonthlyCost)
+			if resource.PublicAccess {
+				b.WriteString(", public")
+			}
+			b.WriteString("]\n")
+		}
+	}
+
+	writeGroups := func(title string, groups map[string]analysis.ImpactGroup) {
+		if len(groups) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n%s:\n", title)
+		for _, key := range impactGroupKeys(groups) {
+			group := groups[key]
+			fmt.Fprintf(&b, "  %-30s %5d resources  $%10.2f/month  %d public\n",
+				key, group.Resources, group.MonthlyCost, group.PublicResources)
+		}
+	}
+	writeGroups("By Provider", report.ByProvider)
+	writeGroups("By Service", report.ByService)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeImpactMarkdown writes a blast radius for change-approval reviews
+func writeImpactMarkdown(w io.Writer, report *analysis.ImpactReport) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Impact of `%s`\n\n", report.Resource.ID)
+	fmt.Fprintf(&b, "| Impacted resources | Monthly cost | Public resources |\n|---:|---:|---:|\n| %d | $%.2f | %d |\n",
+		report.TotalImpacted, report.TotalMonthlyCost, len(report.PublicResources))
+
+	for _, level := range report.ByDepth {
+		fmt.Fprintf(&b, "\n## Depth %d\n\n", level.Depth)
+		b.WriteString("| Provider | Service | Type | Name | ID | Depends on | Monthly cost | Public |\n")
+		b.WriteString("|---|---|---|---|---|---|---:|---|\n")
+		for _, impacted := range level.Resources {
+			resource := impacted.Resource
+			public := ""
+			if resource.PublicAccess {
+				public = "yes"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | `%s` | `%s` (%s) | $%.2f | %s |\n",
+				resource.Provider, resource.Service, resource.Type, escapeMarkdown(resource.Name), resource.ID,
+				impacted.DependsOn, impacted.Relationship, resource.MonthlyCost, public)
+		}
+	}
+
+	writeGroups := func(title, column string, groups map[string]analysis.ImpactGroup) {
+		if len(groups) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n| %s | Resources | Monthly cost | Public |\n|---|---:|---:|---:|\n", title, column)
+		for _, key := range impactGroupKeys(groups) {
+			group := groups[key]
+			fmt.Fprintf(&b, "| %s | %d | $%.2f | %d |\n", key, group.Resources, group.MonthlyCost, group.PublicResources)
+		}
+	}
+	writeGroups("By provider", "Provider", report.ByProvider)
+	writeGroups("By service", "Service", report.ByService)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// describeResource names a resource
+func describeResource(provider, service, resourceType, name, id string) string {
+	if name == "" {
+		name = id
+	}
+	if service == "" && resourceType == "" {
+		return fmt.Sprintf("%s (%s)", name, id)
+	}
+	return fmt.Sprintf("%s %s/%s %s (%s)", provider, service, resourceType, name, id)
+}
+
+// impactGroupKeys orders groups by cost, then name
+func impactGroupKeys(groups map[string]analysis.ImpactGroup) []string {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if groups[keys[i]].MonthlyCost != groups[keys[j]].MonthlyCost {
+			return groups[keys[i]].MonthlyCost > groups[keys[j]].MonthlyCost
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
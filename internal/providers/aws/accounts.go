@@ -0,0 +1,200 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// accountIDPlaceholder is replaced with the member account ID in role ARN templates
+const accountIDPlaceholder = "{account_id}"
+
+// defaultSessionName identifies CloudRecon sessions in the member accounts' CloudTrail
+const defaultSessionName = "cloudrecon"
+
+// STS limits for role session durations
+const (
+	minSessionDuration = 15 * time.Minute
+	maxSessionDuration = 12 * time.Hour
+)
+
+// accountSession holds the configuration used to scan one member account.
+// The role is assumed once per account and run; the credentials cache
+// refreshes the session when it expires during long scans.
+type accountSession struct {
+	once   sync.Once
+	config aws.Config
+	err    error
+}
+
+// assumeRoleSettings is the validated aws.assume_role configuration
+type assumeRoleSettings struct {
+	roleName        string
+	roleARN         string
+	externalID      string
+	sessionName     string
+	sessionDuration time.Duration
+}
+
+// newAssumeRoleSettings validates the assume role configuration
+func newAssumeRoleSettings(cfg core.AWSAssumeRoleConfig) (assumeRoleSettings, error) {
+	settings := assumeRoleSettings{
+		roleName:    cfg.RoleName,
+		roleARN:     cfg.RoleARN,
+		externalID:  cfg.ExternalID,
+		sessionName: cfg.SessionName,
+	}
+	if settings.sessionName == "" {
+		settings.sessionName = defaultSessionName
+	}
+
+	if cfg.RoleARN != "" && !strings.Contains(cfg.RoleARN, accountIDPlaceholder) {
+		return settings, core.NewConfigError(
+			fmt.Sprintf("aws.assume_role.role_arn must contain the %s placeholder", accountIDPlaceholder), nil,
+		).WithContext("role_arn", cfg.RoleARN)
+	}
+
+	if cfg.SessionDuration != "" {
+		duration, err := time.ParseDuration(cfg.SessionDuration)
+		if err != nil {
+			return settings, core.NewConfigError("invalid aws.assume_role.session_duration", err)
+		}
+		if duration < minSessionDuration || duration > maxSessionDuration {
+			return settings, core.NewConfigError(
+				fmt.Sprintf("aws.assume_role.session_duration must be between %v and %v", minSessionDuration, maxSessionDuration), nil,
+			)
+		}
+		settings.sessionDuration = duration
+	}
+
+	return settings, nil
+}
+
+// enabled reports whether member accounts can be scanned
+func (s assumeRoleSettings) enabled() bool {
+	return s.roleName != "" || s.roleARN != ""
+}
+
+// roleARNFor returns the ARN of the role to assume in an account
+func (s assumeRoleSettings) roleARNFor(accountID string) string {
+	if s.roleARN != "" {
+		return strings.ReplaceAll(s.roleARN, accountIDPlaceholder, accountID)
+	}
+	return fmt.Sprintf("arn:aws:iam::%s:role/%s", accountID, s.roleName)
+}
+
+// accountConfig returns the configuration used to scan an account: the
+// loaded credentials for the caller's own account, assumed-role
+// credentials for every other account of the organization
+func (p *AWSProvider) accountConfig(ctx context.Context, account core.Account) (aws.Config, error) {
+	callerAccountID, err := p.callerAccountID(ctx)
+	if err != nil {
+		return aws.Config{}, err
+	}
+	if account.ID == callerAccountID {
+		return p.config, nil
+	}
+
+	if !p.assumeRole.enabled() {
+		return aws.Config{}, core.NewConfigError(
+			fmt.Sprintf("cannot scan member account %s: configure aws.assume_role.role_name or aws.assume_role.role_arn", account.ID), nil,
+		).WithContext("account", account.ID)
+	}
+
+	p.sessionsMu.Lock()
+	session, ok := p.sessions[account.ID]
+	if !ok {
+		session = &accountSession{}
+		p.sessions[account.ID] = session
+	}
+	p.sessionsMu.Unlock()
+
+	session.once.Do(func() {
+		session.config, session.err = p.assumeAccountRole(ctx, account.ID)
+	})
+	return session.config, session.err
+}
+
+// assumeAccountRole builds a configuration with credentials of the role
+// assumed in a member account
+func (p *AWSProvider) assumeAccountRole(ctx context.Context, accountID string) (aws.Config, error) {
+	roleARN := p.assumeRole.roleARNFor(accountID)
+
+	stsClient := sts.NewFromConfig(p.config, func(o *sts.Options) {
+		if o.Region == "" {
+			// STS needs an endpoint, the global one is in us-east-1
+			o.Region = "us-east-1"
+		}
+	})
+	provider := stscreds.NewAssumeRoleProvider(stsClient, roleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = p.assumeRole.sessionName
+		if p.assumeRole.externalID != "" {
+			o.ExternalID = aws.String(p.assumeRole.externalID)
+		}
+		if p.assumeRole.sessionDuration > 0 {
+			o.Duration = p.assumeRole.sessionDuration
+		}
+	})
+
+	config := p.config.Copy()
+	config.Credentials = aws.NewCredentialsCache(provider)
+
+	// Assume the role up front so a missing role or trust policy fails the
+	// account once instead of every region and service failing on their own
+	if _, err := config.Credentials.Retrieve(ctx); err != nil {
+		return aws.Config{}, core.NewAuthError(
+			fmt.Sprintf("failed to assume role %s in account %s", roleARN, accountID), err,
+		).WithContext("account", accountID).WithContext("role_arn", roleARN)
+	}
+
+	p.accountIDs.Store(config.Credentials, accountID)
+	return config, nil
+}
+
+// callerAccountID returns the account the loaded credentials belong to
+func (p *AWSProvider) callerAccountID(ctx context.Context) (string, error) {
+	p.callerMu.Lock()
+	defer p.callerMu.Unlock()
+
+	if p.callerID != "" {
+		return p.callerID, nil
+	}
+
+	identity, err := sts.NewFromConfig(p.config).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", core.NewAuthError("failed to get caller identity", err)
+	}
+
+	p.setCallerAccountID(aws.ToString(identity.Account))
+	return p.callerID, nil
+}
+
+// setCallerAccountID records the account of the loaded credentials.
+// The caller must hold callerMu.
+func (p *AWSProvider) setCallerAccountID(accountID string) {
+	p.callerID = accountID
+	if cache, ok := p.config.Credentials.(*aws.CredentialsCache); ok {
+		p.accountIDs.Store(cache, accountID)
+	}
+}
+
+// getAccountIDFromConfig returns the account a configuration's credentials
+// belong to, or an empty string if the account is not known yet
+func (p *AWSProvider) getAccountIDFromConfig(config aws.Config) string {
+	// Every scanned account has its own credentials cache
+	cache, ok := config.Credentials.(*aws.CredentialsCache)
+	if !ok {
+		return ""
+	}
+	if accountID, ok := p.accountIDs.Load(cache); ok {
+		return accountID.(string)
+	}
+	return ""
+}
@@ -0,0 +1,245 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAssumeRoleSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   core.AWSAssumeRoleConfig
+		enabled  bool
+		duration time.Duration
+		session  string
+		wantErr  bool
+	}{
+		{name: "not configured", config: core.AWSAssumeRoleConfig{}, session: defaultSessionName},
+		{name: "role name", config: core.AWSAssumeRoleConfig{RoleName: "CloudReconReadOnly"}, enabled: true, session: defaultSessionName},
+		{
+			name:    "role ARN template",
+			config:  core.AWSAssumeRoleConfig{RoleARN: "arn:aws:iam::{account_id}:role/audit/Reader", SessionName: "audit"},
+			enabled: true,
+			session: "audit",
+		},
+		{
+			name:     "session duration",
+			config:   core.AWSAssumeRoleConfig{RoleName: "Reader", SessionDuration: "2h"},
+			enabled:  true,
+			duration: 2 * time.Hour,
+			session:  defaultSessionName,
+		},
+		{name: "role ARN without placeholder", config: core.AWSAssumeRoleConfig{RoleARN: "arn:aws:iam::123456789012:role/Reader"}, wantErr: true},
+		{name: "invalid duration", config: core.AWSAssumeRoleConfig{RoleName: "Reader", SessionDuration: "soon"}, wantErr: true},
+		{name: "duration too short", config: core.AWSAssumeRoleConfig{RoleName: "Reader", SessionDuration: "5m"}, wantErr: true},
+		{name: "duration too long", config: core.AWSAssumeRoleConfig{RoleName: "Reader", SessionDuration: "24h"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := newAssumeRoleSettings(tt.config)
+			if tt.wantErr {
+				assertConfigError(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.enabled, settings.enabled())
+			assert.Equal(t, tt.duration, settings.sessionDuration)
+			assert.Equal(t, tt.session, settings.sessionName)
+		})
+	}
+}
+
+func TestAssumeRoleSettings_RoleARNFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   core.AWSAssumeRoleConfig
+		expected string
+	}{
+		{
+			name:     "role name",
+			config:   core.AWSAssumeRoleConfig{RoleName: "CloudReconReadOnly"},
+			expected: "arn:aws:iam::210987654321:role/CloudReconReadOnly",
+		},
+		{
+			name:     "template with path",
+			config:   core.AWSAssumeRoleConfig{RoleARN: "arn:aws:iam::{account_id}:role/audit/Reader"},
+			expected: "arn:aws:iam::210987654321:role/audit/Reader",
+		},
+		{
+			name:     "template in another partition",
+			config:   core.AWSAssumeRoleConfig{RoleARN: "arn:aws-us-gov:iam::{account_id}:role/Reader"},
+			expected: "arn:aws-us-gov:iam::210987654321:role/Reader",
+		},
+		{
+			name:     "template takes precedence over the role name",
+			config:   core.AWSAssumeRoleConfig{RoleName: "Ignored", RoleARN: "arn:aws:iam::{account_id}:role/Reader"},
+			expected: "arn:aws:iam::210987654321:role/Reader",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := newAssumeRoleSettings(tt.config)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, settings.roleARNFor("210987654321"))
+		})
+	}
+}
+
+func TestAccountConfig(t *testing.T) {
+	const callerID, memberID = "123456789012", "210987654321"
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		config     core.AWSAssumeRoleConfig
+		accountID  string
+		wantErr    bool
+		wantAssume url.Values
+	}{
+		{name: "caller account uses the loaded credentials", accountID: callerID},
+		{name: "member account without a role", accountID: memberID, wantErr: true},
+		{
+			name:      "member account with a role name",
+			config:    core.AWSAssumeRoleConfig{RoleName: "CloudReconReadOnly", ExternalID: "ext-1", SessionDuration: "1h"},
+			accountID: memberID,
+			wantAssume: url.Values{
+				"RoleArn":         {"arn:aws:iam::210987654321:role/CloudReconReadOnly"},
+				"RoleSessionName": {defaultSessionName},
+				"ExternalId":      {"ext-1"},
+				"DurationSeconds": {"3600"},
+			},
+		},
+		{
+			name:      "member account with a role ARN template",
+			config:    core.AWSAssumeRoleConfig{RoleARN: "arn:aws:iam::{account_id}:role/audit/Reader", SessionName: "audit"},
+			accountID: memberID,
+			wantAssume: url.Values{
+				"RoleArn":         {"arn:aws:iam::210987654321:role/audit/Reader"},
+				"RoleSessionName": {"audit"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sts := &fakeSTS{}
+			p := newTestProvider(t, tt.config, sts)
+			p.callerID = callerID
+
+			config, err := p.accountConfig(ctx, core.Account{ID: tt.accountID, Provider: "aws"})
+			if tt.wantErr {
+				assertConfigError(t, err)
+				assert.Empty(t, sts.requests)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.wantAssume == nil {
+				assert.Same(t, p.config.Credentials, config.Credentials)
+				assert.Empty(t, sts.requests)
+				return
+			}
+
+			creds, err := config.Credentials.Retrieve(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "ASIAMEMBER", creds.AccessKeyID)
+			assert.Equal(t, tt.accountID, p.getAccountIDFromConfig(config))
+
+			require.Len(t, sts.requests, 1)
+			assert.Equal(t, "AssumeRole", sts.requests[0].Get("Action"))
+			for key, value := range tt.wantAssume {
+				assert.Equal(t, value, sts.requests[0][key], key)
+			}
+
+			// The role is assumed once per account
+			again, err := p.accountConfig(ctx, core.Account{ID: tt.accountID, Provider: "aws"})
+			require.NoError(t, err)
+			assert.Same(t, config.Credentials, again.Credentials)
+			assert.Len(t, sts.requests, 1)
+		})
+	}
+}
+
+// assertConfigError checks that err is a CloudRecon configuration error
+func assertConfigError(t *testing.T, err error) {
+	t.Helper()
+
+	var reconErr *core.CloudReconError
+	require.ErrorAs(t, err, &reconErr)
+	assert.Equal(t, core.ErrorTypeConfig, reconErr.Type)
+}
+
+// newTestProvider creates a provider whose AWS calls are served by sts
+func newTestProvider(t *testing.T, cfg core.AWSAssumeRoleConfig, sts *fakeSTS) *AWSProvider {
+	t.Helper()
+
+	assumeRole, err := newAssumeRoleSettings(cfg)
+	require.NoError(t, err)
+
+	return &AWSProvider{
+		config: aws.Config{
+			Region:      "us-east-1",
+			Credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider("AKIACALLER", "secret", "")),
+			HTTPClient:  sts,
+		},
+		cache:      &memoryCache{},
+		assumeRole: assumeRole,
+		sessions:   make(map[string]*accountSession),
+	}
+}
+
+// fakeSTS answers AssumeRole requests and records their parameters
+type fakeSTS struct {
+	mu       sync.Mutex
+	requests []url.Values
+}
+
+func (f *fakeSTS) Do(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, values)
+	f.mu.Unlock()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"text/xml"}},
+		Body: io.NopCloser(strings.NewReader(`<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>ASIAMEMBER</AccessKeyId>
+      <SecretAccessKey>member-secret</SecretAccessKey>
+      <SessionToken>member-token</SessionToken>
+      <Expiration>2099-01-01T00:00:00Z</Expiration>
+    </Credentials>
+    <AssumedRoleUser>
+      <Arn>arn:aws:sts::210987654321:assumed-role/Reader/cloudrecon</Arn>
+      <AssumedRoleId>AROAMEMBER:cloudrecon</AssumedRoleId>
+    </AssumedRoleUser>
+  </AssumeRoleResult>
+  <ResponseMetadata><RequestId>req-1</RequestId></ResponseMetadata>
+</AssumeRoleResponse>`)),
+		Request: req,
+	}, nil
+}
@@ -3,21 +3,54 @@ package aws
 import (
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go/middleware"
 	"github.com/cloudrecon/cloudrecon/internal/core"
 	"github.com/sirupsen/logrus"
 )
 
 type AWSProvider struct {
-	config       aws.Config
-	credentials  core.Credentials //nolint:unused
-	cache        core.Cache
-	configClient *AWSConfigClient
+	config              aws.Config
+	credentials         core.Credentials //nolint:unused
+	cache               core.Cache
+	includeOptInRegions bool
+
+	// Cross-account access to organization member accounts
+	assumeRole assumeRoleSettings
+	sessionsMu sync.Mutex
+	sessions   map[string]*accountSession
+	accountIDs sync.Map // Credentials cache -> account ID
+	callerMu   sync.Mutex
+	callerID   string
+}
+
+func init() {
+	core.RegisterProvider(core.ProviderRegistration{
+		Name:          "aws",
+		Description:   "Amazon Web Services",
+		ConfigSection: "aws",
+		Factory: func(config *core.Config) (core.CloudProvider, error) {
+			provider, err := NewProvider(config.AWS)
+			if err != nil {
+				return nil, err
+			}
+			return provider, nil
+		},
+		Capabilities: core.ProviderCapabilities{
+			NativeTool:   "AWS Config",
+			Regions:      defaultRegions,
+			AccountTypes: []string{"account"},
+		},
+	})
 }
 
 // NewProvider creates a new AWS provider
@@ -30,14 +63,41 @@ func NewProvider(cfg core.AWSConfig) (*AWSProvider, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
+	awsConfig.APIOptions = append(awsConfig.APIOptions, recordListErrors)
+
+	assumeRole, err := newAssumeRoleSettings(cfg.AssumeRole)
+	if err != nil {
+		return nil, err
+	}
 
 	return &AWSProvider{
-		config:       awsConfig,
-		cache:        &memoryCache{},
-		configClient: NewAWSConfigClient(awsConfig),
+		config:              awsConfig,
+		cache:               &memoryCache{},
+		includeOptInRegions: cfg.IncludeOptInRegions,
+		assumeRole:          assumeRole,
+		sessions:            make(map[string]*accountSession),
 	}, nil
 }
 
+// recordListErrors reports failed List and Describe calls to the discovery
+// scope, because the discovery helpers log and skip them. A service whose
+// listing failed must never be treated as fully covered.
+func recordListErrors(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("CloudReconRecordListErrors",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (
+			middleware.InitializeOutput, middleware.Metadata, error,
+		) {
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			if err != nil {
+				operation := awsmiddleware.GetOperationName(ctx)
+				if strings.HasPrefix(operation, "List") || strings.HasPrefix(operation, "Describe") {
+					core.RecordScopeError(ctx, err)
+				}
+			}
+			return out, metadata, err
+		}), middleware.After)
+}
+
 // Name returns the provider name
 func (p *AWSProvider) Name() string {
 	return "aws"
@@ -51,13 +111,26 @@ func (p *AWSProvider) DiscoverAccounts(ctx context.Context) ([]core.Account, err
 	orgClient := organizations.NewFromConfig(p.config)
 
 	// Check if we have organizations access
-	_, err := orgClient.DescribeOrganization(ctx, &organizations.DescribeOrganizationInput{})
+	org, err := orgClient.DescribeOrganization(ctx, &organizations.DescribeOrganizationInput{})
 	if err == nil {
 		// We have organizations access, list all accounts
 		accounts, err = p.discoverAccountsViaOrganizations(ctx, orgClient)
 		if err != nil {
 			logrus.Warnf("Failed to discover accounts via organizations: %v", err)
 		}
+
+		// Attach the OU path of every account; accounts still get scanned
+		// when the OU tree cannot be read
+		if org.Organization != nil && len(accounts) > 0 {
+			hierarchies, err := p.organizationHierarchy(ctx, orgClient, aws.ToString(org.Organization.Id))
+			if err != nil {
+				logrus.Warnf("Failed to read organizational units: %v", err)
+			}
+			for i := range accounts {
+				accounts[i].Hierarchy = hierarchies[accounts[i].ID]
+				addOrganizationTags(accounts[i].Tags, accounts[i].Hierarchy)
+			}
+		}
 	}
 
 	// Always try to get current account
@@ -94,7 +167,11 @@ func (p *AWSProvider) DiscoverResources(
 	// Try native tools first
 	if opts.UseNativeTools {
 		if available, _ := p.IsNativeToolAvailable(ctx, account); available {
-			return p.DiscoverWithNativeTool(ctx, account)
+			resources, err := p.DiscoverWithNativeTool(ctx, account)
+			if err != nil {
+				return nil, err
+			}
+			return opts.ResourceSelector(p.Name(), serviceAliases).Filter(resources), nil
 		}
 	}
 
@@ -113,14 +190,22 @@ func (p *AWSProvider) ValidateCredentials(ctx context.Context) error {
 	return nil
 }
 
-// IsNativeToolAvailable checks if AWS Config is available
+// IsNativeToolAvailable checks if AWS Config is available in the account
 func (p *AWSProvider) IsNativeToolAvailable(ctx context.Context, account core.Account) (bool, error) {
-	return p.configClient.IsConfigAvailable(ctx)
+	config, err := p.accountConfig(ctx, account)
+	if err != nil {
+		return false, err
+	}
+	return NewAWSConfigClient(config).IsConfigAvailable(ctx)
 }
 
 // DiscoverWithNativeTool uses AWS Config for discovery
 func (p *AWSProvider) DiscoverWithNativeTool(ctx context.Context, account core.Account) ([]core.Resource, error) {
-	return p.configClient.DiscoverWithConfig(ctx, account)
+	config, err := p.accountConfig(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	return NewAWSConfigClient(config).DiscoverWithConfig(ctx, account)
 }
 
 // discoverAccountsViaOrganizations discovers accounts via Organizations API
@@ -136,6 +221,12 @@ func (p *AWSProvider) discoverAccountsViaOrganizations(ctx context.Context, clie
 		}
 
 		for _, account := range page.Accounts {
+			// Suspended and closing accounts cannot be scanned
+			if account.Status != orgtypes.AccountStatusActive {
+				logrus.Infof("Skipping AWS account %s with status %s", aws.ToString(account.Id), account.Status)
+				continue
+			}
+
 			accounts = append(accounts, core.Account{
 				ID:       aws.ToString(account.Id),
 				Provider: "aws",
@@ -161,6 +252,10 @@ func (p *AWSProvider) discoverCurrentAccount(ctx context.Context) (core.Account,
 		return core.Account{}, fmt.Errorf("failed to get caller identity: %w", err)
 	}
 
+	p.callerMu.Lock()
+	p.setCallerAccountID(aws.ToString(identity.Account))
+	p.callerMu.Unlock()
+
 	return core.Account{
 		ID:       aws.ToString(identity.Account),
 		Provider: "aws",
@@ -177,10 +272,16 @@ func (p *AWSProvider) discoverCurrentAccount(ctx context.Context) (core.Account,
 func (p *AWSProvider) discoverViaDirectAPI(ctx context.Context, account core.Account, opts core.DiscoveryOptions) ([]core.Resource, error) {
 	var resources []core.Resource
 
+	// Member accounts are scanned with the credentials of the assumed role
+	config, err := p.accountConfig(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get regions to scan
 	regions := opts.Regions
 	if len(regions) == 0 {
-		regions = p.getAllRegions(ctx)
+		regions = opts.FilterRegions(p.getAllRegions(ctx, account, config))
 	}
 
 	// Discover resources in parallel across regions
@@ -194,7 +295,35 @@ func (p *AWSProvider) discoverViaDirectAPI(ctx context.Context, account core.Acc
 
 	for _, region := range regions {
 		go func(r string) {
-			regionalResources, err := p.discoverRegionalResources(ctx, r, opts)
+			// Regions finished by the run being resumed are already stored
+			if opts.RegionCompleted(account, r) {
+				resultChan <- regionResult{region: r}
+				return
+			}
+
+			opts.ReportProgress(core.DiscoveryProgress{
+				Event:           core.ProgressRegionStarted,
+				CurrentProvider: account.Provider,
+				CurrentAccount:  account.ID,
+				CurrentRegion:   r,
+			})
+
+			regionalResources, err := p.discoverRegionalResources(ctx, config, r, account, opts)
+			if err == nil {
+				if cpErr := opts.CompleteRegion(ctx, account, r, regionalResources); cpErr != nil {
+					logrus.Warnf("Failed to checkpoint region %s: %v", r, cpErr)
+				}
+			}
+
+			opts.ReportProgress(core.DiscoveryProgress{
+				Event:           core.ProgressRegionFinished,
+				CurrentProvider: account.Provider,
+				CurrentAccount:  account.ID,
+				CurrentRegion:   r,
+				ResourceCount:   len(regionalResources),
+				Error:           err,
+			})
+
 			resultChan <- regionResult{
 				region:    r,
 				resources: regionalResources,
@@ -216,142 +345,236 @@ func (p *AWSProvider) discoverViaDirectAPI(ctx context.Context, account core.Acc
 	return resources, nil
 }
 
+// serviceAliases maps CloudFormation service names to the names used in Resource.Service
+var serviceAliases = map[string]string{
+	"elasticloadbalancingv2": "elbv2",
+}
+
 // discoverRegionalResources discovers resources in a specific region
 func (p *AWSProvider) discoverRegionalResources(
 	ctx context.Context,
+	config aws.Config,
 	region string,
+	account core.Account,
 	opts core.DiscoveryOptions,
 ) ([]core.Resource, error) {
 	// Configure regional client
-	regionalConfig := p.config.Copy()
+	regionalConfig := config.Copy()
 	regionalConfig.Region = region
 
-	var resources []core.Resource
+	selector := opts.ResourceSelector(p.Name(), serviceAliases)
+	resources := opts.DiscoverServices(ctx, account, region, selector, p.regionalServices(opts.Mode, regionalConfig))
 
-	// Discover based on mode
-	switch opts.Mode {
+	// Resources belong to the scanned account whichever helper discovered them
+	for i := range resources {
+		resources[i].AccountID = account.ID
+	}
+
+	if opts.Mode == core.DeepMode {
+		p.mapDependencies(ctx, resources)
+	}
+
+	return resources, nil
+}
+
+// regionalServices returns the services to discover for a discovery mode
+func (p *AWSProvider) regionalServices(mode core.DiscoveryMode, config aws.Config) []core.ServiceDiscoverer {
+	switch mode {
 	case core.QuickMode:
 		// Only critical resources
-		resources = append(resources, p.discoverEC2Instances(ctx, regionalConfig, true)...)
-		resources = append(resources, p.discoverPublicS3Buckets(ctx, regionalConfig)...)
-		resources = append(resources, p.discoverRDSInstances(ctx, regionalConfig, true)...)
+		return []core.ServiceDiscoverer{
+			{Service: "ec2", Types: []core.TypeDiscoverer{
+				discoverType(config, func(ctx context.Context, config aws.Config) []core.Resource {
+					return p.discoverEC2Instances(ctx, config, true)
+				}, "instance"),
+			}},
+			{Service: "s3", Types: []core.TypeDiscoverer{
+				discoverType(config, p.discoverPublicS3Buckets, "bucket"),
+			}},
+			{Service: "rds", Types: []core.TypeDiscoverer{
+				discoverType(config, func(ctx context.Context, config aws.Config) []core.Resource {
+					return p.discoverRDSInstances(ctx, config, true)
+				}, "db-instance"),
+			}},
+		}
 
 	case core.StandardMode:
 		// Most resources
-		resources = append(resources, p.discoverEC2Resources(ctx, regionalConfig)...)
-		resources = append(resources, p.discoverS3Resources(ctx, regionalConfig)...)
-		resources = append(resources, p.discoverRDSResources(ctx, regionalConfig)...)
+		return []core.ServiceDiscoverer{
+			p.ec2Service(config),
+			p.s3Service(config),
+			p.rdsService(config),
+		}
 
 	case core.DeepMode:
 		// Everything including dependencies
-		resources = append(resources, p.discoverAllResources(ctx, regionalConfig)...)
-		p.mapDependencies(ctx, resources)
+		return p.allServices(config)
 	}
 
-	return resources, nil
+	return nil
 }
 
-// getAllRegions returns all available AWS regions
-func (p *AWSProvider) getAllRegions(ctx context.Context) []string {
-	// Return common regions for now
-	return []string{
-		"us-east-1", "us-west-2", "eu-west-1", "ap-southeast-1",
-		"us-east-2", "us-west-1", "eu-central-1", "ap-northeast-1",
+// discoverType adapts a discovery helper that logs its own errors to a type discoverer
+func discoverType(
+	config aws.Config,
+	discover func(ctx context.Context, config aws.Config) []core.Resource,
+	resourceTypes ...string,
+) core.TypeDiscoverer {
+	return core.TypeDiscoverer{
+		ResourceTypes: resourceTypes,
+		Discover: func(ctx context.Context) ([]core.Resource, error) {
+			return discover(ctx, config), nil
+		},
 	}
 }
 
-// discoverEC2Resources discovers comprehensive EC2 resources
-func (p *AWSProvider) discoverEC2Resources(ctx context.Context, config aws.Config) []core.Resource {
-	var resources []core.Resource
-
-	// Discover instances
-	resources = append(resources, p.discoverEC2Instances(ctx, config, false)...)
-
-	// Discover security groups
-	resources = append(resources, p.discoverSecurityGroups(ctx, config)...)
-
-	// Discover volumes
-	resources = append(resources, p.discoverVolumes(ctx, config)...)
-
-	// Discover VPCs
-	resources = append(resources, p.discoverVPCs(ctx, config)...)
-
-	// Discover subnets
-	resources = append(resources, p.discoverSubnets(ctx, config)...)
-
-	return resources
+// discoverRegionalType adapts a region-scoped service discovery to a type discoverer
+func discoverRegionalType(
+	config aws.Config,
+	discover func(ctx context.Context, config aws.Config) ([]core.Resource, error),
+	resourceTypes ...string,
+) core.TypeDiscoverer {
+	return core.TypeDiscoverer{
+		ResourceTypes: resourceTypes,
+		Discover: func(ctx context.Context) ([]core.Resource, error) {
+			return discover(ctx, config)
+		},
+	}
 }
 
-// discoverAllResources discovers all resources
-func (p *AWSProvider) discoverAllResources(ctx context.Context, config aws.Config) []core.Resource {
-	var resources []core.Resource
+// ec2Service lists the EC2 resource discoverers
+func (p *AWSProvider) ec2Service(config aws.Config) core.ServiceDiscoverer {
+	return core.ServiceDiscoverer{Service: "ec2", Types: []core.TypeDiscoverer{
+		discoverType(config, func(ctx context.Context, config aws.Config) []core.Resource {
+			return p.discoverEC2Instances(ctx, config, false)
+		}, "instance"),
+		discoverType(config, p.discoverSecurityGroups, "security-group"),
+		discoverType(config, p.discoverVolumes, "volume"),
+		discoverType(config, p.discoverVPCs, "vpc"),
+		discoverType(config, p.discoverSubnets, "subnet"),
+	}}
+}
 
-	// Discover all resource types
-	resources = append(resources, p.discoverEC2Resources(ctx, config)...)
-	resources = append(resources, p.discoverS3Resources(ctx, config)...)
-	resources = append(resources, p.discoverRDSResources(ctx, config)...)
-	resources = append(resources, p.discoverIAMResources(ctx, config)...)
-	resources = append(resources, p.discoverLambdaResources(ctx, config)...)
+// s3Service lists the S3 resource discoverers
+func (p *AWSProvider) s3Service(config aws.Config) core.ServiceDiscoverer {
+	return core.ServiceDiscoverer{Service: "s3", Types: []core.TypeDiscoverer{
+		discoverType(config, p.discoverS3Resources, "bucket"),
+	}}
+}
 
-	// Service-specific discoveries
-	if cfResources, err := p.DiscoverCloudFormationStacks(ctx, config.Region); err == nil {
-		resources = append(resources, cfResources...)
-	}
-	if ecsResources, err := p.DiscoverECSServices(ctx, config.Region); err == nil {
-		resources = append(resources, ecsResources...)
-	}
-	if elasticacheResources, err := p.DiscoverElastiCacheClusters(ctx, config.Region); err == nil {
-		resources = append(resources, elasticacheResources...)
-	}
-	if lbResources, err := p.DiscoverLoadBalancers(ctx, config.Region); err == nil {
-		resources = append(resources, lbResources...)
-	}
-	if snsResources, err := p.DiscoverSNSTopics(ctx, config.Region); err == nil {
-		resources = append(resources, snsResources...)
-	}
-	if sqsResources, err := p.DiscoverSQSQueues(ctx, config.Region); err == nil {
-		resources = append(resources, sqsResources...)
-	}
+// rdsService lists the RDS resource discoverers
+func (p *AWSProvider) rdsService(config aws.Config) core.ServiceDiscoverer {
+	return core.ServiceDiscoverer{Service: "rds", Types: []core.TypeDiscoverer{
+		discoverType(config, func(ctx context.Context, config aws.Config) []core.Resource {
+			return p.discoverRDSInstances(ctx, config, false)
+		}, "db-instance"),
+		discoverType(config, p.discoverRDSClusters, "db-cluster"),
+		discoverType(config, p.discoverRDSSnapshots, "db-snapshot"),
+		discoverType(config, p.discoverRDSParameterGroups, "db-parameter-group"),
+		discoverType(config, p.discoverRDSSubnetGroups, "db-subnet-group"),
+	}}
+}
 
-	// Global services (Route53)
-	if route53Resources, err := p.DiscoverRoute53Zones(ctx, config.Region); err == nil {
-		resources = append(resources, route53Resources...)
+// allServices returns every service discovered in deep mode
+func (p *AWSProvider) allServices(config aws.Config) []core.ServiceDiscoverer {
+	return []core.ServiceDiscoverer{
+		p.ec2Service(config),
+		p.s3Service(config),
+		p.rdsService(config),
+		{Service: "iam", Types: []core.TypeDiscoverer{
+			discoverType(config, p.discoverIAMUsers, "user"),
+			discoverType(config, p.discoverIAMRoles, "role"),
+			discoverType(config, p.discoverIAMGroups, "group"),
+			discoverType(config, p.discoverIAMPolicies, "policy"),
+			discoverType(config, p.discoverIAMAccessKeys, "access-key"),
+		}},
+		{Service: "lambda", Types: []core.TypeDiscoverer{
+			discoverType(config, p.discoverLambdaFunctions, "function"),
+			discoverType(config, p.discoverLambdaLayers, "layer"),
+			discoverType(config, p.discoverLambdaEventSourceMappings, "event-source-mapping"),
+		}},
+
+		// Service-specific discoveries
+		{Service: "cloudformation", Types: []core.TypeDiscoverer{
+			discoverRegionalType(config, p.DiscoverCloudFormationStacks, "stack"),
+		}},
+		{Service: "ecs", Types: []core.TypeDiscoverer{
+			discoverRegionalType(config, p.DiscoverECSServices, "cluster", "service"),
+		}},
+		{Service: "elasticache", Types: []core.TypeDiscoverer{
+			discoverRegionalType(config, p.DiscoverElastiCacheClusters, "replication-group"),
+		}},
+		{Service: "elbv2", Types: []core.TypeDiscoverer{
+			discoverRegionalType(config, p.DiscoverLoadBalancers, "load-balancer"),
+		}},
+		{Service: "sns", Types: []core.TypeDiscoverer{
+			discoverRegionalType(config, p.DiscoverSNSTopics, "topic"),
+		}},
+		{Service: "sqs", Types: []core.TypeDiscoverer{
+			discoverRegionalType(config, p.DiscoverSQSQueues, "queue"),
+		}},
+
+		// Global services (Route53)
+		{Service: "route53", Types: []core.TypeDiscoverer{
+			discoverRegionalType(config, p.DiscoverRoute53Zones, "hosted-zone"),
+		}},
 	}
-
-	return resources
 }
 
 // Helper methods
-func (p *AWSProvider) getAccountIDFromConfig(config aws.Config) string {
-	// This is a placeholder - in a real implementation, you'd get this from STS
-	return "placeholder-account-id"
-}
-
 func (p *AWSProvider) mapDependencies(ctx context.Context, resources []core.Resource) {
 	// TODO: Implement dependency mapping
 }
 
 // memoryCache is a simple in-memory cache implementation
 type memoryCache struct {
-	data map[string]interface{}
+	mu   sync.Mutex
+	data map[string]cacheEntry
+}
+
+// cacheEntry is a cached value with its expiry time
+type cacheEntry struct {
+	value     interface{}
+	expiresAt time.Time
 }
 
 func (c *memoryCache) Get(key string) (interface{}, bool) {
-	value, ok := c.data[key]
-	return value, ok
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.data[key]
+	if !ok {
+		return nil, false
+	}
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		delete(c.data, key)
+		return nil, false
+	}
+	return entry.value, true
 }
 
 func (c *memoryCache) Set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.data == nil {
-		c.data = make(map[string]interface{})
+		c.data = make(map[string]cacheEntry)
+	}
+	entry := cacheEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
 	}
-	c.data[key] = value
+	c.data[key] = entry
 }
 
 func (c *memoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	delete(c.data, key)
 }
 
 func (c *memoryCache) Clear() {
-	c.data = make(map[string]interface{})
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data = make(map[string]cacheEntry)
 }
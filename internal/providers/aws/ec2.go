@@ -42,7 +42,7 @@ func (p *AWSProvider) discoverEC2Instances(ctx context.Context, config aws.Confi
 					Service:         "ec2",
 					Type:            "instance",
 					Name:            p.getInstanceName(instance),
-					ARN:             p.getInstanceARN(instance, config.Region),
+					ARN:             p.getInstanceARN(instance, config),
 					CreatedAt:       aws.ToTime(instance.LaunchTime),
 					UpdatedAt:       time.Now(),
 					DiscoveredAt:    time.Now(),
@@ -262,9 +262,9 @@ func (p *AWSProvider) getInstanceName(instance ec2Types.Instance) string {
 	return aws.ToString(instance.InstanceId)
 }
 
-func (p *AWSProvider) getInstanceARN(instance ec2Types.Instance, region string) string {
-	accountID := p.getAccountIDFromConfig(aws.Config{Region: region})
-	return fmt.Sprintf("arn:aws:ec2:%s:%s:instance/%s", region, accountID, aws.ToString(instance.InstanceId))
+func (p *AWSProvider) getInstanceARN(instance ec2Types.Instance, config aws.Config) string {
+	accountID := p.getAccountIDFromConfig(config)
+	return fmt.Sprintf("arn:aws:ec2:%s:%s:instance/%s", config.Region, accountID, aws.ToString(instance.InstanceId))
 }
 
 func (p *AWSProvider) parseInstanceTags(instance ec2Types.Instance) map[string]string {
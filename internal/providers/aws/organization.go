@@ -0,0 +1,103 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// organizationHierarchy walks an organization from its roots down through
+// its organizational units and returns the nodes above every account, keyed
+// by account ID. Roots are not nodes of their own: top-level OUs and
+// accounts hang directly off the organization.
+func (p *AWSProvider) organizationHierarchy(ctx context.Context, client *organizations.Client, organizationID string) (map[string][]core.OrgNode, error) {
+	organization := core.OrgNode{
+		ID:       organizationID,
+		Provider: "aws",
+		Kind:     core.OrgNodeOrganization,
+		Name:     organizationID,
+	}
+
+	hierarchies := make(map[string][]core.OrgNode)
+
+	var walk func(parentID string, path []core.OrgNode) error
+	walk = func(parentID string, path []core.OrgNode) error {
+		accounts := organizations.NewListAccountsForParentPaginator(client, &organizations.ListAccountsForParentInput{
+			ParentId: aws.String(parentID),
+		})
+		for accounts.HasMorePages() {
+			page, err := accounts.NextPage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list accounts in %s: %w", parentID, err)
+			}
+			for _, account := range page.Accounts {
+				hierarchies[aws.ToString(account.Id)] = path
+			}
+		}
+
+		units := organizations.NewListOrganizationalUnitsForParentPaginator(client, &organizations.ListOrganizationalUnitsForParentInput{
+			ParentId: aws.String(parentID),
+		})
+		for units.HasMorePages() {
+			page, err := units.NextPage(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list organizational units in %s: %w", parentID, err)
+			}
+			for _, unit := range page.OrganizationalUnits {
+				node := core.OrgNode{
+					ID:       aws.ToString(unit.Id),
+					Provider: "aws",
+					Kind:     core.OrgNodeOrganizationalUnit,
+					Name:     aws.ToString(unit.Name),
+					ParentID: path[len(path)-1].ID,
+				}
+
+				// Each OU gets its own copy of the path shared by its siblings
+				unitPath := append(append([]core.OrgNode(nil), path...), node)
+				if err := walk(node.ID, unitPath); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	roots := organizations.NewListRootsPaginator(client, &organizations.ListRootsInput{})
+	for roots.HasMorePages() {
+		page, err := roots.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organization roots: %w", err)
+		}
+		for _, root := range page.Roots {
+			if err := walk(aws.ToString(root.Id), []core.OrgNode{organization}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return hierarchies, nil
+}
+
+// addOrganizationTags records the OU path of an account in its tags
+func addOrganizationTags(tags map[string]string, hierarchy []core.OrgNode) {
+	if len(hierarchy) == 0 {
+		return
+	}
+
+	tags["organization_id"] = hierarchy[0].ID
+	if len(hierarchy) == 1 {
+		return
+	}
+
+	names := make([]string, 0, len(hierarchy)-1)
+	for _, node := range hierarchy[1:] {
+		names = append(names, node.Name)
+	}
+	tags["organizational_unit"] = hierarchy[len(hierarchy)-1].ID
+	tags["ou_path"] = strings.Join(names, "/")
+}
@@ -0,0 +1,95 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/sirupsen/logrus"
+)
+
+// regionCacheTTL is how long the enabled regions of an account are cached
+const regionCacheTTL = time.Hour
+
+// defaultRegions are scanned when the enabled regions cannot be enumerated
+var defaultRegions = []string{
+	"us-east-1", "us-west-2", "eu-west-1", "ap-southeast-1",
+	"us-east-2", "us-west-1", "eu-central-1", "ap-northeast-1",
+}
+
+// Region opt-in statuses reported by DescribeRegions
+const (
+	optInNotRequired = "opt-in-not-required"
+	optInOptedIn     = "opted-in"
+)
+
+// getAllRegions returns the regions enabled for an account
+func (p *AWSProvider) getAllRegions(ctx context.Context, account core.Account, config aws.Config) []string {
+	cacheKey := "regions:" + account.ID
+	if cached, ok := p.cache.Get(cacheKey); ok {
+		if regions, ok := cached.([]string); ok {
+			return regions
+		}
+	}
+
+	regions, err := p.describeRegions(ctx, config)
+	if err != nil {
+		logrus.Warnf("Failed to enumerate regions for account %s, using default regions: %v", account.ID, err)
+		return defaultRegions
+	}
+
+	p.cache.Set(cacheKey, regions, regionCacheTTL)
+	return regions
+}
+
+// describeRegions lists the regions enabled for the account of config with
+// EC2 DescribeRegions
+func (p *AWSProvider) describeRegions(ctx context.Context, config aws.Config) ([]string, error) {
+	config = config.Copy()
+	if config.Region == "" {
+		// DescribeRegions needs an endpoint, any enabled region will do
+		config.Region = "us-east-1"
+	}
+	client := ec2.NewFromConfig(config)
+
+	output, err := client.DescribeRegions(ctx, &ec2.DescribeRegionsInput{
+		AllRegions: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe regions: %w", err)
+	}
+
+	regions := enabledRegions(output.Regions, p.includeOptInRegions)
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("no enabled regions returned")
+	}
+	return regions, nil
+}
+
+// enabledRegions returns the sorted names of the regions to scan. Regions the
+// account has not opted in to are disabled and never returned; opted-in
+// regions are only returned when includeOptIn is set.
+func enabledRegions(regions []ec2Types.Region, includeOptIn bool) []string {
+	var names []string
+	for _, region := range regions {
+		switch aws.ToString(region.OptInStatus) {
+		case optInNotRequired:
+		case optInOptedIn:
+			if !includeOptIn {
+				continue
+			}
+		default:
+			// Not opted in, the region is disabled for the account
+			continue
+		}
+		names = append(names, aws.ToString(region.RegionName))
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,141 @@
+package aws
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnabledRegions(t *testing.T) {
+	region := func(name, status string) ec2Types.Region {
+		return ec2Types.Region{RegionName: aws.String(name), OptInStatus: aws.String(status)}
+	}
+	regions := []ec2Types.Region{
+		region("us-west-2", optInNotRequired),
+		region("af-south-1", optInOptedIn),
+		region("me-central-1", "not-opted-in"),
+		region("us-east-1", optInNotRequired),
+		region("ap-east-1", optInOptedIn),
+		{RegionName: aws.String("eu-west-1")},
+	}
+
+	tests := []struct {
+		name         string
+		regions      []ec2Types.Region
+		includeOptIn bool
+		expected     []string
+	}{
+		{
+			name:     "opt-in regions excluded",
+			regions:  regions,
+			expected: []string{"us-east-1", "us-west-2"},
+		},
+		{
+			name:         "opt-in regions included",
+			regions:      regions,
+			includeOptIn: true,
+			expected:     []string{"af-south-1", "ap-east-1", "us-east-1", "us-west-2"},
+		},
+		{
+			name:         "only disabled regions",
+			regions:      []ec2Types.Region{region("me-central-1", "not-opted-in")},
+			includeOptIn: true,
+		},
+		{name: "no regions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, enabledRegions(tt.regions, tt.includeOptIn))
+		})
+	}
+}
+
+func TestGetAllRegions(t *testing.T) {
+	const regionsXML = `<DescribeRegionsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>req-1</requestId>
+  <regionInfo>
+    <item><regionName>us-east-1</regionName><optInStatus>opt-in-not-required</optInStatus></item>
+    <item><regionName>af-south-1</regionName><optInStatus>opted-in</optInStatus></item>
+    <item><regionName>me-central-1</regionName><optInStatus>not-opted-in</optInStatus></item>
+  </regionInfo>
+</DescribeRegionsResponse>`
+	const disabledXML = `<DescribeRegionsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>req-1</requestId>
+  <regionInfo>
+    <item><regionName>me-central-1</regionName><optInStatus>not-opted-in</optInStatus></item>
+  </regionInfo>
+</DescribeRegionsResponse>`
+	const deniedXML = `<Response><Errors><Error><Code>UnauthorizedOperation</Code>
+<Message>You are not authorized to perform this operation.</Message></Error></Errors>
+<RequestID>req-1</RequestID></Response>`
+
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		includeOptIn bool
+		expected     []string
+		cached       bool
+	}{
+		{name: "enabled regions", status: http.StatusOK, body: regionsXML, expected: []string{"us-east-1"}, cached: true},
+		{
+			name:         "opt-in regions included",
+			status:       http.StatusOK,
+			body:         regionsXML,
+			includeOptIn: true,
+			expected:     []string{"af-south-1", "us-east-1"},
+			cached:       true,
+		},
+		{name: "no enabled regions", status: http.StatusOK, body: disabledXML, expected: defaultRegions},
+		{name: "describe denied", status: http.StatusForbidden, body: deniedXML, expected: defaultRegions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ec2 := &fakeEC2{status: tt.status, body: tt.body}
+			p := &AWSProvider{cache: &memoryCache{}, includeOptInRegions: tt.includeOptIn}
+			config := aws.Config{
+				Credentials: credentials.NewStaticCredentialsProvider("AKIACALLER", "secret", ""),
+				HTTPClient:  ec2,
+				Retryer:     func() aws.Retryer { return aws.NopRetryer{} },
+			}
+			account := core.Account{ID: "123456789012", Provider: "aws"}
+
+			assert.Equal(t, tt.expected, p.getAllRegions(context.Background(), account, config))
+
+			// Only enumerated regions are cached, the default regions are not
+			assert.Equal(t, tt.expected, p.getAllRegions(context.Background(), account, config))
+			if tt.cached {
+				assert.Equal(t, 1, ec2.calls)
+			} else {
+				assert.Equal(t, 2, ec2.calls)
+			}
+		})
+	}
+}
+
+// fakeEC2 answers every EC2 request with the same response
+type fakeEC2 struct {
+	status int
+	body   string
+	calls  int
+}
+
+func (f *fakeEC2) Do(req *http.Request) (*http.Response, error) {
+	f.calls++
+	return &http.Response{
+		StatusCode: f.status,
+		Header:     http.Header{"Content-Type": {"text/xml"}},
+		Body:       io.NopCloser(strings.NewReader(f.body)),
+		Request:    req,
+	}, nil
+}
@@ -26,10 +26,8 @@ import (
 )
 
 // DiscoverCloudFormationStacks discovers CloudFormation stacks
-func (p *AWSProvider) DiscoverCloudFormationStacks(ctx context.Context, region string) ([]core.Resource, error) {
-	client := cloudformation.NewFromConfig(p.config, func(o *cloudformation.Options) {
-		o.Region = region
-	})
+func (p *AWSProvider) DiscoverCloudFormationStacks(ctx context.Context, config aws.Config) ([]core.Resource, error) {
+	client := cloudformation.NewFromConfig(config)
 
 	var resources []core.Resource
 	paginator := cloudformation.NewListStacksPaginator(client, &cloudformation.ListStacksInput{})
@@ -61,7 +59,7 @@ func (p *AWSProvider) DiscoverCloudFormationStacks(ctx context.Context, region s
 			resource := core.Resource{
 				ID:        aws.ToString(stack.StackId),
 				Provider:  "aws",
-				Region:    region,
+				Region:    config.Region,
 				Service:   "cloudformation",
 				Type:      "stack",
 				Name:      aws.ToString(stack.StackName),
@@ -94,10 +92,8 @@ func (p *AWSProvider) DiscoverCloudFormationStacks(ctx context.Context, region s
 }
 
 // DiscoverECSServices discovers ECS services and clusters
-func (p *AWSProvider) DiscoverECSServices(ctx context.Context, region string) ([]core.Resource, error) {
-	client := ecs.NewFromConfig(p.config, func(o *ecs.Options) {
-		o.Region = region
-	})
+func (p *AWSProvider) DiscoverECSServices(ctx context.Context, config aws.Config) ([]core.Resource, error) {
+	client := ecs.NewFromConfig(config)
 
 	var resources []core.Resource
 
@@ -126,7 +122,7 @@ func (p *AWSProvider) DiscoverECSServices(ctx context.Context, region string) ([
 			clusterResource := core.Resource{
 				ID:        aws.ToString(cluster.ClusterArn),
 				Provider:  "aws",
-				Region:    region,
+				Region:    config.Region,
 				Service:   "ecs",
 				Type:      "cluster",
 				Name:      aws.ToString(cluster.ClusterName),
@@ -177,7 +173,7 @@ func (p *AWSProvider) DiscoverECSServices(ctx context.Context, region string) ([
 					serviceResource := core.Resource{
 						ID:        aws.ToString(service.ServiceArn),
 						Provider:  "aws",
-						Region:    region,
+						Region:    config.Region,
 						Service:   "ecs",
 						Type:      "service",
 						Name:      aws.ToString(service.ServiceName),
@@ -223,10 +219,8 @@ func (p *AWSProvider) DiscoverECSServices(ctx context.Context, region string) ([
 }
 
 // DiscoverElastiCacheClusters discovers ElastiCache clusters
-func (p *AWSProvider) DiscoverElastiCacheClusters(ctx context.Context, region string) ([]core.Resource, error) {
-	client := elasticache.NewFromConfig(p.config, func(o *elasticache.Options) {
-		o.Region = region
-	})
+func (p *AWSProvider) DiscoverElastiCacheClusters(ctx context.Context, config aws.Config) ([]core.Resource, error) {
+	client := elasticache.NewFromConfig(config)
 
 	var resources []core.Resource
 
@@ -242,7 +236,7 @@ func (p *AWSProvider) DiscoverElastiCacheClusters(ctx context.Context, region st
 			resource := core.Resource{
 				ID:        aws.ToString(rg.ReplicationGroupId),
 				Provider:  "aws",
-				Region:    region,
+				Region:    config.Region,
 				Service:   "elasticache",
 				Type:      "replication-group",
 				Name:      aws.ToString(rg.ReplicationGroupId),
@@ -278,10 +272,8 @@ func (p *AWSProvider) DiscoverElastiCacheClusters(ctx context.Context, region st
 }
 
 // DiscoverLoadBalancers discovers Application and Network Load Balancers
-func (p *AWSProvider) DiscoverLoadBalancers(ctx context.Context, region string) ([]core.Resource, error) {
-	client := elasticloadbalancingv2.NewFromConfig(p.config, func(o *elasticloadbalancingv2.Options) {
-		o.Region = region
-	})
+func (p *AWSProvider) DiscoverLoadBalancers(ctx context.Context, config aws.Config) ([]core.Resource, error) {
+	client := elasticloadbalancingv2.NewFromConfig(config)
 
 	var resources []core.Resource
 
@@ -318,7 +310,7 @@ func (p *AWSProvider) DiscoverLoadBalancers(ctx context.Context, region string)
 			resource := core.Resource{
 				ID:        aws.ToString(lb.LoadBalancerArn),
 				Provider:  "aws",
-				Region:    region,
+				Region:    config.Region,
 				Service:   "elbv2",
 				Type:      strings.ToLower(string(lb.Type)),
 				Name:      aws.ToString(lb.LoadBalancerName),
@@ -352,10 +344,8 @@ func (p *AWSProvider) DiscoverLoadBalancers(ctx context.Context, region string)
 }
 
 // DiscoverRoute53Zones discovers Route 53 hosted zones
-func (p *AWSProvider) DiscoverRoute53Zones(ctx context.Context, region string) ([]core.Resource, error) {
-	client := route53.NewFromConfig(p.config, func(o *route53.Options) {
-		o.Region = region
-	})
+func (p *AWSProvider) DiscoverRoute53Zones(ctx context.Context, config aws.Config) ([]core.Resource, error) {
+	client := route53.NewFromConfig(config)
 
 	var resources []core.Resource
 
@@ -404,10 +394,8 @@ func (p *AWSProvider) DiscoverRoute53Zones(ctx context.Context, region string) (
 }
 
 // DiscoverSNSTopics discovers SNS topics
-func (p *AWSProvider) DiscoverSNSTopics(ctx context.Context, region string) ([]core.Resource, error) {
-	client := sns.NewFromConfig(p.config, func(o *sns.Options) {
-		o.Region = region
-	})
+func (p *AWSProvider) DiscoverSNSTopics(ctx context.Context, config aws.Config) ([]core.Resource, error) {
+	client := sns.NewFromConfig(config)
 
 	var resources []core.Resource
 
@@ -438,7 +426,7 @@ func (p *AWSProvider) DiscoverSNSTopics(ctx context.Context, region string) ([]c
 			resource := core.Resource{
 				ID:        aws.ToString(topic.TopicArn),
 				Provider:  "aws",
-				Region:    region,
+				Region:    config.Region,
 				Service:   "sns",
 				Type:      "topic",
 				Name:      aws.ToString(topic.TopicArn),
@@ -461,10 +449,8 @@ func (p *AWSProvider) DiscoverSNSTopics(ctx context.Context, region string) ([]c
 }
 
 // DiscoverSQSQueues discovers SQS queues
-func (p *AWSProvider) DiscoverSQSQueues(ctx context.Context, region string) ([]core.Resource, error) {
-	client := sqs.NewFromConfig(p.config, func(o *sqs.Options) {
-		o.Region = region
-	})
+func (p *AWSProvider) DiscoverSQSQueues(ctx context.Context, config aws.Config) ([]core.Resource, error) {
+	client := sqs.NewFromConfig(config)
 
 	var resources []core.Resource
 
@@ -499,7 +485,7 @@ func (p *AWSProvider) DiscoverSQSQueues(ctx context.Context, region string) ([]c
 		resource := core.Resource{
 			ID:        queueUrl,
 			Provider:  "aws",
-			Region:    region,
+			Region:    config.Region,
 			Service:   "sqs",
 			Type:      "queue",
 			Name:      queueName,
@@ -3,26 +3,85 @@ package azure
 import (
 	"context"
 	"fmt"
-	"time"
+	"sort"
+	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/sirupsen/logrus"
 )
 
 type AzureProvider struct {
 	resourceGraphClient *AzureResourceGraphClient
+	armClient           *armClient
+	subscriptions       []string // Optional allow-list of subscription IDs or names
+}
+
+// defaultRegions are scanned when the locations of a subscription cannot be listed
+var defaultRegions = []string{
+	"eastus", "eastus2", "westus2", "centralus",
+	"westeurope", "northeurope", "uksouth", "southeastasia",
+}
+
+func init() {
+	core.RegisterProvider(core.ProviderRegistration{
+		Name:          "azure",
+		Description:   "Microsoft Azure",
+		ConfigSection: "azure",
+		Factory: func(config *core.Config) (core.CloudProvider, error) {
+			provider, err := NewProvider(config.Azure)
+			if err != nil {
+				return nil, err
+			}
+			return provider, nil
+		},
+		Capabilities: core.ProviderCapabilities{
+			NativeTool:   "Azure Resource Graph",
+			Regions:      defaultRegions,
+			AccountTypes: []string{"subscription"},
+		},
+	})
 }
 
 // NewProvider creates a new Azure provider
 func NewProvider(cfg core.AzureConfig) (*AzureProvider, error) {
+	return NewProviderWithOptions(cfg, ClientOptions{})
+}
+
+// NewProviderWithOptions creates an Azure provider whose SDK clients use a
+// custom credential, Resource Manager endpoint or HTTP transport
+func NewProviderWithOptions(cfg core.AzureConfig, options ClientOptions) (*AzureProvider, error) {
+	if options.Endpoint == "" {
+		options.Endpoint = cfg.Endpoint
+	}
+
+	cred := options.Credential
+	if cred == nil {
+		// Use default Azure credential chain
+		defaultCred, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Azure credentials: %w", err)
+		}
+		cred = defaultCred
+	}
+	clientOptions := options.armClientOptions()
+
+	client, err := newARMClient(cred, clientOptions)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize Resource Graph client
-	resourceGraphClient, err := NewAzureResourceGraphClient()
+	resourceGraphClient, err := NewAzureResourceGraphClient(cred, clientOptions)
 	if err != nil {
 		// Log error but don't fail - we can fall back to direct API
-		fmt.Printf("Warning: Failed to initialize Resource Graph client: %v\n", err)
+		logrus.Warnf("Failed to initialize Resource Graph client: %v", err)
 	}
 
 	return &AzureProvider{
 		resourceGraphClient: resourceGraphClient,
+		armClient:           client,
+		subscriptions:       cfg.Subscriptions,
 	}, nil
 }
 
@@ -31,22 +90,68 @@ func (p *AzureProvider) Name() string {
 	return "azure"
 }
 
-// DiscoverAccounts discovers all Azure subscriptions
+// DiscoverAccounts discovers the Azure subscriptions the credential can access.
+// Subscriptions configured in azure.subscriptions act as an allow-list.
 func (p *AzureProvider) DiscoverAccounts(ctx context.Context) ([]core.Account, error) {
+	subscriptions, err := p.armClient.listSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	allowed := make(map[string]bool, len(p.subscriptions))
+	for _, subscription := range p.subscriptions {
+		allowed[strings.ToLower(strings.TrimSpace(subscription))] = true
+	}
+
 	var accounts []core.Account
+	hierarchies := make(map[string]map[string]managementGroupPath) // Keyed by tenant ID
 
-	// For now, return a placeholder account
-	account := core.Account{
-		ID:       "placeholder-subscription-id",
-		Provider: "azure",
-		Name:     "Placeholder Subscription",
-		Type:     "subscription",
-		Tags: map[string]string{
-			"tenant_id": "placeholder-tenant-id",
-		},
+	for _, subscription := range subscriptions {
+		if len(allowed) > 0 &&
+			!allowed[strings.ToLower(subscription.SubscriptionID)] &&
+			!allowed[strings.ToLower(subscription.DisplayName)] {
+			continue
+		}
+
+		// Disabled and deleted subscriptions cannot be scanned
+		if subscription.State == "Disabled" || subscription.State == "Deleted" {
+			logrus.Infof("Skipping Azure subscription %s in state %s", subscription.SubscriptionID, subscription.State)
+			continue
+		}
+
+		account := core.Account{
+			ID:       subscription.SubscriptionID,
+			Provider: "azure",
+			Name:     subscription.DisplayName,
+			Type:     "subscription",
+			Tags: map[string]string{
+				"tenant_id": subscription.TenantID,
+				"state":     subscription.State,
+			},
+		}
+
+		if subscription.TenantID != "" {
+			paths, ok := hierarchies[subscription.TenantID]
+			if !ok {
+				paths = p.managementGroupPaths(ctx, subscription.TenantID)
+				hierarchies[subscription.TenantID] = paths
+			}
+			if path, ok := paths[strings.ToLower(subscription.SubscriptionID)]; ok {
+				path.addTags(account.Tags)
+				account.Hierarchy = path.orgNodes()
+			}
+		}
+
+		accounts = append(accounts, account)
 	}
 
-	accounts = append(accounts, account)
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("no Azure subscriptions discovered")
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].ID < accounts[j].ID
+	})
 	return accounts, nil
 }
 
@@ -62,7 +167,9 @@ func (p *AzureProvider) DiscoverResources(
 
 // ValidateCredentials checks if credentials are valid
 func (p *AzureProvider) ValidateCredentials(ctx context.Context) error {
-	// For now, always return success
+	if _, err := p.armClient.listSubscriptions(ctx); err != nil {
+		return fmt.Errorf("invalid Azure credentials: %w", err)
+	}
 	return nil
 }
 
@@ -82,30 +189,127 @@ func (p *AzureProvider) DiscoverWithNativeTool(ctx context.Context, account core
 	return p.resourceGraphClient.DiscoverWithResourceGraph(ctx, account)
 }
 
-// discoverViaDirectAPI falls back to direct API calls
+// discoverViaDirectAPI lists resources through Azure Resource Manager.
+// Each resource type is listed once for the whole subscription and the
+// results are split by region, so regions are checkpointed and covered
+// the same way as providers whose APIs are regional.
 func (p *AzureProvider) discoverViaDirectAPI(ctx context.Context, account core.Account, opts core.DiscoveryOptions) ([]core.Resource, error) {
 	var resources []core.Resource
 
-	// For now, return a simple placeholder resource
-	resource := core.Resource{
-		ID:              "placeholder-resource",
-		Provider:        "azure",
-		AccountID:       account.ID,
-		Region:          "eastus",
-		Service:         "compute",
-		Type:            "virtual-machine",
-		Name:            "Placeholder VM",
-		ARN:             fmt.Sprintf("/subscriptions/%s/resourceGroups/rg-placeholder/providers/Microsoft.Compute/virtualMachines/placeholder-vm", account.ID),
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
-		DiscoveredAt:    time.Now(),
-		DiscoveryMethod: "direct_api",
-		Tags: map[string]string{
-			"Environment": "dev",
-			"Project":     "cloudrecon",
-		},
+	// Get regions to scan
+	regions := opts.Regions
+	if len(regions) == 0 {
+		regions = opts.FilterRegions(p.getAllRegions(ctx, account))
+	}
+
+	listings := newSubscriptionListings(p.armClient, account.ID)
+	selector := opts.ResourceSelector(p.Name(), nil)
+
+	for _, region := range regions {
+		// Regions finished by the run being resumed are already stored
+		if opts.RegionCompleted(account, region) {
+			continue
+		}
+
+		opts.ReportProgress(core.DiscoveryProgress{
+			Event:           core.ProgressRegionStarted,
+			CurrentProvider: account.Provider,
+			CurrentAccount:  account.ID,
+			CurrentRegion:   region,
+		})
+
+		regionalResources := opts.DiscoverServices(ctx, account, region, selector,
+			p.regionalServices(opts.Mode, listings, account, region))
+		if err := opts.CompleteRegion(ctx, account, region, regionalResources); err != nil {
+			logrus.Warnf("Failed to checkpoint region %s: %v", region, err)
+		}
+
+		opts.ReportProgress(core.DiscoveryProgress{
+			Event:           core.ProgressRegionFinished,
+			CurrentProvider: account.Provider,
+			CurrentAccount:  account.ID,
+			CurrentRegion:   region,
+			ResourceCount:   len(regionalResources),
+		})
+
+		resources = append(resources, regionalResources...)
 	}
 
-	resources = append(resources, resource)
 	return resources, nil
 }
+
+// getAllRegions returns the physical locations available to a subscription
+func (p *AzureProvider) getAllRegions(ctx context.Context, account core.Account) []string {
+	locations, err := p.armClient.listLocations(ctx, account.ID)
+	if err != nil || len(locations) == 0 {
+		logrus.Warnf("Failed to list locations for subscription %s, using default regions: %v", account.ID, err)
+		return defaultRegions
+	}
+	return locations
+}
+
+// regionalServices returns the services to discover for a discovery mode
+func (p *AzureProvider) regionalServices(
+	mode core.DiscoveryMode,
+	listings *subscriptionListings,
+	account core.Account,
+	region string,
+) []core.ServiceDiscoverer {
+	discover := func(t armType) core.TypeDiscoverer {
+		return core.TypeDiscoverer{
+			ResourceTypes: []string{t.resourceType},
+			Discover: func(ctx context.Context) ([]core.Resource, error) {
+				items, err := listings.get(ctx, t, region)
+				if err != nil {
+					return nil, err
+				}
+				resources := make([]core.Resource, 0, len(items))
+				for _, item := range items {
+					resources = append(resources, toResource(account, t, item))
+				}
+				return resources, nil
+			},
+		}
+	}
+
+	// Subnets are embedded in their virtual network
+	discoverSubnets := core.TypeDiscoverer{
+		ResourceTypes: []string{subnetsType},
+		Discover: func(ctx context.Context) ([]core.Resource, error) {
+			vnets, err := listings.get(ctx, virtualNetworksType, region)
+			if err != nil {
+				return nil, err
+			}
+			var resources []core.Resource
+			for _, vnet := range vnets {
+				resources = append(resources, subnetResources(account, vnet)...)
+			}
+			return resources, nil
+		},
+	}
+
+	if mode == core.QuickMode {
+		// Only critical resources
+		return []core.ServiceDiscoverer{
+			{Service: "compute", Types: []core.TypeDiscoverer{discover(virtualMachinesType)}},
+			{Service: "storage", Types: []core.TypeDiscoverer{discover(storageAccountsType)}},
+			{Service: "sql", Types: []core.TypeDiscoverer{discover(sqlServersType)}},
+		}
+	}
+
+	return []core.ServiceDiscoverer{
+		{Service: "compute", Types: []core.TypeDiscoverer{
+			discover(virtualMachinesType),
+			discover(disksType),
+		}},
+		{Service: "network", Types: []core.TypeDiscoverer{
+			discover(virtualNetworksType),
+			discoverSubnets,
+			discover(securityGroupsType),
+		}},
+		{Service: "storage", Types: []core.TypeDiscoverer{discover(storageAccountsType)}},
+		{Service: "sql", Types: []core.TypeDiscoverer{discover(sqlServersType)}},
+		{Service: "web", Types: []core.TypeDiscoverer{discover(webSitesType)}},
+		{Service: "keyvault", Types: []core.TypeDiscoverer{discover(keyVaultsType)}},
+	}
+}
@@ -0,0 +1,117 @@
+package azure
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/sirupsen/logrus"
+)
+
+// Descendant types returned by the management group descendants API
+const (
+	managementGroupType = "Microsoft.Management/managementGroups"
+	subscriptionType    = "/subscriptions"
+)
+
+// managementGroupPath is the chain of management groups above a subscription,
+// ordered from the tenant root group down to the subscription's parent
+type managementGroupPath struct {
+	ids   []string
+	names []string
+}
+
+// addTags records the path in account tags
+func (p managementGroupPath) addTags(tags map[string]string) {
+	if len(p.ids) == 0 {
+		return
+	}
+	tags["management_group"] = p.ids[len(p.ids)-1]
+	tags["management_group_ids"] = strings.Join(p.ids, "/")
+	tags["management_group_path"] = strings.Join(p.names, "/")
+}
+
+// orgNodes returns the management groups of the path as hierarchy nodes
+func (p managementGroupPath) orgNodes() []core.OrgNode {
+	nodes := make([]core.OrgNode, 0, len(p.ids))
+	for i, id := range p.ids {
+		node := core.OrgNode{
+			ID:       id,
+			Provider: "azure",
+			Kind:     core.OrgNodeManagementGroup,
+			Name:     p.names[i],
+		}
+		if i > 0 {
+			node.ParentID = p.ids[i-1]
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// managementGroupPaths walks the management group hierarchy of a tenant and
+// returns the path of every subscription in it, keyed by lowercase
+// subscription ID. The tenant root group is named after the tenant ID.
+// Credentials without management group access get no paths.
+func (p *AzureProvider) managementGroupPaths(ctx context.Context, tenantID string) map[string]managementGroupPath {
+	paths := make(map[string]managementGroupPath)
+
+	descendants, err := p.armClient.listDescendants(ctx, tenantID)
+	if err != nil {
+		logrus.Warnf("Failed to read management groups of tenant %s: %v", tenantID, err)
+		return paths
+	}
+
+	type group struct {
+		displayName string
+		parent      string
+	}
+	groups := make(map[string]group)
+	for _, descendant := range descendants {
+		if strings.EqualFold(descendant.Type, managementGroupType) {
+			groups[strings.ToLower(descendant.Name)] = group{
+				displayName: descendant.Properties.DisplayName,
+				parent:      resourceName(descendant.Properties.Parent.ID),
+			}
+		}
+	}
+
+	for _, descendant := range descendants {
+		if !strings.EqualFold(descendant.Type, subscriptionType) {
+			continue
+		}
+
+		var path managementGroupPath
+		seen := make(map[string]bool)
+		for name := resourceName(descendant.Properties.Parent.ID); name != "" && !seen[strings.ToLower(name)]; {
+			seen[strings.ToLower(name)] = true
+
+			g, ok := groups[strings.ToLower(name)]
+			displayName := g.displayName
+			if displayName == "" {
+				displayName = name
+			}
+			path.ids = append([]string{name}, path.ids...)
+			path.names = append([]string{displayName}, path.names...)
+
+			if !ok {
+				// The tenant root group is not one of its own descendants
+				break
+			}
+			name = g.parent
+		}
+
+		paths[strings.ToLower(descendant.Name)] = path
+	}
+
+	return paths
+}
+
+// resourceName returns the last segment of a resource ID
+func resourceName(id string) string {
+	id = strings.TrimSuffix(id, "/")
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		return id[i+1:]
+	}
+	return id
+}
@@ -6,7 +6,8 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
 	"github.com/cloudrecon/cloudrecon/internal/core"
 	"github.com/sirupsen/logrus"
@@ -18,14 +19,8 @@ type AzureResourceGraphClient struct {
 }
 
 // NewAzureResourceGraphClient creates a new Azure Resource Graph client
-func NewAzureResourceGraphClient() (*AzureResourceGraphClient, error) {
-	// Use default Azure credential chain
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Azure credentials: %w", err)
-	}
-
-	client, err := armresourcegraph.NewClient(cred, nil)
+func NewAzureResourceGraphClient(cred azcore.TokenCredential, options *arm.ClientOptions) (*AzureResourceGraphClient, error) {
+	client, err := armresourcegraph.NewClient(cred, options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Resource Graph client: %w", err)
 	}
@@ -225,7 +220,7 @@ func (c *AzureResourceGraphClient) parseResourceGraphResult(item interface{}, ac
 	}
 
 	// Enrich with security and compliance information
-	c.enrichResourceSecurity(&resource, properties)
+	enrichResourceSecurity(&resource, properties)
 
 	return resource, nil
 }
@@ -246,19 +241,19 @@ func (c *AzureResourceGraphClient) extractServiceAndType(resourceType string) (s
 }
 
 // enrichResourceSecurity enriches resource with security and compliance information
-func (c *AzureResourceGraphClient) enrichResourceSecurity(resource *core.Resource, properties map[string]interface{}) {
+func enrichResourceSecurity(resource *core.Resource, properties map[string]interface{}) {
 	// Check for public access
-	resource.PublicAccess = c.isResourcePublic(resource.Service, resource.Type, properties)
+	resource.PublicAccess = isResourcePublic(resource.Service, resource.Type, properties)
 
 	// Check for encryption
-	resource.Encrypted = c.isResourceEncrypted(resource.Service, resource.Type, properties)
+	resource.Encrypted = isResourceEncrypted(resource.Service, resource.Type, properties)
 
 	// Add compliance flags
-	resource.Compliance = c.getComplianceFlags(resource.Service, resource.Type, properties)
+	resource.Compliance = getComplianceFlags(resource.Service, resource.Type, properties)
 }
 
 // isResourcePublic checks if a resource has public access
-func (c *AzureResourceGraphClient) isResourcePublic(service, resourceType string, properties map[string]interface{}) bool {
+func isResourcePublic(service, resourceType string, properties map[string]interface{}) bool {
 	switch service {
 	case "storage":
 		if resourceType == "storageaccounts" {
@@ -272,6 +267,9 @@ func (c *AzureResourceGraphClient) isResourcePublic(service, resourceType string
 	case "web":
 		if resourceType == "sites" {
 			// Check if app service has public access
+			if httpsOnly, ok := properties["httpsOnly"].(bool); ok {
+				return !httpsOnly
+			}
 			if siteConfig, ok := properties["siteConfig"].(map[string]interface{}); ok {
 				if httpsOnly, ok := siteConfig["httpsOnly"].(bool); ok {
 					return !httpsOnly
@@ -283,12 +281,55 @@ func (c *AzureResourceGraphClient) isResourcePublic(service, resourceType string
 			// Public IP addresses are inherently public
 			return true
 		}
+		if resourceType == "networksecuritygroups" {
+			return allowsInternetInbound(properties)
+		}
+	case "sql":
+		if resourceType == "servers" {
+			if access, ok := properties["publicNetworkAccess"].(string); ok {
+				return access == "Enabled"
+			}
+		}
+	case "keyvault":
+		if resourceType == "vaults" {
+			if access, ok := properties["publicNetworkAccess"].(string); ok && access == "Disabled" {
+				return false
+			}
+			if networkRules, ok := properties["networkAcls"].(map[string]interface{}); ok {
+				if defaultAction, ok := networkRules["defaultAction"].(string); ok {
+					return defaultAction == "Allow"
+				}
+			}
+		}
+	}
+	return false
+}
+
+// allowsInternetInbound checks if a network security group allows inbound traffic from anywhere
+func allowsInternetInbound(properties map[string]interface{}) bool {
+	rules, _ := properties["securityRules"].([]interface{})
+	for _, item := range rules {
+		rule, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ruleProperties, ok := rule["properties"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if ruleProperties["direction"] != "Inbound" || ruleProperties["access"] != "Allow" {
+			continue
+		}
+		switch ruleProperties["sourceAddressPrefix"] {
+		case "*", "Internet", "0.0.0.0/0":
+			return true
+		}
 	}
 	return false
 }
 
 // isResourceEncrypted checks if a resource is encrypted
-func (c *AzureResourceGraphClient) isResourceEncrypted(service, resourceType string, properties map[string]interface{}) bool {
+func isResourceEncrypted(service, resourceType string, properties map[string]interface{}) bool {
 	switch service {
 	case "storage":
 		if resourceType == "storageaccounts" {
@@ -303,7 +344,21 @@ func (c *AzureResourceGraphClient) isResourceEncrypted(service, resourceType str
 			}
 		}
 	case "compute":
+		if resourceType == "virtualmachines" {
+			// Managed disks are encrypted at rest by the platform
+			if storageProfile, ok := properties["storageProfile"].(map[string]interface{}); ok {
+				if osDisk, ok := storageProfile["osDisk"].(map[string]interface{}); ok {
+					_, managed := osDisk["managedDisk"].(map[string]interface{})
+					return managed
+				}
+			}
+		}
 		if resourceType == "disks" {
+			if encryption, ok := properties["encryption"].(map[string]interface{}); ok {
+				if encryptionType, ok := encryption["type"].(string); ok && encryptionType != "" {
+					return true
+				}
+			}
 			if encryptionSettings, ok := properties["encryptionSettings"].(map[string]interface{}); ok {
 				if enabled, ok := encryptionSettings["enabled"].(bool); ok {
 					return enabled
@@ -321,19 +376,19 @@ func (c *AzureResourceGraphClient) isResourceEncrypted(service, resourceType str
 }
 
 // getComplianceFlags returns compliance flags for the resource
-func (c *AzureResourceGraphClient) getComplianceFlags(service, resourceType string, properties map[string]interface{}) []string {
+func getComplianceFlags(service, resourceType string, properties map[string]interface{}) []string {
 	var flags []string
 
 	// Check for required tags (this would be based on your organization's policy)
 	// For now, we'll add some basic compliance checks
 
 	// Check for public access
-	if c.isResourcePublic(service, resourceType, properties) {
+	if isResourcePublic(service, resourceType, properties) {
 		flags = append(flags, "public-access")
 	}
 
 	// Check for encryption
-	if !c.isResourceEncrypted(service, resourceType, properties) {
+	if !isResourceEncrypted(service, resourceType, properties) {
 		flags = append(flags, "unencrypted")
 	}
 
@@ -0,0 +1,251 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+const (
+	armModuleName    = "cloudrecon/azure"
+	armModuleVersion = "v1.0.0"
+
+	// locationsAPIVersion is the Resource Manager API version used to list subscription locations
+	locationsAPIVersion = "2022-12-01"
+)
+
+// ClientOptions configures the Azure SDK clients used by the provider
+type ClientOptions struct {
+	Credential azcore.TokenCredential // Defaults to the default Azure credential chain
+	Endpoint   string                 // Resource Manager endpoint, defaults to the public cloud
+	Transport  policy.Transporter     // HTTP transport, e.g. the client of a local fake ARM API
+}
+
+// armClientOptions returns the SDK options for the configured endpoint and transport
+func (o ClientOptions) armClientOptions() *arm.ClientOptions {
+	options := &arm.ClientOptions{
+		// Discovery is read-only and must never register resource providers
+		DisableRPRegistration: true,
+	}
+	options.Transport = o.Transport
+
+	if o.Endpoint != "" {
+		endpoint := strings.TrimSuffix(o.Endpoint, "/")
+		options.Cloud = cloud.Configuration{
+			ActiveDirectoryAuthorityHost: cloud.AzurePublic.ActiveDirectoryAuthorityHost,
+			Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+				cloud.ResourceManager: {
+					Audience: endpoint,
+					Endpoint: endpoint,
+				},
+			},
+		}
+		// Local fakes of the API are usually served over plain HTTP
+		options.InsecureAllowCredentialWithHTTP = strings.HasPrefix(endpoint, "http://")
+	}
+
+	return options
+}
+
+// armClient lists Azure Resource Manager resources over the SDK pipeline
+type armClient struct {
+	endpoint string
+	pipeline runtime.Pipeline
+}
+
+// newARMClient creates a Resource Manager client
+func newARMClient(cred azcore.TokenCredential, options *arm.ClientOptions) (*armClient, error) {
+	client, err := arm.NewClient(armModuleName, armModuleVersion, cred, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Resource Manager client: %w", err)
+	}
+
+	return &armClient{
+		endpoint: client.Endpoint(),
+		pipeline: client.Pipeline(),
+	}, nil
+}
+
+// armResource is the common envelope of Resource Manager resources
+type armResource struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	Location   string            `json:"location,omitempty"`
+	Kind       string            `json:"kind,omitempty"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	SKU        json.RawMessage   `json:"sku,omitempty"`
+	Properties json.RawMessage   `json:"properties,omitempty"`
+	SystemData *armSystemData    `json:"systemData,omitempty"`
+}
+
+// armSystemData holds the creation and modification times of a resource
+type armSystemData struct {
+	CreatedAt      time.Time `json:"createdAt"`
+	LastModifiedAt time.Time `json:"lastModifiedAt"`
+}
+
+// armPage is a page of a Resource Manager list operation
+type armPage struct {
+	Value    []json.RawMessage `json:"value"`
+	NextLink string            `json:"nextLink"`
+}
+
+// list returns every item of a list operation, following nextLink pages
+func (c *armClient) list(ctx context.Context, path, apiVersion string) ([]json.RawMessage, error) {
+	next := runtime.JoinPaths(c.endpoint, path) + "?api-version=" + url.QueryEscape(apiVersion)
+
+	var items []json.RawMessage
+	for next != "" {
+		req, err := runtime.NewRequest(ctx, http.MethodGet, next)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.pipeline.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", path, err)
+		}
+		if !runtime.HasStatusCode(resp, http.StatusOK) {
+			return nil, fmt.Errorf("failed to list %s: %w", path, runtime.NewResponseError(resp))
+		}
+
+		var page armPage
+		if err := runtime.UnmarshalAsJSON(resp, &page); err != nil {
+			return nil, fmt.Errorf("failed to decode %s page: %w", path, err)
+		}
+
+		items = append(items, page.Value...)
+		next = page.NextLink
+	}
+
+	return items, nil
+}
+
+// listResources lists the resources of a type in a subscription
+func (c *armClient) listResources(ctx context.Context, subscriptionID, resourceType, apiVersion string) ([]armResource, error) {
+	path := fmt.Sprintf("/subscriptions/%s/providers/%s", url.PathEscape(subscriptionID), resourceType)
+
+	items, err := c.list(ctx, path, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]armResource, 0, len(items))
+	for _, item := range items {
+		var resource armResource
+		if err := json.Unmarshal(item, &resource); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", resourceType, err)
+		}
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
+
+// listLocations lists the physical regions available to a subscription
+func (c *armClient) listLocations(ctx context.Context, subscriptionID string) ([]string, error) {
+	path := fmt.Sprintf("/subscriptions/%s/locations", url.PathEscape(subscriptionID))
+
+	items, err := c.list(ctx, path, locationsAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var locations []string
+	for _, item := range items {
+		var location struct {
+			Name     string `json:"name"`
+			Metadata struct {
+				RegionType string `json:"regionType"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(item, &location); err != nil {
+			return nil, fmt.Errorf("failed to decode location: %w", err)
+		}
+		// Logical locations such as geographies hold no resources
+		if location.Metadata.RegionType != "" && location.Metadata.RegionType != "Physical" {
+			continue
+		}
+		locations = append(locations, location.Name)
+	}
+
+	return locations, nil
+}
+
+// API versions used to enumerate subscriptions and management groups
+const (
+	subscriptionsAPIVersion    = "2022-12-01"
+	managementGroupsAPIVersion = "2020-05-01"
+)
+
+// armSubscription is a subscription visible to the credential
+type armSubscription struct {
+	SubscriptionID string `json:"subscriptionId"`
+	DisplayName    string `json:"displayName"`
+	State          string `json:"state"`
+	TenantID       string `json:"tenantId"`
+}
+
+// listSubscriptions lists the subscriptions the credential can access
+func (c *armClient) listSubscriptions(ctx context.Context) ([]armSubscription, error) {
+	items, err := c.list(ctx, "/subscriptions", subscriptionsAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions := make([]armSubscription, 0, len(items))
+	for _, item := range items {
+		var subscription armSubscription
+		if err := json.Unmarshal(item, &subscription); err != nil {
+			return nil, fmt.Errorf("failed to decode subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, nil
+}
+
+// armDescendant is a management group or subscription below a management group
+type armDescendant struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Properties struct {
+		DisplayName string `json:"displayName"`
+		Parent      struct {
+			ID string `json:"id"`
+		} `json:"parent"`
+	} `json:"properties"`
+}
+
+// listDescendants lists every management group and subscription below a management group
+func (c *armClient) listDescendants(ctx context.Context, groupID string) ([]armDescendant, error) {
+	path := fmt.Sprintf("/providers/Microsoft.Management/managementGroups/%s/descendants", url.PathEscape(groupID))
+
+	items, err := c.list(ctx, path, managementGroupsAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	descendants := make([]armDescendant, 0, len(items))
+	for _, item := range items {
+		var descendant armDescendant
+		if err := json.Unmarshal(item, &descendant); err != nil {
+			return nil, fmt.Errorf("failed to decode management group descendant: %w", err)
+		}
+		descendants = append(descendants, descendant)
+	}
+
+	return descendants, nil
+}
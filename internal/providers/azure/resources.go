@@ -0,0 +1,280 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// armType is a resource type listed per subscription through Resource Manager.
+// Service and type names match the ones produced by Resource Graph discovery.
+type armType struct {
+	service      string
+	resourceType string
+	provider     string // Resource provider namespace and type
+	apiVersion   string
+}
+
+var (
+	virtualMachinesType = armType{"compute", "virtualmachines", "Microsoft.Compute/virtualMachines", "2024-03-01"}
+	disksType           = armType{"compute", "disks", "Microsoft.Compute/disks", "2023-04-02"}
+	virtualNetworksType = armType{"network", "virtualnetworks", "Microsoft.Network/virtualNetworks", "2023-09-01"}
+	securityGroupsType  = armType{"network", "networksecuritygroups", "Microsoft.Network/networkSecurityGroups", "2023-09-01"}
+	storageAccountsType = armType{"storage", "storageaccounts", "Microsoft.Storage/storageAccounts", "2023-01-01"}
+	sqlServersType      = armType{"sql", "servers", "Microsoft.Sql/servers", "2021-11-01"}
+	webSitesType        = armType{"web", "sites", "Microsoft.Web/sites", "2022-09-01"}
+	keyVaultsType       = armType{"keyvault", "vaults", "Microsoft.KeyVault/vaults", "2023-07-01"}
+)
+
+// subnetsType is read from the subnets embedded in virtual networks
+const subnetsType = "subnets"
+
+// subscriptionListings lists each resource type of a subscription once and
+// shares the result between the regions being discovered
+type subscriptionListings struct {
+	client         *armClient
+	subscriptionID string
+
+	mu       sync.Mutex
+	listings map[string]*armListing
+}
+
+// armListing is the result of listing one resource type
+type armListing struct {
+	once      sync.Once
+	resources []armResource
+	err       error
+}
+
+// newSubscriptionListings creates the listings of a subscription
+func newSubscriptionListings(client *armClient, subscriptionID string) *subscriptionListings {
+	return &subscriptionListings{
+		client:         client,
+		subscriptionID: subscriptionID,
+		listings:       make(map[string]*armListing),
+	}
+}
+
+// get returns the resources of a type in a region
+func (l *subscriptionListings) get(ctx context.Context, t armType, region string) ([]armResource, error) {
+	l.mu.Lock()
+	listing, ok := l.listings[t.provider]
+	if !ok {
+		listing = &armListing{}
+		l.listings[t.provider] = listing
+	}
+	l.mu.Unlock()
+
+	listing.once.Do(func() {
+		listing.resources, listing.err = l.client.listResources(ctx, l.subscriptionID, t.provider, t.apiVersion)
+	})
+	if listing.err != nil {
+		return nil, listing.err
+	}
+
+	var resources []armResource
+	for _, resource := range listing.resources {
+		if normalizeLocation(resource.Location) == normalizeLocation(region) {
+			resources = append(resources, resource)
+		}
+	}
+	return resources, nil
+}
+
+// normalizeLocation turns display names such as "East US" into location names
+func normalizeLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
+
+// toResource converts a Resource Manager resource
+func toResource(account core.Account, t armType, item armResource) core.Resource {
+	properties := decodeProperties(item.Properties)
+
+	createdAt, updatedAt := resourceTimes(item, properties)
+	configJSON, _ := json.Marshal(item)
+
+	resource := core.Resource{
+		ID:              item.ID,
+		Provider:        "azure",
+		AccountID:       account.ID,
+		Region:          normalizeLocation(item.Location),
+		Service:         t.service,
+		Type:            t.resourceType,
+		Name:            item.Name,
+		ARN:             item.ID, // Azure uses full resource ID as ARN
+		CreatedAt:       createdAt,
+		UpdatedAt:       updatedAt,
+		DiscoveredAt:    time.Now(),
+		DiscoveryMethod: "direct_api",
+		Configuration:   configJSON,
+		Tags:            resourceTags(item.ID, item.Tags),
+		Dependencies:    resourceDependencies(t, properties),
+	}
+
+	enrichResourceSecurity(&resource, properties)
+	return resource
+}
+
+// subnetResources converts the subnets embedded in a virtual network
+func subnetResources(account core.Account, vnet armResource) []core.Resource {
+	var vnetProperties struct {
+		Subnets []armResource `json:"subnets"`
+	}
+	if err := json.Unmarshal(vnet.Properties, &vnetProperties); err != nil {
+		return nil
+	}
+
+	var resources []core.Resource
+	for _, subnet := range vnetProperties.Subnets {
+		properties := decodeProperties(subnet.Properties)
+		createdAt, updatedAt := resourceTimes(vnet, properties)
+		configJSON, _ := json.Marshal(subnet)
+
+		dependencies := []string{vnet.ID}
+		if nsg := stringAt(properties, "networkSecurityGroup", "id"); nsg != "" {
+			dependencies = append(dependencies, nsg)
+		}
+
+		resources = append(resources, core.Resource{
+			ID:              subnet.ID,
+			Provider:        "azure",
+			AccountID:       account.ID,
+			Region:          normalizeLocation(vnet.Location),
+			Service:         virtualNetworksType.service,
+			Type:            subnetsType,
+			Name:            subnet.Name,
+			ARN:             subnet.ID,
+			CreatedAt:       createdAt,
+			UpdatedAt:       updatedAt,
+			DiscoveredAt:    time.Now(),
+			DiscoveryMethod: "direct_api",
+			Configuration:   configJSON,
+			Tags:            resourceTags(subnet.ID, vnet.Tags),
+			Dependencies:    dependencies,
+		})
+	}
+
+	return resources
+}
+
+// decodeProperties decodes the properties of a resource
+func decodeProperties(raw json.RawMessage) map[string]interface{} {
+	properties := make(map[string]interface{})
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &properties)
+	}
+	return properties
+}
+
+// resourceTimes returns the creation and last update times of a resource
+func resourceTimes(item armResource, properties map[string]interface{}) (time.Time, time.Time) {
+	var createdAt, updatedAt time.Time
+	if item.SystemData != nil {
+		createdAt = item.SystemData.CreatedAt
+		updatedAt = item.SystemData.LastModifiedAt
+	}
+	if createdAt.IsZero() {
+		// Compute resources report their creation time in their properties
+		if parsed, err := time.Parse(time.RFC3339, stringAt(properties, "timeCreated")); err == nil {
+			createdAt = parsed
+		}
+	}
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	if updatedAt.IsZero() {
+		updatedAt = createdAt
+	}
+	return createdAt, updatedAt
+}
+
+// resourceTags copies the tags of a resource and adds its resource group
+func resourceTags(id string, tags map[string]string) map[string]string {
+	result := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		result[k] = v
+	}
+	if resourceGroup := resourceGroupFromID(id); resourceGroup != "" {
+		result["ResourceGroup"] = resourceGroup
+	}
+	return result
+}
+
+// resourceGroupFromID extracts the resource group from a resource ID
+func resourceGroupFromID(id string) string {
+	parts := strings.Split(strings.Trim(id, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if strings.EqualFold(parts[i], "resourceGroups") {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// resourceDependencies returns the IDs of resources a resource depends on
+func resourceDependencies(t armType, properties map[string]interface{}) []string {
+	var dependencies []string
+
+	switch t {
+	case virtualMachinesType:
+		// Managed OS and data disks
+		if id := stringAt(properties, "storageProfile", "osDisk", "managedDisk", "id"); id != "" {
+			dependencies = append(dependencies, id)
+		}
+		for _, disk := range sliceAt(properties, "storageProfile", "dataDisks") {
+			if id := stringAt(disk, "managedDisk", "id"); id != "" {
+				dependencies = append(dependencies, id)
+			}
+		}
+	case storageAccountsType, keyVaultsType:
+		// Subnets allowed through the firewall
+		for _, rule := range sliceAt(properties, "networkAcls", "virtualNetworkRules") {
+			if id := stringAt(rule, "id"); id != "" {
+				dependencies = append(dependencies, id)
+			}
+		}
+	case webSitesType:
+		// Regional virtual network integration
+		if id := stringAt(properties, "virtualNetworkSubnetId"); id != "" {
+			dependencies = append(dependencies, id)
+		}
+	}
+
+	return dependencies
+}
+
+// stringAt returns the string at a path of nested objects
+func stringAt(m map[string]interface{}, path ...string) string {
+	value, _ := valueAt(m, path...).(string)
+	return value
+}
+
+// sliceAt returns the objects of the array at a path of nested objects
+func sliceAt(m map[string]interface{}, path ...string) []map[string]interface{} {
+	items, _ := valueAt(m, path...).([]interface{})
+
+	var objects []map[string]interface{}
+	for _, item := range items {
+		if object, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
+
+// valueAt returns the value at a path of nested objects
+func valueAt(m map[string]interface{}, path ...string) interface{} {
+	var value interface{} = m
+	for _, key := range path {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
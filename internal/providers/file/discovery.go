@@ -0,0 +1,313 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	core.RegisterProvider(core.ProviderRegistration{
+		Name:          "file",
+		Description:   "Offline inventory loaded from JSON/YAML fixture files",
+		ConfigSection: "file",
+		Factory: func(config *core.Config) (core.CloudProvider, error) {
+			provider, err := NewProvider(config.File)
+			if err != nil {
+				return nil, err
+			}
+			return provider, nil
+		},
+		Capabilities: core.ProviderCapabilities{
+			AccountTypes: []string{"account", "subscription", "project"},
+		},
+		OptIn: true,
+	})
+}
+
+// FileProvider serves accounts and resources from inventory documents on disk.
+// Resources keep the provider they were recorded with, while their accounts
+// belong to the file provider so the orchestrator routes discovery back here.
+type FileProvider struct {
+	path      string
+	accounts  []core.Account
+	resources map[string][]core.Resource // Keyed by account ID
+}
+
+// inventory is the shape of an inventory document. A document may also be a
+// single resource or a list of resources.
+type inventory struct {
+	Accounts  []core.Account  `json:"accounts"`
+	Resources []core.Resource `json:"resources"`
+}
+
+// NewProvider creates a file provider and loads its inventory
+func NewProvider(cfg core.FileConfig) (*FileProvider, error) {
+	if cfg.Path == "" {
+		return nil, core.NewConfigError("file.path is required for the file provider", nil)
+	}
+
+	p := &FileProvider{
+		path:      cfg.Path,
+		resources: make(map[string][]core.Resource),
+	}
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Name returns the provider name
+func (p *FileProvider) Name() string {
+	return "file"
+}
+
+// DiscoverAccounts returns the accounts declared in or referenced by the inventory
+func (p *FileProvider) DiscoverAccounts(ctx context.Context) ([]core.Account, error) {
+	if len(p.accounts) == 0 {
+		return nil, fmt.Errorf("no accounts found in %s", p.path)
+	}
+	return p.accounts, nil
+}
+
+// DiscoverResources returns the inventory resources of an account
+func (p *FileProvider) DiscoverResources(
+	ctx context.Context,
+	account core.Account,
+	opts core.DiscoveryOptions,
+) ([]core.Resource, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	regions := make(map[string]bool, len(opts.Regions))
+	for _, region := range opts.Regions {
+		regions[region] = true
+	}
+
+	var resources []core.Resource
+	for _, resource := range p.resources[account.ID] {
+		if len(regions) > 0 && !regions[resource.Region] {
+			continue
+		}
+		// Filters are matched against the provider the resource was recorded with
+		if !opts.ResourceSelector(resource.Provider, nil).Selects(resource.Service, resource.Type) {
+			continue
+		}
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
+
+// ValidateCredentials checks that the inventory path is readable
+func (p *FileProvider) ValidateCredentials(ctx context.Context) error {
+	if _, err := os.Stat(p.path); err != nil {
+		return fmt.Errorf("inventory path not accessible: %w", err)
+	}
+	return nil
+}
+
+// load reads every inventory document below the configured path
+func (p *FileProvider) load() error {
+	files, err := inventoryFiles(p.path)
+	if err != nil {
+		return core.NewConfigError("failed to list inventory files", err).WithContext("path", p.path)
+	}
+
+	declared := make(map[string]core.Account)
+	now := time.Now()
+
+	for _, path := range files {
+		doc, err := readInventory(path)
+		if err != nil {
+			return core.NewValidationError("failed to read inventory document", err).WithContext("file", path)
+		}
+
+		for _, account := range doc.Accounts {
+			if account.ID == "" {
+				return core.NewValidationError("account without id", nil).WithContext("file", path)
+			}
+			declared[account.ID] = account
+		}
+
+		for i, resource := range doc.Resources {
+			if resource.ID == "" {
+				return core.NewValidationError(fmt.Sprintf("resource %d has no id", i), nil).WithContext("file", path)
+			}
+			if resource.AccountID == "" {
+				return core.NewValidationError(fmt.Sprintf("resource %s has no account_id", resource.ID), nil).
+					WithContext("file", path)
+			}
+			if resource.DiscoveredAt.IsZero() {
+				resource.DiscoveredAt = now
+			}
+			if resource.DiscoveryMethod == "" {
+				resource.DiscoveryMethod = "file"
+			}
+			p.resources[resource.AccountID] = append(p.resources[resource.AccountID], resource)
+		}
+	}
+
+	// Accounts referenced only by resources are derived from them
+	for accountID, resources := range p.resources {
+		if _, ok := declared[accountID]; !ok {
+			declared[accountID] = core.Account{
+				ID:   accountID,
+				Name: accountID,
+				Type: "account",
+				Tags: map[string]string{"source_provider": sourceProviders(resources)},
+			}
+		}
+	}
+
+	for _, account := range declared {
+		if account.Tags == nil {
+			account.Tags = make(map[string]string)
+		}
+		if account.Provider != "" && account.Provider != p.Name() {
+			account.Tags["source_provider"] = account.Provider
+		}
+		account.Provider = p.Name()
+		p.accounts = append(p.accounts, account)
+	}
+	sort.Slice(p.accounts, func(i, j int) bool {
+		return p.accounts[i].ID < p.accounts[j].ID
+	})
+
+	return nil
+}
+
+// sourceProviders lists the providers resources were recorded with, sorted
+// and comma separated
+func sourceProviders(resources []core.Resource) string {
+	seen := make(map[string]bool)
+	var providers []string
+	for _, resource := range resources {
+		if resource.Provider != "" && !seen[resource.Provider] {
+			seen[resource.Provider] = true
+			providers = append(providers, resource.Provider)
+		}
+	}
+	sort.Strings(providers)
+	return strings.Join(providers, ",")
+}
+
+// inventoryFiles returns the JSON and YAML files at path, which may be a file or a directory
+func inventoryFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(file string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".json", ".yaml", ".yml":
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// readInventory parses an inventory document. YAML documents are converted to
+// JSON first so both formats share the core.Resource json field names.
+func readInventory(path string) (*inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yaml" || ext == ".yml" {
+		var value interface{}
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		data, err = json.Marshal(normalizeYAML(value))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data = []byte(strings.TrimSpace(string(data)))
+	if len(data) == 0 {
+		return &inventory{}, nil
+	}
+
+	// A list of resources
+	if data[0] == '[' {
+		var resources []core.Resource
+		if err := json.Unmarshal(data, &resources); err != nil {
+			return nil, err
+		}
+		return &inventory{Resources: resources}, nil
+	}
+
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+
+	// A full inventory document
+	_, hasAccounts := probe["accounts"]
+	_, hasResources := probe["resources"]
+	if hasAccounts || hasResources {
+		var doc inventory
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		return &doc, nil
+	}
+
+	// A single resource
+	var resource core.Resource
+	if err := json.Unmarshal(data, &resource); err != nil {
+		return nil, err
+	}
+	return &inventory{Resources: []core.Resource{resource}}, nil
+}
+
+// normalizeYAML converts the map[interface{}]interface{} values produced by
+// yaml.v2 into map[string]interface{} so they can be encoded as JSON
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = normalizeYAML(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,298 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeInventory writes inventory documents into a new directory
+func writeInventory(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestNewProvider_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{
+			name:    "account without id",
+			content: `{"accounts": [{"name": "production"}]}`,
+			message: "account without id",
+		},
+		{
+			name:    "resource without id",
+			content: `{"resources": [{"account_id": "123456789012", "provider": "aws"}]}`,
+			message: "resource 0 has no id",
+		},
+		{
+			name:    "resource without account",
+			content: `[{"id": "i-1", "provider": "aws"}]`,
+			message: "resource i-1 has no account_id",
+		},
+		{
+			name:    "malformed document",
+			content: `{"resources": [`,
+			message: "failed to read inventory document",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeInventory(t, map[string]string{"inventory.json": tt.content})
+
+			_, err := NewProvider(core.FileConfig{Path: dir})
+			var reconErr *core.CloudReconError
+			require.ErrorAs(t, err, &reconErr)
+			assert.Equal(t, core.ErrorTypeValidation, reconErr.Type)
+			assert.Contains(t, reconErr.Message, tt.message)
+			assert.Equal(t, filepath.Join(dir, "inventory.json"), reconErr.Context["file"])
+		})
+	}
+}
+
+func TestNewProvider_ConfigErrors(t *testing.T) {
+	_, err := NewProvider(core.FileConfig{})
+	assert.Error(t, err)
+
+	_, err = NewProvider(core.FileConfig{Path: filepath.Join(t.TempDir(), "missing")})
+	var reconErr *core.CloudReconError
+	require.ErrorAs(t, err, &reconErr)
+	assert.Equal(t, core.ErrorTypeConfig, reconErr.Type)
+}
+
+func TestNewProvider_Accounts(t *testing.T) {
+	dir := writeInventory(t, map[string]string{
+		"aws.json": `{
+			"accounts": [{"id": "123456789012", "provider": "aws", "name": "production", "type": "account"}],
+			"resources": [{"id": "i-1", "provider": "aws", "account_id": "123456789012", "region": "us-east-1"}]
+		}`,
+		"nested/gcp.yaml": `
+- id: worker-1
+  provider: gcp
+  account_id: analytics-dev
+  region: us-central1
+- id: exports
+  provider: gcp
+  account_id: analytics-dev
+  region: us-central1
+`,
+		"shared.json": `[
+			{"id": "vpn-1", "provider": "aws", "account_id": "shared"},
+			{"id": "gw-1", "provider": "azure", "account_id": "shared"},
+			{"id": "vpn-2", "provider": "aws", "account_id": "shared"}
+		]`,
+		"notes.txt": "not an inventory document",
+	})
+
+	p, err := NewProvider(core.FileConfig{Path: dir})
+	require.NoError(t, err)
+
+	accounts, err := p.DiscoverAccounts(context.Background())
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+
+	// Declared accounts keep their fields and remember their provider
+	assert.Equal(t, core.Account{
+		ID:       "123456789012",
+		Provider: "file",
+		Name:     "production",
+		Type:     "account",
+		Tags:     map[string]string{"source_provider": "aws"},
+	}, accounts[0])
+
+	// Accounts referenced only by resources are derived from them
+	assert.Equal(t, core.Account{
+		ID:       "analytics-dev",
+		Provider: "file",
+		Name:     "analytics-dev",
+		Type:     "account",
+		Tags:     map[string]string{"source_provider": "gcp"},
+	}, accounts[1])
+	assert.Equal(t, "shared", accounts[2].ID)
+	assert.Equal(t, "aws,azure", accounts[2].Tags["source_provider"])
+
+	resources, err := p.DiscoverResources(context.Background(), accounts[1], core.DiscoveryOptions{})
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	for _, resource := range resources {
+		assert.Equal(t, "file", resource.DiscoveryMethod)
+		assert.False(t, resource.DiscoveredAt.IsZero())
+	}
+}
+
+func TestNewProvider_NoAccounts(t *testing.T) {
+	dir := writeInventory(t, map[string]string{"empty.json": "  \n"})
+
+	p, err := NewProvider(core.FileConfig{Path: dir})
+	require.NoError(t, err)
+
+	_, err = p.DiscoverAccounts(context.Background())
+	assert.ErrorContains(t, err, "no accounts found")
+}
+
+func TestInventoryFiles(t *testing.T) {
+	dir := writeInventory(t, map[string]string{
+		"b.yml":            "[]",
+		"a.JSON":           "[]",
+		"nested/c.yaml":    "[]",
+		"README.md":        "",
+		"nested/state.txt": "",
+	})
+
+	files, err := inventoryFiles(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "a.JSON"),
+		filepath.Join(dir, "b.yml"),
+		filepath.Join(dir, "nested", "c.yaml"),
+	}, files)
+
+	// A single file is read whatever its extension
+	files, err = inventoryFiles(filepath.Join(dir, "README.md"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "README.md")}, files)
+
+	_, err = inventoryFiles(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestReadInventory(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		content   string
+		accounts  int
+		resources []string
+	}{
+		{name: "empty document", file: "empty.json", content: "\n"},
+		{
+			name:      "list of resources",
+			file:      "list.json",
+			content:   `[{"id": "i-1"}, {"id": "i-2"}]`,
+			resources: []string{"i-1", "i-2"},
+		},
+		{
+			name:      "full document",
+			file:      "full.json",
+			content:   `{"accounts": [{"id": "a"}], "resources": [{"id": "i-1"}]}`,
+			accounts:  1,
+			resources: []string{"i-1"},
+		},
+		{
+			name:      "single resource",
+			file:      "single.json",
+			content:   `{"id": "i-1", "account_id": "a"}`,
+			resources: []string{"i-1"},
+		},
+		{
+			name:      "YAML document",
+			file:      "full.yaml",
+			content:   "accounts:\n  - id: a\nresources:\n  - id: i-1\n  - id: i-2\n",
+			accounts:  1,
+			resources: []string{"i-1", "i-2"},
+		},
+		{
+			name:      "single YAML resource",
+			file:      "single.yml",
+			content:   "id: i-1\naccount_id: a\n",
+			resources: []string{"i-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeInventory(t, map[string]string{tt.file: tt.content})
+
+			doc, err := readInventory(filepath.Join(dir, tt.file))
+			require.NoError(t, err)
+			assert.Len(t, doc.Accounts, tt.accounts)
+
+			var ids []string
+			for _, resource := range doc.Resources {
+				ids = append(ids, resource.ID)
+			}
+			assert.Equal(t, tt.resources, ids)
+		})
+	}
+}
+
+func TestReadInventory_YAMLFields(t *testing.T) {
+	dir := writeInventory(t, map[string]string{"bucket.yaml": `
+id: exports
+account_id: analytics-dev
+public_access: true
+monthly_cost: 1.5
+tags:
+  Environment: dev
+configuration:
+  versioning:
+    enabled: true
+  ports: [80, 443]
+  1: numeric key
+`})
+
+	doc, err := readInventory(filepath.Join(dir, "bucket.yaml"))
+	require.NoError(t, err)
+	require.Len(t, doc.Resources, 1)
+
+	resource := doc.Resources[0]
+	assert.Equal(t, "analytics-dev", resource.AccountID)
+	assert.True(t, resource.PublicAccess)
+	assert.Equal(t, 1.5, resource.MonthlyCost)
+	assert.Equal(t, map[string]string{"Environment": "dev"}, resource.Tags)
+	assert.JSONEq(t, `{"versioning": {"enabled": true}, "ports": [80, 443], "1": "numeric key"}`,
+		string(resource.Configuration))
+}
+
+func TestNormalizeYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected interface{}
+	}{
+		{name: "scalar", value: "web", expected: "web"},
+		{name: "nil", value: nil, expected: nil},
+		{
+			name:     "map keys become strings",
+			value:    map[interface{}]interface{}{"name": "web", 8080: true},
+			expected: map[string]interface{}{"name": "web", "8080": true},
+		},
+		{
+			name: "nested maps and lists",
+			value: map[interface{}]interface{}{
+				"rules": []interface{}{
+					map[interface{}]interface{}{"port": 443},
+					"any",
+				},
+			},
+			expected: map[string]interface{}{
+				"rules": []interface{}{
+					map[string]interface{}{"port": 443},
+					"any",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, normalizeYAML(tt.value))
+		})
+	}
+}
@@ -2,24 +2,50 @@ package gcp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"time"
 
 	"cloud.google.com/go/asset/apiv1/assetpb"
+	"cloud.google.com/go/iam"
 	"cloud.google.com/go/storage"
 	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
 	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
 	"github.com/cloudrecon/cloudrecon/internal/core"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
 )
 
 type GCPProvider struct {
 	config               core.GCPConfig
 	assetInventoryClient *GCPAssetInventoryClient
+	regions              regionCache
+	computeOptions       []option.ClientOption // Options of the Compute clients listing regions
+}
+
+func init() {
+	core.RegisterProvider(core.ProviderRegistration{
+		Name:          "gcp",
+		Description:   "Google Cloud Platform",
+		ConfigSection: "gcp",
+		Factory: func(config *core.Config) (core.CloudProvider, error) {
+			provider, err := NewProvider(config.GCP)
+			if err != nil {
+				return nil, err
+			}
+			return provider, nil
+		},
+		Capabilities: core.ProviderCapabilities{
+			NativeTool:   "Cloud Asset Inventory",
+			Regions:      defaultRegions,
+			AccountTypes: []string{"project"},
+		},
+	})
 }
 
 // NewProvider creates a new GCP provider
@@ -45,8 +71,37 @@ func (p *GCPProvider) Name() string {
 	return "gcp"
 }
 
-// DiscoverAccounts discovers all GCP projects using multiple fallback methods
+// DiscoverAccounts discovers all GCP projects using multiple fallback methods,
+// or every project below the configured folders. Each project is tagged with
+// its organization and folder ancestry.
 func (p *GCPProvider) DiscoverAccounts(ctx context.Context) ([]core.Account, error) {
+	accounts, err := p.discoverProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.addAncestry(ctx, accounts)
+	return accounts, nil
+}
+
+// discoverProjects discovers the projects to scan
+func (p *GCPProvider) discoverProjects(ctx context.Context) ([]core.Account, error) {
+	if len(p.config.Folders) > 0 {
+		lister, err := newResourceManagerLister(ctx)
+		if err != nil {
+			return nil, core.NewProviderError("failed to discover projects under GCP folders", err)
+		}
+		defer lister.Close()
+
+		accounts, err := p.discoverFromFolders(ctx, lister)
+		if err != nil {
+			return nil, core.NewProviderError("failed to discover projects under GCP folders", err).
+				WithContext("folders", strings.Join(p.config.Folders, ","))
+		}
+		logrus.Infof("Discovered %d GCP projects under %d folders", len(accounts), len(p.config.Folders))
+		return accounts, nil
+	}
+
 	// Use configured discovery methods or default order
 	methods := p.config.DiscoveryMethods
 	if len(methods) == 0 {
@@ -87,7 +142,11 @@ func (p *GCPProvider) DiscoverResources(
 	// Try native tools first
 	if opts.UseNativeTools {
 		if available, _ := p.IsNativeToolAvailable(ctx, account); available {
-			return p.DiscoverWithNativeTool(ctx, account)
+			resources, err := p.DiscoverWithNativeTool(ctx, account)
+			if err != nil {
+				return nil, err
+			}
+			return opts.ResourceSelector(p.Name(), serviceAliases).Filter(resources), nil
 		}
 	}
 
@@ -248,19 +307,7 @@ func (p *GCPProvider) discoverFromResourceManager(ctx context.Context) ([]core.A
 			continue
 		}
 
-		account := core.Account{
-			ID:       project.ProjectId,
-			Provider: "gcp",
-			Name:     project.DisplayName,
-			Type:     "project",
-			Tags: map[string]string{
-				"lifecycle_state":  project.State.String(),
-				"project_id":       project.ProjectId,
-				"discovery_method": "resource_manager",
-			},
-		}
-
-		accounts = append(accounts, account)
+		accounts = append(accounts, projectAccount(project, "resource_manager"))
 	}
 
 	if len(accounts) == 0 {
@@ -291,15 +338,30 @@ func (p *GCPProvider) validateProjectAccess(ctx context.Context, projectID strin
 	return nil
 }
 
+// discoveryScopes returns the regions to scan. Without --regions every
+// region of the project is scanned, along with the scope of multi-region
+// buckets, which are not in any region; --exclude-regions can leave out both.
+func (p *GCPProvider) discoveryScopes(ctx context.Context, account core.Account, opts core.DiscoveryOptions) []string {
+	if len(opts.Regions) > 0 {
+		return opts.Regions
+	}
+
+	regions := p.getAllRegions(ctx, account)
+	scopes := make([]string, 0, len(regions)+1)
+	scopes = append(scopes, regions...)
+	scopes = append(scopes, multiRegionScope)
+	return opts.FilterRegions(scopes)
+}
+
 // discoverViaDirectAPI falls back to direct API calls
 func (p *GCPProvider) discoverViaDirectAPI(ctx context.Context, account core.Account, opts core.DiscoveryOptions) ([]core.Resource, error) {
 	var resources []core.Resource
 
 	// Get regions to scan
-	regions := opts.Regions
-	if len(regions) == 0 {
-		regions = p.getAllRegions(ctx)
-	}
+	regions := p.discoveryScopes(ctx, account, opts)
+
+	// Project-wide listings are shared between the regions
+	listings := newProjectListings(account.ID)
 
 	// Discover resources in parallel across regions
 	type regionResult struct {
@@ -312,7 +374,35 @@ func (p *GCPProvider) discoverViaDirectAPI(ctx context.Context, account core.Acc
 
 	for _, region := range regions {
 		go func(r string) {
-			regionalResources, err := p.discoverRegionalResources(ctx, r, account, opts)
+			// Regions finished by the run being resumed are already stored
+			if opts.RegionCompleted(account, r) {
+				resultChan <- regionResult{region: r}
+				return
+			}
+
+			opts.ReportProgress(core.DiscoveryProgress{
+				Event:           core.ProgressRegionStarted,
+				CurrentProvider: account.Provider,
+				CurrentAccount:  account.ID,
+				CurrentRegion:   r,
+			})
+
+			regionalResources, err := p.discoverRegionalResources(ctx, listings, r, account, opts)
+			if err == nil {
+				if cpErr := opts.CompleteRegion(ctx, account, r, regionalResources); cpErr != nil {
+					logrus.Warnf("Failed to checkpoint region %s: %v", r, cpErr)
+				}
+			}
+
+			opts.ReportProgress(core.DiscoveryProgress{
+				Event:           core.ProgressRegionFinished,
+				CurrentProvider: account.Provider,
+				CurrentAccount:  account.ID,
+				CurrentRegion:   r,
+				ResourceCount:   len(regionalResources),
+				Error:           err,
+			})
+
 			resultChan <- regionResult{
 				region:    r,
 				resources: regionalResources,
@@ -334,81 +424,182 @@ func (p *GCPProvider) discoverViaDirectAPI(ctx context.Context, account core.Acc
 	return resources, nil
 }
 
+// serviceAliases maps API service names to the names used in Resource.Service
+var serviceAliases = map[string]string{
+	"sqladmin": "sql",
+}
+
 // discoverRegionalResources discovers resources in a specific region
 func (p *GCPProvider) discoverRegionalResources(
 	ctx context.Context,
+	listings *projectListings,
 	region string,
 	account core.Account,
 	opts core.DiscoveryOptions,
 ) ([]core.Resource, error) {
-	var resources []core.Resource
+	selector := opts.ResourceSelector(p.Name(), serviceAliases)
+	resources := opts.DiscoverServices(ctx, account, region, selector, p.regionalServices(opts.Mode, selector, listings, region, account))
 
-	// Discover based on mode
-	switch opts.Mode {
+	if opts.Mode == core.DeepMode {
+		p.mapDependencies(ctx, resources)
+	}
+
+	return resources, nil
+}
+
+// regionalServices returns the services to discover for a discovery mode
+func (p *GCPProvider) regionalServices(
+	mode core.DiscoveryMode,
+	selector core.ResourceSelector,
+	listings *projectListings,
+	region string,
+	account core.Account,
+) []core.ServiceDiscoverer {
+	// The multi-region scope only holds buckets, which Asset Inventory
+	// already returns in unfiltered deep scans
+	if region == multiRegionScope {
+		if mode == core.DeepMode && selector.All() {
+			return nil
+		}
+		return []core.ServiceDiscoverer{
+			{Service: "storage", Types: []core.TypeDiscoverer{
+				discoverType(func(ctx context.Context) []core.Resource {
+					return p.discoverBuckets(ctx, listings, region, account, mode == core.QuickMode)
+				}, "bucket"),
+			}},
+		}
+	}
+
+	compute := core.ServiceDiscoverer{Service: "compute", Types: []core.TypeDiscoverer{
+		discoverType(func(ctx context.Context) []core.Resource {
+			return p.discoverComputeResources(ctx, account.ID)
+		}, "instance", "disk", "network", "firewall"),
+	}}
+
+	standard := []core.ServiceDiscoverer{
+		compute,
+		{Service: "storage", Types: []core.TypeDiscoverer{
+			discoverType(func(ctx context.Context) []core.Resource {
+				return p.discoverStorageResources(ctx, listings, region, account)
+			}, "bucket"),
+		}},
+		{Service: "sql", Types: []core.TypeDiscoverer{
+			discoverType(func(ctx context.Context) []core.Resource {
+				return p.discoverSQLResources(ctx, listings, region, account)
+			}, "instance"),
+		}},
+		{Service: "cloudfunctions", Types: []core.TypeDiscoverer{
+			discoverType(func(ctx context.Context) []core.Resource {
+				return p.discoverCloudFunctions(ctx, region, account)
+			}, "function"),
+		}},
+	}
+
+	switch mode {
 	case core.QuickMode:
 		// Only critical resources
-		resources = append(resources, p.discoverComputeResources(ctx, account.ID)...)
-		resources = append(resources, p.discoverPublicStorageBuckets(ctx, region, account)...)
-		resources = append(resources, p.discoverSQLInstances(ctx, region, account, true)...)
+		return []core.ServiceDiscoverer{
+			compute,
+			{Service: "storage", Types: []core.TypeDiscoverer{
+				discoverType(func(ctx context.Context) []core.Resource {
+					return p.discoverPublicStorageBuckets(ctx, listings, region, account)
+				}, "bucket"),
+			}},
+			{Service: "sql", Types: []core.TypeDiscoverer{
+				discoverType(func(ctx context.Context) []core.Resource {
+					return p.discoverSQLInstances(ctx, listings, region, account, true)
+				}, "instance"),
+			}},
+		}
 
 	case core.StandardMode:
 		// Most resources
-		resources = append(resources, p.discoverComputeResources(ctx, account.ID)...)
-		resources = append(resources, p.discoverStorageResources(ctx, region, account)...)
-		resources = append(resources, p.discoverSQLResources(ctx, region, account)...)
-		resources = append(resources, p.discoverCloudFunctions(ctx, region, account)...)
+		return standard
 
 	case core.DeepMode:
+		// Asset Inventory returns every type at once, so filtered scans
+		// use the per-service discoverers instead
+		if !selector.All() {
+			return standard
+		}
+
 		// Everything including dependencies
-		resources = append(resources, p.discoverAllResources(ctx, region, account)...)
-		p.mapDependencies(ctx, resources)
+		return []core.ServiceDiscoverer{
+			compute,
+			{Service: "cloudasset", Types: []core.TypeDiscoverer{
+				discoverType(func(ctx context.Context) []core.Resource {
+					return p.discoverAssetInventoryResources(ctx, account.ID)
+				}, "asset"),
+			}},
+		}
 	}
 
-	return resources, nil
+	return nil
 }
 
-// getAllRegions returns all available GCP regions
-func (p *GCPProvider) getAllRegions(ctx context.Context) []string {
-	// Return common regions for now
-	return []string{
-		"us-central1", "us-east1", "us-west1", "europe-west1",
-		"asia-east1", "asia-southeast1", "australia-southeast1",
+// discoverType adapts a discovery helper that logs its own errors to a type discoverer
+func discoverType(discover func(ctx context.Context) []core.Resource, resourceTypes ...string) core.TypeDiscoverer {
+	return core.TypeDiscoverer{
+		ResourceTypes: resourceTypes,
+		Discover: func(ctx context.Context) ([]core.Resource, error) {
+			return discover(ctx), nil
+		},
 	}
 }
 
-// discoverPublicStorageBuckets discovers public Cloud Storage buckets
-func (p *GCPProvider) discoverPublicStorageBuckets(ctx context.Context, region string, account core.Account) []core.Resource {
-	// TODO: Implement Cloud Storage bucket discovery
-	return []core.Resource{}
+// discoverPublicStorageBuckets discovers Cloud Storage buckets granted to allUsers or allAuthenticatedUsers
+func (p *GCPProvider) discoverPublicStorageBuckets(
+	ctx context.Context,
+	listings *projectListings,
+	region string,
+	account core.Account,
+) []core.Resource {
+	return p.discoverBuckets(ctx, listings, region, account, true)
 }
 
-// discoverSQLInstances discovers Cloud SQL instances
-func (p *GCPProvider) discoverSQLInstances(ctx context.Context, region string, account core.Account, criticalOnly bool) []core.Resource {
-	// TODO: Implement Cloud SQL instance discovery
-	return []core.Resource{}
+// discoverStorageResources discovers comprehensive Cloud Storage resources
+func (p *GCPProvider) discoverStorageResources(
+	ctx context.Context,
+	listings *projectListings,
+	region string,
+	account core.Account,
+) []core.Resource {
+	return p.discoverBuckets(ctx, listings, region, account, false)
 }
 
-// discoverStorageResources discovers comprehensive Cloud Storage resources
-func (p *GCPProvider) discoverStorageResources(ctx context.Context, region string, account core.Account) []core.Resource {
+// discoverBuckets discovers the Cloud Storage buckets of a discovery scope,
+// either a region or multiRegionScope, and their public IAM members
+func (p *GCPProvider) discoverBuckets(
+	ctx context.Context,
+	listings *projectListings,
+	scope string,
+	account core.Account,
+	publicOnly bool,
+) []core.Resource {
 	var resources []core.Resource
 
-	// Create storage client
-	client, err := storage.NewClient(ctx)
+	// Buckets are listed once per project and shared between scopes
+	buckets, err := listings.getBuckets(ctx, scope)
 	if err != nil {
-		logrus.Warnf("Failed to create storage client: %v", err)
-		return resources
-	}
-	defer client.Close()
-
-	// List all buckets
-	it := client.Buckets(ctx, account.ID)
-	for {
-		bucketAttrs, err := it.Next()
-		if err == iterator.Done {
-			break
+		logrus.Warnf("Failed to discover storage buckets: %v", err)
+		core.RecordScopeError(ctx, err)
+	}
+
+	for _, bucket := range buckets {
+		bucketAttrs, publicMembers := bucket.attrs, bucket.publicMembers
+		if bucket.policyErr != nil {
+			logrus.Warnf("Failed to read IAM policy of bucket %s: %v", bucketAttrs.Name, bucket.policyErr)
+			if publicOnly {
+				// The bucket may be public, so the scan is incomplete
+				core.RecordScopeError(ctx, bucket.policyErr)
+				continue
+			}
 		}
-		if err != nil {
-			logrus.Warnf("Failed to list bucket: %v", err)
+
+		// Public access prevention overrides public IAM bindings
+		public := len(publicMembers) > 0 &&
+			bucketAttrs.PublicAccessPrevention != storage.PublicAccessPreventionEnforced
+		if publicOnly && !public {
 			continue
 		}
 
@@ -417,7 +608,7 @@ func (p *GCPProvider) discoverStorageResources(ctx context.Context, region strin
 			ID:              fmt.Sprintf("storage-bucket-%s", bucketAttrs.Name),
 			Provider:        "gcp",
 			AccountID:       account.ID,
-			Region:          bucketAttrs.Location,
+			Region:          scope,
 			Service:         "storage",
 			Type:            "bucket",
 			Name:            bucketAttrs.Name,
@@ -434,11 +625,14 @@ func (p *GCPProvider) discoverStorageResources(ctx context.Context, region strin
 			},
 		}
 
-		// Add additional metadata
-		if bucketAttrs.Encryption != nil {
-			resource.Tags["encryption"] = "enabled"
+		// Cloud Storage always encrypts data at rest, optionally with a customer key
+		resource.Encrypted = true
+		kmsKeyName := ""
+		if bucketAttrs.Encryption != nil && bucketAttrs.Encryption.DefaultKMSKeyName != "" {
+			kmsKeyName = bucketAttrs.Encryption.DefaultKMSKeyName
+			resource.Tags["encryption"] = "customer_managed"
 		} else {
-			resource.Tags["encryption"] = "disabled"
+			resource.Tags["encryption"] = "google_managed"
 		}
 
 		if bucketAttrs.VersioningEnabled {
@@ -454,17 +648,63 @@ func (p *GCPProvider) discoverStorageResources(ctx context.Context, region strin
 			resource.Tags["public_access"] = "allowed"
 		}
 
+		resource.PublicAccess = public
+		if public {
+			resource.Compliance = append(resource.Compliance, "public-access")
+		}
+
+		resource.Configuration, _ = json.Marshal(map[string]interface{}{
+			"location":                    bucketAttrs.Location,
+			"location_type":               bucketAttrs.LocationType,
+			"storage_class":               bucketAttrs.StorageClass,
+			"versioning_enabled":          bucketAttrs.VersioningEnabled,
+			"uniform_bucket_level_access": bucketAttrs.UniformBucketLevelAccess.Enabled,
+			"public_access_prevention":    bucketAttrs.PublicAccessPrevention.String(),
+			"default_kms_key_name":        kmsKeyName,
+			"public_members":              publicMembers,
+		})
+
 		resources = append(resources, resource)
 	}
 
-	logrus.Debugf("Discovered %d storage buckets for project %s", len(resources), account.ID)
+	logrus.Debugf("Discovered %d storage buckets in %s for project %s", len(resources), scope, account.ID)
 	return resources
 }
 
+// publicBucketMembers returns the IAM bindings of a bucket that grant a role
+// to allUsers or allAuthenticatedUsers, as role=member strings. Legacy bucket
+// ACLs are included since they are reported as legacy IAM roles.
+func publicBucketMembers(ctx context.Context, bucket *storage.BucketHandle) ([]string, error) {
+	policy, err := bucket.IAM().Policy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return publicPolicyMembers(policy), nil
+}
+
+// publicPolicyMembers returns the bindings of an IAM policy granting a role to
+// allUsers or allAuthenticatedUsers, as sorted role=member strings
+func publicPolicyMembers(policy *iam.Policy) []string {
+	var members []string
+	for _, role := range policy.Roles() {
+		for _, member := range policy.Members(role) {
+			if member == "allUsers" || member == "allAuthenticatedUsers" {
+				members = append(members, fmt.Sprintf("%s=%s", role, member))
+			}
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
 // discoverSQLResources discovers comprehensive Cloud SQL resources
-func (p *GCPProvider) discoverSQLResources(ctx context.Context, region string, account core.Account) []core.Resource {
-	// TODO: Implement comprehensive Cloud SQL discovery
-	return []core.Resource{}
+func (p *GCPProvider) discoverSQLResources(
+	ctx context.Context,
+	listings *projectListings,
+	region string,
+	account core.Account,
+) []core.Resource {
+	return p.discoverSQLInstances(ctx, listings, region, account, false)
 }
 
 // discoverCloudFunctions discovers Cloud Functions
@@ -473,17 +713,6 @@ func (p *GCPProvider) discoverCloudFunctions(ctx context.Context, region string,
 	return []core.Resource{}
 }
 
-// discoverAllResources discovers all resources
-func (p *GCPProvider) discoverAllResources(ctx context.Context, region string, account core.Account) []core.Resource {
-	var resources []core.Resource
-
-	// Discover all resource types
-	resources = append(resources, p.discoverComputeResources(ctx, account.ID)...)
-	resources = append(resources, p.discoverAssetInventoryResources(ctx, account.ID)...)
-
-	return resources
-}
-
 // discoverAssetInventoryResources discovers resources using Cloud Asset Inventory
 func (p *GCPProvider) discoverAssetInventoryResources(ctx context.Context, projectID string) []core.Resource {
 	// TODO: Implement Cloud Asset Inventory integration
@@ -0,0 +1,356 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/iterator"
+)
+
+// Resource Manager name prefixes of the resource hierarchy
+const (
+	organizationPrefix = "organizations/"
+	folderPrefix       = "folders/"
+	projectPrefix      = "projects/"
+)
+
+// maxAncestryDepth bounds ancestry walks; Resource Manager allows 10 folder levels
+const maxAncestryDepth = 12
+
+// hierarchyNode is an organization or folder of the resource hierarchy
+type hierarchyNode struct {
+	name        string // organizations/{id} or folders/{id}
+	displayName string
+	parent      string
+}
+
+// hierarchy resolves the organization and folder ancestry of projects.
+// Nodes are cached since projects of a landing zone share most of their ancestors.
+type hierarchy struct {
+	folders       *resourcemanager.FoldersClient
+	organizations *resourcemanager.OrganizationsClient
+	nodes         map[string]hierarchyNode
+}
+
+// newHierarchy creates the Resource Manager clients used to walk the hierarchy
+func newHierarchy(ctx context.Context) (*hierarchy, error) {
+	folders, err := resourcemanager.NewFoldersClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Folders client: %w", err)
+	}
+
+	organizations, err := resourcemanager.NewOrganizationsClient(ctx)
+	if err != nil {
+		folders.Close()
+		return nil, fmt.Errorf("failed to create Organizations client: %w", err)
+	}
+
+	return &hierarchy{
+		folders:       folders,
+		organizations: organizations,
+		nodes:         make(map[string]hierarchyNode),
+	}, nil
+}
+
+// Close releases the Resource Manager clients
+func (h *hierarchy) Close() {
+	h.folders.Close()
+	h.organizations.Close()
+}
+
+// node returns an organization or folder
+func (h *hierarchy) node(ctx context.Context, name string) (hierarchyNode, error) {
+	if node, ok := h.nodes[name]; ok {
+		return node, nil
+	}
+
+	var node hierarchyNode
+	switch {
+	case strings.HasPrefix(name, folderPrefix):
+		folder, err := h.folders.GetFolder(ctx, &resourcemanagerpb.GetFolderRequest{Name: name})
+		if err != nil {
+			return node, fmt.Errorf("failed to get folder %s: %w", name, err)
+		}
+		node = hierarchyNode{name: folder.Name, displayName: folder.DisplayName, parent: folder.Parent}
+
+	case strings.HasPrefix(name, organizationPrefix):
+		organization, err := h.organizations.GetOrganization(ctx, &resourcemanagerpb.GetOrganizationRequest{Name: name})
+		if err != nil {
+			return node, fmt.Errorf("failed to get organization %s: %w", name, err)
+		}
+		node = hierarchyNode{name: organization.Name, displayName: organization.DisplayName}
+
+	default:
+		return node, fmt.Errorf("unsupported parent %s", name)
+	}
+
+	h.nodes[name] = node
+	return node, nil
+}
+
+// ancestry returns the organization and folders above a parent, ordered
+// from the organization down to the parent itself
+func (h *hierarchy) ancestry(ctx context.Context, parent string) ([]hierarchyNode, error) {
+	var nodes []hierarchyNode
+
+	for name := parent; name != ""; {
+		if len(nodes) >= maxAncestryDepth {
+			return nil, fmt.Errorf("ancestry of %s is deeper than %d levels", parent, maxAncestryDepth)
+		}
+
+		node, err := h.node(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append([]hierarchyNode{node}, nodes...)
+		name = node.parent
+	}
+
+	return nodes, nil
+}
+
+// addAncestryTags records the ancestry of a project in its account tags:
+// the full ancestry path of names, the organization, the folder IDs from the
+// top down and their display names
+func addAncestryTags(tags map[string]string, nodes []hierarchyNode) {
+	var names, folderIDs, displayNames []string
+
+	for _, node := range nodes {
+		names = append(names, node.name)
+		displayNames = append(displayNames, node.displayName)
+
+		switch {
+		case strings.HasPrefix(node.name, organizationPrefix):
+			tags["organization_id"] = strings.TrimPrefix(node.name, organizationPrefix)
+		case strings.HasPrefix(node.name, folderPrefix):
+			folderIDs = append(folderIDs, strings.TrimPrefix(node.name, folderPrefix))
+		}
+	}
+
+	if len(nodes) == 0 {
+		return
+	}
+	tags["ancestry"] = strings.Join(names, "/")
+	tags["ancestry_path"] = strings.Join(displayNames, "/")
+	if len(folderIDs) > 0 {
+		tags["folder"] = folderIDs[len(folderIDs)-1]
+		tags["folder_ids"] = strings.Join(folderIDs, "/")
+	}
+}
+
+// orgNodes converts an ancestry into hierarchy nodes
+func orgNodes(nodes []hierarchyNode) []core.OrgNode {
+	result := make([]core.OrgNode, 0, len(nodes))
+	for _, node := range nodes {
+		kind := core.OrgNodeFolder
+		if strings.HasPrefix(node.name, organizationPrefix) {
+			kind = core.OrgNodeOrganization
+		}
+		result = append(result, core.OrgNode{
+			ID:       node.name,
+			Provider: "gcp",
+			Kind:     kind,
+			Name:     node.displayName,
+			ParentID: node.parent,
+		})
+	}
+	return result
+}
+
+// addAncestry records the ancestry of each project. Projects whose parent
+// is unknown are looked up first. Failures are logged and leave the
+// project without ancestry tags.
+func (p *GCPProvider) addAncestry(ctx context.Context, accounts []core.Account) {
+	h, err := newHierarchy(ctx)
+	if err != nil {
+		logrus.Debugf("Skipping GCP project ancestry: %v", err)
+		return
+	}
+	defer h.Close()
+
+	var projects *resourcemanager.ProjectsClient
+	defer func() {
+		if projects != nil {
+			projects.Close()
+		}
+	}()
+
+	for i := range accounts {
+		account := &accounts[i]
+		if account.Tags == nil {
+			account.Tags = make(map[string]string)
+		}
+
+		parent := account.Tags["parent"]
+		if parent == "" {
+			if projects == nil {
+				if projects, err = resourcemanager.NewProjectsClient(ctx); err != nil {
+					logrus.Debugf("Skipping GCP project ancestry: %v", err)
+					return
+				}
+			}
+			project, err := projects.GetProject(ctx, &resourcemanagerpb.GetProjectRequest{
+				Name: projectPrefix + account.ID,
+			})
+			if err != nil {
+				logrus.Debugf("Failed to get parent of project %s: %v", account.ID, err)
+				continue
+			}
+			parent = project.Parent
+			account.Tags["parent"] = parent
+		}
+
+		nodes, err := h.ancestry(ctx, parent)
+		if err != nil {
+			logrus.Warnf("Failed to resolve ancestry of project %s: %v", account.ID, err)
+			continue
+		}
+		addAncestryTags(account.Tags, nodes)
+		account.Hierarchy = orgNodes(nodes)
+	}
+}
+
+// folderLister lists the projects and folders directly below a parent
+type folderLister interface {
+	listProjects(ctx context.Context, parent string) ([]*resourcemanagerpb.Project, error)
+	listFolders(ctx context.Context, parent string) ([]*resourcemanagerpb.Folder, error)
+}
+
+// resourceManagerLister lists the children of a parent with Resource Manager
+type resourceManagerLister struct {
+	folders  *resourcemanager.FoldersClient
+	projects *resourcemanager.ProjectsClient
+}
+
+// newResourceManagerLister creates the Resource Manager clients used to walk folders
+func newResourceManagerLister(ctx context.Context) (*resourceManagerLister, error) {
+	folders, err := resourcemanager.NewFoldersClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Folders client: %w", err)
+	}
+
+	projects, err := resourcemanager.NewProjectsClient(ctx)
+	if err != nil {
+		folders.Close()
+		return nil, fmt.Errorf("failed to create Resource Manager client: %w", err)
+	}
+
+	return &resourceManagerLister{folders: folders, projects: projects}, nil
+}
+
+// Close releases the Resource Manager clients
+func (l *resourceManagerLister) Close() {
+	l.folders.Close()
+	l.projects.Close()
+}
+
+func (l *resourceManagerLister) listProjects(ctx context.Context, parent string) ([]*resourcemanagerpb.Project, error) {
+	var projects []*resourcemanagerpb.Project
+
+	it := l.projects.ListProjects(ctx, &resourcemanagerpb.ListProjectsRequest{Parent: parent})
+	for {
+		project, err := it.Next()
+		if err == iterator.Done {
+			return projects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+}
+
+func (l *resourceManagerLister) listFolders(ctx context.Context, parent string) ([]*resourcemanagerpb.Folder, error) {
+	var folders []*resourcemanagerpb.Folder
+
+	it := l.folders.ListFolders(ctx, &resourcemanagerpb.ListFoldersRequest{Parent: parent})
+	for {
+		folder, err := it.Next()
+		if err == iterator.Done {
+			return folders, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		folders = append(folders, folder)
+	}
+}
+
+// discoverFromFolders discovers every active project below the configured
+// folders, descending through their subfolders
+func (p *GCPProvider) discoverFromFolders(ctx context.Context, lister folderLister) ([]core.Account, error) {
+	var accounts []core.Account
+	seen := make(map[string]bool)
+
+	queue := make([]string, 0, len(p.config.Folders))
+	for _, folder := range p.config.Folders {
+		queue = append(queue, folderName(folder))
+	}
+
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		if seen[parent] {
+			continue
+		}
+		seen[parent] = true
+
+		projects, err := lister.listProjects(ctx, parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list projects in %s: %w", parent, err)
+		}
+		for _, project := range projects {
+			if project.State != resourcemanagerpb.Project_ACTIVE {
+				continue
+			}
+			accounts = append(accounts, projectAccount(project, "folder"))
+		}
+
+		folders, err := lister.listFolders(ctx, parent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list folders in %s: %w", parent, err)
+		}
+		for _, folder := range folders {
+			if folder.State != resourcemanagerpb.Folder_ACTIVE {
+				continue
+			}
+			queue = append(queue, folder.Name)
+		}
+	}
+
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("no active projects found under folders %s", strings.Join(p.config.Folders, ", "))
+	}
+
+	return accounts, nil
+}
+
+// projectAccount converts a Resource Manager project
+func projectAccount(project *resourcemanagerpb.Project, method string) core.Account {
+	return core.Account{
+		ID:       project.ProjectId,
+		Provider: "gcp",
+		Name:     project.DisplayName,
+		Type:     "project",
+		Tags: map[string]string{
+			"lifecycle_state":  project.State.String(),
+			"project_id":       project.ProjectId,
+			"parent":           project.Parent,
+			"discovery_method": method,
+		},
+	}
+}
+
+// folderName accepts a folder ID with or without the folders/ prefix
+func folderName(folder string) string {
+	folder = strings.TrimSpace(folder)
+	if strings.HasPrefix(folder, folderPrefix) {
+		return folder
+	}
+	return folderPrefix + folder
+}
@@ -0,0 +1,224 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testOrganization = hierarchyNode{name: "organizations/100", displayName: "example.com"}
+	testProdFolder   = hierarchyNode{name: "folders/200", displayName: "prod", parent: "organizations/100"}
+	testShopFolder   = hierarchyNode{name: "folders/300", displayName: "shop", parent: "folders/200"}
+)
+
+func TestAddAncestryTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodes    []hierarchyNode
+		expected map[string]string
+	}{
+		{
+			name:     "no ancestry",
+			expected: map[string]string{"parent": "organizations/100"},
+		},
+		{
+			name:  "directly below the organization",
+			nodes: []hierarchyNode{testOrganization},
+			expected: map[string]string{
+				"parent":          "organizations/100",
+				"organization_id": "100",
+				"ancestry":        "organizations/100",
+				"ancestry_path":   "example.com",
+			},
+		},
+		{
+			name:  "nested folders",
+			nodes: []hierarchyNode{testOrganization, testProdFolder, testShopFolder},
+			expected: map[string]string{
+				"parent":          "organizations/100",
+				"organization_id": "100",
+				"ancestry":        "organizations/100/folders/200/folders/300",
+				"ancestry_path":   "example.com/prod/shop",
+				"folder":          "300",
+				"folder_ids":      "200/300",
+			},
+		},
+		{
+			name:  "folder outside a visible organization",
+			nodes: []hierarchyNode{{name: "folders/200", displayName: "prod"}},
+			expected: map[string]string{
+				"parent":        "organizations/100",
+				"ancestry":      "folders/200",
+				"ancestry_path": "prod",
+				"folder":        "200",
+				"folder_ids":    "200",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := map[string]string{"parent": "organizations/100"}
+			addAncestryTags(tags, tt.nodes)
+			assert.Equal(t, tt.expected, tags)
+		})
+	}
+}
+
+func TestOrgNodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodes    []hierarchyNode
+		expected []core.OrgNode
+	}{
+		{name: "no ancestry", expected: []core.OrgNode{}},
+		{
+			name:  "nested folders",
+			nodes: []hierarchyNode{testOrganization, testProdFolder, testShopFolder},
+			expected: []core.OrgNode{
+				{ID: "organizations/100", Provider: "gcp", Kind: core.OrgNodeOrganization, Name: "example.com"},
+				{ID: "folders/200", Provider: "gcp", Kind: core.OrgNodeFolder, Name: "prod", ParentID: "organizations/100"},
+				{ID: "folders/300", Provider: "gcp", Kind: core.OrgNodeFolder, Name: "shop", ParentID: "folders/200"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, orgNodes(tt.nodes))
+		})
+	}
+}
+
+func TestFolderName(t *testing.T) {
+	tests := []struct {
+		folder   string
+		expected string
+	}{
+		{"200", "folders/200"},
+		{"folders/200", "folders/200"},
+		{"  200\n", "folders/200"},
+		{" folders/200 ", "folders/200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.folder, func(t *testing.T) {
+			assert.Equal(t, tt.expected, folderName(tt.folder))
+		})
+	}
+}
+
+func TestDiscoverFromFolders(t *testing.T) {
+	ctx := context.Background()
+	errDenied := errors.New("rpc error: code = PermissionDenied")
+
+	// prod holds a project and the shop folder, which holds another folder
+	// and a deleted project; the retired folder is never walked
+	newLister := func() *fakeFolderLister {
+		return &fakeFolderLister{
+			projects: map[string][]*resourcemanagerpb.Project{
+				"folders/200": {testProject("prod-shared", "folders/200", resourcemanagerpb.Project_ACTIVE)},
+				"folders/300": {
+					testProject("shop-prod", "folders/300", resourcemanagerpb.Project_ACTIVE),
+					testProject("shop-old", "folders/300", resourcemanagerpb.Project_DELETE_REQUESTED),
+				},
+				"folders/400": {testProject("shop-payments", "folders/400", resourcemanagerpb.Project_ACTIVE)},
+				"folders/500": {testProject("retired", "folders/500", resourcemanagerpb.Project_ACTIVE)},
+				"folders/900": {testProject("sandbox", "folders/900", resourcemanagerpb.Project_ACTIVE)},
+			},
+			folders: map[string][]*resourcemanagerpb.Folder{
+				"folders/200": {
+					{Name: "folders/300", Parent: "folders/200", State: resourcemanagerpb.Folder_ACTIVE},
+					{Name: "folders/500", Parent: "folders/200", State: resourcemanagerpb.Folder_DELETE_REQUESTED},
+				},
+				"folders/300": {{Name: "folders/400", Parent: "folders/300", State: resourcemanagerpb.Folder_ACTIVE}},
+			},
+			errors: make(map[string]error),
+		}
+	}
+
+	t.Run("nested folders", func(t *testing.T) {
+		lister := newLister()
+		p := &GCPProvider{config: core.GCPConfig{Folders: []string{"200", "folders/900"}}}
+
+		accounts, err := p.discoverFromFolders(ctx, lister)
+		require.NoError(t, err)
+
+		var ids []string
+		for _, account := range accounts {
+			ids = append(ids, account.ID)
+			assert.Equal(t, "folder", account.Tags["discovery_method"])
+		}
+		assert.Equal(t, []string{"prod-shared", "sandbox", "shop-prod", "shop-payments"}, ids)
+		assert.Equal(t, "folders/400", accounts[3].Tags["parent"])
+		assert.Equal(t, []string{"folders/200", "folders/900", "folders/300", "folders/400"}, lister.walked)
+	})
+
+	t.Run("overlapping folders are walked once", func(t *testing.T) {
+		lister := newLister()
+		p := &GCPProvider{config: core.GCPConfig{Folders: []string{"200", "300"}}}
+
+		accounts, err := p.discoverFromFolders(ctx, lister)
+		require.NoError(t, err)
+		assert.Len(t, accounts, 3)
+		assert.Equal(t, []string{"folders/200", "folders/300", "folders/400"}, lister.walked)
+	})
+
+	t.Run("permission denied partway through", func(t *testing.T) {
+		lister := newLister()
+		lister.errors["folders/300"] = errDenied
+		p := &GCPProvider{config: core.GCPConfig{Folders: []string{"200"}}}
+
+		accounts, err := p.discoverFromFolders(ctx, lister)
+		assert.ErrorIs(t, err, errDenied)
+		assert.ErrorContains(t, err, "failed to list projects in folders/300")
+		assert.Nil(t, accounts)
+		assert.NotContains(t, lister.walked, "folders/400")
+	})
+
+	t.Run("no active projects", func(t *testing.T) {
+		p := &GCPProvider{config: core.GCPConfig{Folders: []string{"500"}}}
+		lister := newLister()
+		lister.projects["folders/500"][0].State = resourcemanagerpb.Project_DELETE_REQUESTED
+
+		_, err := p.discoverFromFolders(ctx, lister)
+		assert.ErrorContains(t, err, "no active projects found under folders 500")
+	})
+}
+
+func testProject(id, parent string, state resourcemanagerpb.Project_State) *resourcemanagerpb.Project {
+	return &resourcemanagerpb.Project{
+		Name:        "projects/" + id,
+		ProjectId:   id,
+		DisplayName: id,
+		Parent:      parent,
+		State:       state,
+	}
+}
+
+// fakeFolderLister serves the children of each parent and records the
+// order in which parents are listed
+type fakeFolderLister struct {
+	projects map[string][]*resourcemanagerpb.Project
+	folders  map[string][]*resourcemanagerpb.Folder
+	errors   map[string]error
+	walked   []string
+}
+
+func (f *fakeFolderLister) listProjects(_ context.Context, parent string) ([]*resourcemanagerpb.Project, error) {
+	f.walked = append(f.walked, parent)
+	if err := f.errors[parent]; err != nil {
+		return nil, err
+	}
+	return f.projects[parent], nil
+}
+
+func (f *fakeFolderLister) listFolders(_ context.Context, parent string) ([]*resourcemanagerpb.Folder, error) {
+	return f.folders[parent], nil
+}
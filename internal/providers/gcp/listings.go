@@ -0,0 +1,134 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	sqladmin "google.golang.org/api/sqladmin/v1"
+)
+
+// multiRegionScope is the discovery scope of multi-region and dual-region
+// buckets, which span several regions and so belong to none of them
+const multiRegionScope = "multi-region"
+
+// bucketLocationTypeRegion is the location type of single-region buckets
+const bucketLocationTypeRegion = "region"
+
+// projectListings lists the project-wide resources of a project once and
+// shares them between the regions being discovered, each keeping its own
+type projectListings struct {
+	projectID string
+
+	bucketsOnce sync.Once
+	buckets     []bucketListing
+	bucketsErr  error
+
+	sqlOnce      sync.Once
+	sqlInstances []*sqladmin.DatabaseInstance
+	sqlErr       error
+}
+
+// bucketListing is a listed bucket with the public members of its IAM policy
+type bucketListing struct {
+	attrs         *storage.BucketAttrs
+	publicMembers []string
+	policyErr     error
+}
+
+// newProjectListings creates the listings of a project
+func newProjectListings(projectID string) *projectListings {
+	return &projectListings{projectID: projectID}
+}
+
+// getBuckets returns the buckets of a discovery scope. Buckets listed before
+// the listing failed are returned along with the error.
+func (l *projectListings) getBuckets(ctx context.Context, scope string) ([]bucketListing, error) {
+	l.bucketsOnce.Do(func() {
+		l.buckets, l.bucketsErr = listBuckets(ctx, l.projectID)
+	})
+
+	var buckets []bucketListing
+	for _, bucket := range l.buckets {
+		if bucketScope(bucket.attrs.Location, bucket.attrs.LocationType) == scope {
+			buckets = append(buckets, bucket)
+		}
+	}
+	return buckets, l.bucketsErr
+}
+
+// getSQLInstances returns the Cloud SQL instances of a region
+func (l *projectListings) getSQLInstances(ctx context.Context, region string) ([]*sqladmin.DatabaseInstance, error) {
+	l.sqlOnce.Do(func() {
+		l.sqlInstances, l.sqlErr = listSQLInstances(ctx, l.projectID)
+	})
+	if l.sqlErr != nil {
+		return nil, l.sqlErr
+	}
+
+	var instances []*sqladmin.DatabaseInstance
+	for _, instance := range l.sqlInstances {
+		if instance.Region == region {
+			instances = append(instances, instance)
+		}
+	}
+	return instances, nil
+}
+
+// bucketScope returns the discovery scope of a bucket: the region of a
+// single-region bucket, or multiRegionScope for the others
+func bucketScope(location, locationType string) string {
+	if strings.EqualFold(locationType, bucketLocationTypeRegion) {
+		return strings.ToLower(location)
+	}
+	return multiRegionScope
+}
+
+// listBuckets lists the buckets of a project and reads their IAM policy
+func listBuckets(ctx context.Context, projectID string) ([]bucketListing, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage client: %w", err)
+	}
+	defer client.Close()
+
+	var buckets []bucketListing
+	it := client.Buckets(ctx, projectID)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			// Iterator errors are sticky, so the listing cannot continue
+			return buckets, fmt.Errorf("failed to list buckets: %w", err)
+		}
+
+		members, err := publicBucketMembers(ctx, client.Bucket(attrs.Name))
+		buckets = append(buckets, bucketListing{attrs: attrs, publicMembers: members, policyErr: err})
+	}
+
+	return buckets, nil
+}
+
+// listSQLInstances lists the Cloud SQL instances of a project
+func listSQLInstances(ctx context.Context, projectID string) ([]*sqladmin.DatabaseInstance, error) {
+	service, err := sqladmin.NewService(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Cloud SQL client: %w", err)
+	}
+
+	var instances []*sqladmin.DatabaseInstance
+	err = service.Instances.List(projectID).Pages(ctx, func(page *sqladmin.InstancesListResponse) error {
+		instances = append(instances, page.Items...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Cloud SQL instances: %w", err)
+	}
+
+	return instances, nil
+}
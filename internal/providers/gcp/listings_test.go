@@ -0,0 +1,141 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/iam"
+	"cloud.google.com/go/storage"
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketScope(t *testing.T) {
+	tests := []struct {
+		location     string
+		locationType string
+		expected     string
+	}{
+		{"US-CENTRAL1", "region", "us-central1"},
+		{"europe-west4", "region", "europe-west4"},
+		{"US", "multi-region", multiRegionScope},
+		{"EU", "multi-region", multiRegionScope},
+		{"NAM4", "dual-region", multiRegionScope},
+		{"US-EAST1+US-WEST1", "dual-region", multiRegionScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			assert.Equal(t, tt.expected, bucketScope(tt.location, tt.locationType))
+		})
+	}
+}
+
+func TestPublicPolicyMembers(t *testing.T) {
+	policy := &iam.Policy{}
+	policy.Add("allUsers", "roles/storage.objectViewer")
+	policy.Add("user:admin@example.com", "roles/storage.objectViewer")
+	policy.Add("allAuthenticatedUsers", "roles/storage.legacyBucketReader")
+	policy.Add("serviceAccount:app@shop-prod.iam.gserviceaccount.com", "roles/storage.admin")
+
+	assert.Equal(t, []string{
+		"roles/storage.legacyBucketReader=allAuthenticatedUsers",
+		"roles/storage.objectViewer=allUsers",
+	}, publicPolicyMembers(policy))
+
+	private := &iam.Policy{}
+	private.Add("group:dev@example.com", "roles/storage.objectAdmin")
+	assert.Empty(t, publicPolicyMembers(private))
+}
+
+func TestDiscoverBuckets(t *testing.T) {
+	p := &GCPProvider{}
+	account := core.Account{ID: "shop-prod", Provider: "gcp"}
+
+	// The project listing is filled in as if it had already been listed
+	listings := newProjectListings(account.ID)
+	listings.bucketsOnce.Do(func() {
+		listings.buckets = []bucketListing{
+			{attrs: &storage.BucketAttrs{Name: "assets", Location: "US", LocationType: "multi-region"},
+				publicMembers: []string{"roles/storage.objectViewer=allUsers"}},
+			{attrs: &storage.BucketAttrs{Name: "backups", Location: "NAM4", LocationType: "dual-region"}},
+			{attrs: &storage.BucketAttrs{Name: "logs", Location: "US-CENTRAL1", LocationType: "region"}},
+			{attrs: &storage.BucketAttrs{Name: "locked", Location: "US-CENTRAL1", LocationType: "region",
+				PublicAccessPrevention: storage.PublicAccessPreventionEnforced},
+				publicMembers: []string{"roles/storage.objectViewer=allUsers"}},
+			{attrs: &storage.BucketAttrs{Name: "restricted", Location: "US-CENTRAL1", LocationType: "region"},
+				policyErr: errors.New("permission denied")},
+		}
+	})
+
+	ctx := context.Background()
+
+	t.Run("multi-region scope", func(t *testing.T) {
+		resources := p.discoverBuckets(ctx, listings, multiRegionScope, account, false)
+		require.Len(t, resources, 2)
+		assert.ElementsMatch(t, []string{"assets", "backups"}, resourceNames(resources))
+		for _, resource := range resources {
+			assert.Equal(t, multiRegionScope, resource.Region)
+		}
+		assert.Equal(t, "US", resources[0].Tags["location"])
+		assert.True(t, resources[0].PublicAccess)
+	})
+
+	t.Run("regional scope", func(t *testing.T) {
+		resources := p.discoverBuckets(ctx, listings, "us-central1", account, false)
+		assert.ElementsMatch(t, []string{"logs", "locked", "restricted"}, resourceNames(resources))
+		for _, resource := range resources {
+			assert.Equal(t, "us-central1", resource.Region)
+			// Public access prevention overrides the public binding of locked
+			assert.False(t, resource.PublicAccess)
+		}
+
+		assert.Empty(t, p.discoverBuckets(ctx, listings, "europe-west1", account, false))
+	})
+
+	t.Run("public only", func(t *testing.T) {
+		assert.Equal(t, []string{"assets"},
+			resourceNames(p.discoverBuckets(ctx, listings, multiRegionScope, account, true)))
+		assert.Empty(t, p.discoverBuckets(ctx, listings, "us-central1", account, true))
+	})
+}
+
+func TestDiscoveryScopes(t *testing.T) {
+	account := core.Account{ID: "shop-prod", Provider: "gcp"}
+	p := &GCPProvider{}
+	p.regions.set(account.ID, []string{"europe-west1", "us-central1"})
+
+	tests := []struct {
+		name     string
+		opts     core.DiscoveryOptions
+		expected []string
+	}{
+		{
+			name:     "every region",
+			expected: []string{"europe-west1", "us-central1", multiRegionScope},
+		},
+		{
+			name:     "explicit regions leave out multi-region buckets",
+			opts:     core.DiscoveryOptions{Regions: []string{"us-central1"}},
+			expected: []string{"us-central1"},
+		},
+		{
+			name:     "explicit multi-region scope",
+			opts:     core.DiscoveryOptions{Regions: []string{"us-central1", multiRegionScope}},
+			expected: []string{"us-central1", multiRegionScope},
+		},
+		{
+			name:     "excluded regions",
+			opts:     core.DiscoveryOptions{ExcludeRegions: []string{"europe-west1", multiRegionScope}},
+			expected: []string{"us-central1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, p.discoveryScopes(context.Background(), account, tt.opts))
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/sirupsen/logrus"
+	compute "google.golang.org/api/compute/v1"
+)
+
+// regionCacheTTL is how long the regions of a project are cached
+const regionCacheTTL = time.Hour
+
+// defaultRegions are scanned when the regions of a project cannot be listed
+var defaultRegions = []string{
+	"us-central1", "us-east1", "us-west1", "europe-west1",
+	"asia-east1", "asia-southeast1", "australia-southeast1",
+}
+
+// regionCache holds the listed regions per project
+type regionCache struct {
+	mu      sync.Mutex
+	entries map[string]regionCacheEntry
+}
+
+// regionCacheEntry is a cached region list with its expiry time
+type regionCacheEntry struct {
+	regions   []string
+	expiresAt time.Time
+}
+
+// get returns the cached regions of a project
+func (c *regionCache) get(projectID string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[projectID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.regions, true
+}
+
+// set caches the regions of a project
+func (c *regionCache) set(projectID string, regions []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]regionCacheEntry)
+	}
+	c.entries[projectID] = regionCacheEntry{
+		regions:   regions,
+		expiresAt: time.Now().Add(regionCacheTTL),
+	}
+}
+
+// getAllRegions returns the Compute regions available to a project
+func (p *GCPProvider) getAllRegions(ctx context.Context, account core.Account) []string {
+	if regions, ok := p.regions.get(account.ID); ok {
+		return regions
+	}
+
+	regions, err := p.listRegions(ctx, account.ID)
+	if err != nil {
+		logrus.Warnf("Failed to list regions for project %s, using default regions: %v", account.ID, err)
+		return defaultRegions
+	}
+
+	p.regions.set(account.ID, regions)
+	return regions
+}
+
+// listRegions lists the project's Compute regions that are up
+func (p *GCPProvider) listRegions(ctx context.Context, projectID string) ([]string, error) {
+	service, err := compute.NewService(ctx, p.computeOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Compute client: %w", err)
+	}
+
+	var regions []string
+	err = service.Regions.List(projectID).Pages(ctx, func(page *compute.RegionList) error {
+		regions = append(regions, upRegions(page.Items)...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list regions: %w", err)
+	}
+
+	if len(regions) == 0 {
+		return nil, fmt.Errorf("no regions returned")
+	}
+
+	sort.Strings(regions)
+	return regions, nil
+}
+
+// upRegions returns the names of the regions that are up
+func upRegions(regions []*compute.Region) []string {
+	var names []string
+	for _, region := range regions {
+		if region.Status == "UP" {
+			names = append(names, region.Name)
+		}
+	}
+	return names
+}
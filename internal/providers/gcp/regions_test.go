@@ -0,0 +1,99 @@
+package gcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/stretchr/testify/assert"
+	compute "google.golang.org/api/compute/v1"
+	"google.golang.org/api/option"
+)
+
+func TestUpRegions(t *testing.T) {
+	tests := []struct {
+		name     string
+		regions  []*compute.Region
+		expected []string
+	}{
+		{
+			name: "regions that are up",
+			regions: []*compute.Region{
+				{Name: "us-central1", Status: "UP"},
+				{Name: "europe-west9", Status: "DOWN"},
+				{Name: "asia-east1", Status: "UP"},
+				{Name: "me-west1"},
+			},
+			expected: []string{"us-central1", "asia-east1"},
+		},
+		{name: "every region down", regions: []*compute.Region{{Name: "us-east1", Status: "DOWN"}}},
+		{name: "no regions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, upRegions(tt.regions))
+		})
+	}
+}
+
+func TestGetAllRegions(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected []string
+		cached   bool
+	}{
+		{
+			name:     "regions that are up",
+			status:   http.StatusOK,
+			body:     `{"items": [{"name": "us-east1", "status": "UP"}, {"name": "europe-west9", "status": "DOWN"}, {"name": "asia-east1", "status": "UP"}]}`,
+			expected: []string{"asia-east1", "us-east1"},
+			cached:   true,
+		},
+		{
+			name:     "every region down",
+			status:   http.StatusOK,
+			body:     `{"items": [{"name": "us-east1", "status": "DOWN"}]}`,
+			expected: defaultRegions,
+		},
+		{
+			name:     "listing denied",
+			status:   http.StatusForbidden,
+			body:     `{"error": {"code": 403, "message": "Required 'compute.regions.list' permission"}}`,
+			expected: defaultRegions,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p := &GCPProvider{computeOptions: []option.ClientOption{
+				option.WithEndpoint(server.URL),
+				option.WithoutAuthentication(),
+			}}
+			account := core.Account{ID: "shop-prod", Provider: "gcp"}
+
+			assert.Equal(t, tt.expected, p.getAllRegions(context.Background(), account))
+
+			// Only listed regions are cached, the default regions are not
+			assert.Equal(t, tt.expected, p.getAllRegions(context.Background(), account))
+			if tt.cached {
+				assert.Equal(t, 1, calls)
+			} else {
+				assert.Equal(t, 2, calls)
+			}
+		})
+	}
+}
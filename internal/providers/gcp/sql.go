@@ -0,0 +1,193 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/sirupsen/logrus"
+	sqladmin "google.golang.org/api/sqladmin/v1"
+)
+
+// sqlInstanceRunnable is the state of a Cloud SQL instance that is serving
+const sqlInstanceRunnable = "RUNNABLE"
+
+// discoverSQLInstances discovers the Cloud SQL instances of a region
+func (p *GCPProvider) discoverSQLInstances(
+	ctx context.Context,
+	listings *projectListings,
+	region string,
+	account core.Account,
+	criticalOnly bool,
+) []core.Resource {
+	var resources []core.Resource
+
+	// Instances are listed once per project and shared between regions
+	instances, err := listings.getSQLInstances(ctx, region)
+	if err != nil {
+		logrus.Warnf("Failed to discover Cloud SQL instances: %v", err)
+		core.RecordScopeError(ctx, err)
+		return resources
+	}
+
+	for _, instance := range instances {
+		// Stopped and failed instances are skipped in quick mode
+		if criticalOnly && instance.State != sqlInstanceRunnable {
+			continue
+		}
+		resources = append(resources, p.sqlInstanceResource(instance, account))
+	}
+
+	logrus.Debugf("Discovered %d Cloud SQL instances in %s for project %s", len(resources), region, account.ID)
+	return resources
+}
+
+// sqlInstanceResource converts a Cloud SQL instance
+func (p *GCPProvider) sqlInstanceResource(instance *sqladmin.DatabaseInstance, account core.Account) core.Resource {
+	id := sqlInstanceID(account.ID, instance.Name)
+
+	createdAt, err := time.Parse(time.RFC3339, instance.CreateTime)
+	if err != nil {
+		createdAt = time.Now()
+	}
+
+	tags := map[string]string{
+		"database_version": instance.DatabaseVersion,
+		"state":            instance.State,
+	}
+
+	config := map[string]interface{}{
+		"database_version": instance.DatabaseVersion,
+		"state":            instance.State,
+		"instance_type":    instance.InstanceType,
+		"connection_name":  instance.ConnectionName,
+		"gce_zone":         instance.GceZone,
+		"replica_names":    instance.ReplicaNames,
+	}
+
+	var ipAddresses []map[string]string
+	for _, address := range instance.IpAddresses {
+		ipAddresses = append(ipAddresses, map[string]string{
+			"ip_address": address.IpAddress,
+			"type":       address.Type,
+		})
+	}
+	config["ip_addresses"] = ipAddresses
+
+	kmsKeyName := ""
+	if instance.DiskEncryptionConfiguration != nil {
+		kmsKeyName = instance.DiskEncryptionConfiguration.KmsKeyName
+	}
+	config["kms_key_name"] = kmsKeyName
+	if kmsKeyName != "" {
+		tags["encryption"] = "customer_managed"
+	} else {
+		tags["encryption"] = "google_managed"
+	}
+
+	public := false
+	backupsEnabled := false
+
+	if settings := instance.Settings; settings != nil {
+		for key, value := range settings.UserLabels {
+			tags[key] = value
+		}
+		tags["tier"] = settings.Tier
+		config["tier"] = settings.Tier
+		config["availability_type"] = settings.AvailabilityType
+		config["data_disk_size_gb"] = settings.DataDiskSizeGb
+		config["deletion_protection"] = settings.DeletionProtectionEnabled
+
+		if ipConfig := settings.IpConfiguration; ipConfig != nil {
+			var authorizedNetworks []map[string]string
+			for _, network := range ipConfig.AuthorizedNetworks {
+				authorizedNetworks = append(authorizedNetworks, map[string]string{
+					"name":  network.Name,
+					"value": network.Value,
+				})
+				// A public IP reachable from any address is open to the internet
+				if ipConfig.Ipv4Enabled && isOpenCIDR(network.Value) {
+					public = true
+				}
+			}
+
+			config["ip_configuration"] = map[string]interface{}{
+				"ipv4_enabled":        ipConfig.Ipv4Enabled,
+				"private_network":     ipConfig.PrivateNetwork,
+				"require_ssl":         ipConfig.RequireSsl,
+				"ssl_mode":            ipConfig.SslMode,
+				"authorized_networks": authorizedNetworks,
+			}
+		}
+
+		if backup := settings.BackupConfiguration; backup != nil {
+			backupsEnabled = backup.Enabled
+			backupConfig := map[string]interface{}{
+				"enabled":                        backup.Enabled,
+				"start_time":                     backup.StartTime,
+				"location":                       backup.Location,
+				"point_in_time_recovery_enabled": backup.PointInTimeRecoveryEnabled,
+				"binary_log_enabled":             backup.BinaryLogEnabled,
+				"transaction_log_retention_days": backup.TransactionLogRetentionDays,
+			}
+			if retention := backup.BackupRetentionSettings; retention != nil {
+				backupConfig["retained_backups"] = retention.RetainedBackups
+				backupConfig["retention_unit"] = retention.RetentionUnit
+			}
+			config["backup_configuration"] = backupConfig
+		}
+	}
+
+	configJSON, _ := json.Marshal(config)
+
+	resource := core.Resource{
+		ID:              id,
+		Provider:        "gcp",
+		AccountID:       account.ID,
+		Region:          instance.Region,
+		Service:         "sql",
+		Type:            "instance",
+		Name:            instance.Name,
+		ARN:             id,
+		CreatedAt:       createdAt,
+		UpdatedAt:       time.Now(),
+		DiscoveredAt:    time.Now(),
+		DiscoveryMethod: "direct_api",
+		Tags:            tags,
+		Configuration:   configJSON,
+		// Cloud SQL always encrypts data at rest
+		Encrypted:    true,
+		PublicAccess: public,
+	}
+
+	if public {
+		resource.Compliance = append(resource.Compliance, "public-access")
+	}
+	if !backupsEnabled {
+		resource.Compliance = append(resource.Compliance, "backups-disabled")
+	}
+
+	// Read replicas depend on their primary, named project:instance
+	if instance.MasterInstanceName != "" {
+		project, name := account.ID, instance.MasterInstanceName
+		if i := strings.Index(name, ":"); i >= 0 {
+			project, name = name[:i], name[i+1:]
+		}
+		resource.Dependencies = append(resource.Dependencies, sqlInstanceID(project, name))
+	}
+
+	return resource
+}
+
+// sqlInstanceID returns the full resource name of a Cloud SQL instance
+func sqlInstanceID(projectID, name string) string {
+	return fmt.Sprintf("//cloudsql.googleapis.com/projects/%s/instances/%s", projectID, name)
+}
+
+// isOpenCIDR reports whether a CIDR range matches every address
+func isOpenCIDR(cidr string) bool {
+	return cidr == "0.0.0.0/0" || cidr == "::/0"
+}
@@ -0,0 +1,158 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sqladmin "google.golang.org/api/sqladmin/v1"
+)
+
+func TestSQLInstanceResource(t *testing.T) {
+	p := &GCPProvider{}
+	account := core.Account{ID: "shop-prod", Provider: "gcp"}
+
+	t.Run("public instance without backups", func(t *testing.T) {
+		instance := &sqladmin.DatabaseInstance{
+			Name:            "orders",
+			Region:          "us-central1",
+			DatabaseVersion: "POSTGRES_15",
+			State:           sqlInstanceRunnable,
+			IpAddresses:     []*sqladmin.IpMapping{{IpAddress: "34.1.2.3", Type: "PRIMARY"}},
+			Settings: &sqladmin.Settings{
+				Tier:       "db-custom-2-7680",
+				UserLabels: map[string]string{"team": "orders"},
+				IpConfiguration: &sqladmin.IpConfiguration{
+					Ipv4Enabled: true,
+					AuthorizedNetworks: []*sqladmin.AclEntry{
+						{Name: "office", Value: "203.0.113.0/24"},
+						{Name: "anywhere", Value: "0.0.0.0/0"},
+					},
+				},
+				BackupConfiguration: &sqladmin.BackupConfiguration{Enabled: false},
+			},
+		}
+
+		resource := p.sqlInstanceResource(instance, account)
+
+		assert.Equal(t, "//cloudsql.googleapis.com/projects/shop-prod/instances/orders", resource.ID)
+		assert.Equal(t, "us-central1", resource.Region)
+		assert.Equal(t, "sql", resource.Service)
+		assert.Equal(t, "instance", resource.Type)
+		assert.True(t, resource.PublicAccess)
+		assert.True(t, resource.Encrypted)
+		assert.ElementsMatch(t, []string{"public-access", "backups-disabled"}, resource.Compliance)
+		assert.Equal(t, "orders", resource.Tags["team"])
+		assert.Equal(t, "google_managed", resource.Tags["encryption"])
+		assert.Empty(t, resource.Dependencies)
+
+		var config struct {
+			IPAddresses     []map[string]string `json:"ip_addresses"`
+			IPConfiguration struct {
+				AuthorizedNetworks []map[string]string `json:"authorized_networks"`
+			} `json:"ip_configuration"`
+			BackupConfiguration map[string]interface{} `json:"backup_configuration"`
+		}
+		require.NoError(t, json.Unmarshal(resource.Configuration, &config))
+		assert.Equal(t, []map[string]string{{"ip_address": "34.1.2.3", "type": "PRIMARY"}}, config.IPAddresses)
+		assert.Equal(t, []map[string]string{
+			{"name": "office", "value": "203.0.113.0/24"},
+			{"name": "anywhere", "value": "0.0.0.0/0"},
+		}, config.IPConfiguration.AuthorizedNetworks)
+		assert.Equal(t, false, config.BackupConfiguration["enabled"])
+	})
+
+	t.Run("open network without public IP", func(t *testing.T) {
+		instance := &sqladmin.DatabaseInstance{
+			Name:   "internal",
+			Region: "us-central1",
+			Settings: &sqladmin.Settings{
+				IpConfiguration: &sqladmin.IpConfiguration{
+					Ipv4Enabled:        false,
+					AuthorizedNetworks: []*sqladmin.AclEntry{{Value: "0.0.0.0/0"}},
+				},
+				BackupConfiguration: &sqladmin.BackupConfiguration{Enabled: true},
+			},
+		}
+
+		resource := p.sqlInstanceResource(instance, account)
+
+		assert.False(t, resource.PublicAccess)
+		assert.Empty(t, resource.Compliance)
+	})
+
+	t.Run("read replica", func(t *testing.T) {
+		resource := p.sqlInstanceResource(&sqladmin.DatabaseInstance{
+			Name:               "orders-replica",
+			MasterInstanceName: "shop-core:orders",
+			DiskEncryptionConfiguration: &sqladmin.DiskEncryptionConfiguration{
+				KmsKeyName: "projects/shop-core/locations/us/keyRings/sql/cryptoKeys/orders",
+			},
+		}, account)
+
+		assert.Equal(t, []string{"//cloudsql.googleapis.com/projects/shop-core/instances/orders"}, resource.Dependencies)
+		assert.Equal(t, "customer_managed", resource.Tags["encryption"])
+
+		resource = p.sqlInstanceResource(&sqladmin.DatabaseInstance{
+			Name:               "orders-replica",
+			MasterInstanceName: "orders",
+		}, account)
+
+		assert.Equal(t, []string{"//cloudsql.googleapis.com/projects/shop-prod/instances/orders"}, resource.Dependencies)
+	})
+}
+
+func TestIsOpenCIDR(t *testing.T) {
+	tests := []struct {
+		cidr     string
+		expected bool
+	}{
+		{"0.0.0.0/0", true},
+		{"::/0", true},
+		{"0.0.0.0/1", false},
+		{"10.0.0.0/8", false},
+		{"203.0.113.7/32", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isOpenCIDR(tt.cidr))
+		})
+	}
+}
+
+func TestDiscoverSQLInstances(t *testing.T) {
+	p := &GCPProvider{}
+	account := core.Account{ID: "shop-prod", Provider: "gcp"}
+
+	// The project listing is filled in as if it had already been listed
+	listings := newProjectListings(account.ID)
+	listings.sqlOnce.Do(func() {
+		listings.sqlInstances = []*sqladmin.DatabaseInstance{
+			{Name: "orders", Region: "us-central1", State: sqlInstanceRunnable},
+			{Name: "reports", Region: "us-central1", State: "SUSPENDED"},
+			{Name: "eu-orders", Region: "europe-west1", State: sqlInstanceRunnable},
+		}
+	})
+
+	ctx := context.Background()
+	assert.ElementsMatch(t, []string{"orders", "reports"},
+		resourceNames(p.discoverSQLInstances(ctx, listings, "us-central1", account, false)))
+	assert.Equal(t, []string{"orders"},
+		resourceNames(p.discoverSQLInstances(ctx, listings, "us-central1", account, true)))
+	assert.Equal(t, []string{"eu-orders"},
+		resourceNames(p.discoverSQLInstances(ctx, listings, "europe-west1", account, false)))
+	assert.Empty(t, p.discoverSQLInstances(ctx, listings, "asia-east1", account, false))
+}
+
+func resourceNames(resources []core.Resource) []string {
+	names := []string{}
+	for _, resource := range resources {
+		names = append(names, resource.Name)
+	}
+	return names
+}
@@ -0,0 +1,220 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+func init() {
+	core.RegisterProvider(core.ProviderRegistration{
+		Name:          "terraform",
+		Description:   "Inventory read from local Terraform state files (format version 4)",
+		ConfigSection: "terraform",
+		Factory: func(config *core.Config) (core.CloudProvider, error) {
+			provider, err := NewProvider(config.Terraform)
+			if err != nil {
+				return nil, err
+			}
+			return provider, nil
+		},
+		Capabilities: core.ProviderCapabilities{
+			AccountTypes: []string{"account", "subscription", "project"},
+		},
+		OptIn: true,
+	})
+}
+
+// TerraformProvider maps resources recorded in Terraform state to core resources.
+// Resources keep the cloud provider they belong to, while their accounts
+// belong to the terraform provider so the orchestrator routes discovery back here.
+type TerraformProvider struct {
+	statePaths []string
+
+	// The state files are read once and shared by every account
+	once      sync.Once
+	resources []core.Resource
+	err       error
+}
+
+// NewProvider creates a new Terraform state provider
+func NewProvider(cfg core.TerraformConfig) (*TerraformProvider, error) {
+	if len(cfg.StatePaths) == 0 {
+		return nil, core.NewConfigError("terraform.state_paths is required for the terraform provider", nil)
+	}
+
+	return &TerraformProvider{
+		statePaths: cfg.StatePaths,
+	}, nil
+}
+
+// Name returns the provider name
+func (p *TerraformProvider) Name() string {
+	return "terraform"
+}
+
+// DiscoverAccounts returns the accounts, subscriptions and projects referenced by the state files
+func (p *TerraformProvider) DiscoverAccounts(ctx context.Context) ([]core.Account, error) {
+	resources, err := p.states()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var accounts []core.Account
+	for _, resource := range resources {
+		if seen[resource.AccountID] {
+			continue
+		}
+		seen[resource.AccountID] = true
+
+		accounts = append(accounts, core.Account{
+			ID:       resource.AccountID,
+			Provider: p.Name(),
+			Name:     resource.AccountID,
+			Type:     accountType(resource.Provider),
+			Tags: map[string]string{
+				"source_provider": resource.Provider,
+			},
+		})
+	}
+
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("no supported resources found in Terraform state")
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].ID < accounts[j].ID
+	})
+	return accounts, nil
+}
+
+// DiscoverResources returns the state resources of an account
+func (p *TerraformProvider) DiscoverResources(
+	ctx context.Context,
+	account core.Account,
+	opts core.DiscoveryOptions,
+) ([]core.Resource, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	all, err := p.states()
+	if err != nil {
+		return nil, err
+	}
+
+	regions := make(map[string]bool, len(opts.Regions))
+	for _, region := range opts.Regions {
+		regions[region] = true
+	}
+
+	var resources []core.Resource
+	for _, resource := range all {
+		if resource.AccountID != account.ID {
+			continue
+		}
+		if len(regions) > 0 && !regions[resource.Region] {
+			continue
+		}
+		if !opts.ResourceSelector(resource.Provider, nil).Selects(resource.Service, resource.Type) {
+			continue
+		}
+		resources = append(resources, resource)
+	}
+
+	return resources, nil
+}
+
+// ValidateCredentials checks that the state paths are readable
+func (p *TerraformProvider) ValidateCredentials(ctx context.Context) error {
+	for _, path := range p.statePaths {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("state path not accessible: %w", err)
+		}
+	}
+	return nil
+}
+
+// states returns the resources of every configured state file
+func (p *TerraformProvider) states() ([]core.Resource, error) {
+	p.once.Do(func() {
+		p.resources, p.err = p.readStates()
+	})
+	return p.resources, p.err
+}
+
+// readStates reads every configured state file
+func (p *TerraformProvider) readStates() ([]core.Resource, error) {
+	files, err := stateFiles(p.statePaths)
+	if err != nil {
+		return nil, core.NewConfigError("failed to list Terraform state files", err)
+	}
+
+	discoveredAt := time.Now()
+	var resources []core.Resource
+	for _, file := range files {
+		stateResources, err := readState(file, discoveredAt)
+		if err != nil {
+			return nil, core.NewValidationError("failed to read Terraform state", err).WithContext("file", file)
+		}
+		resources = append(resources, stateResources...)
+	}
+
+	return resources, nil
+}
+
+// stateFiles expands directories in paths to the *.tfstate files they contain
+func stateFiles(paths []string) ([]string, error) {
+	var files []string
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(file string, entry os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// Provider plugins and modules live in .terraform
+			if entry.IsDir() && entry.Name() == ".terraform" {
+				return filepath.SkipDir
+			}
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".tfstate") {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// accountType returns the account type used by a cloud provider
+func accountType(provider string) string {
+	switch provider {
+	case "azure":
+		return "subscription"
+	case "gcp":
+		return "project"
+	default:
+		return "account"
+	}
+}
@@ -0,0 +1,97 @@
+package terraform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerraformProvider_ReadsStatesOnce(t *testing.T) {
+	dir := t.TempDir()
+	state := `{
+		"version": 4,
+		"resources": [
+			{"mode": "managed", "type": "aws_instance", "name": "web", "instances": [
+				{"attributes": {"id": "i-1", "arn": "arn:aws:ec2:us-east-1:123456789012:instance/i-1"}}
+			]},
+			{"mode": "managed", "type": "azurerm_resource_group", "name": "logs", "instances": [
+				{"attributes": {"id": "/subscriptions/sub-1/resourceGroups/logs", "location": "westeurope"}}
+			]}
+		]
+	}`
+	path := filepath.Join(dir, "terraform.tfstate")
+	require.NoError(t, os.WriteFile(path, []byte(state), 0o644))
+
+	p, err := NewProvider(core.TerraformConfig{StatePaths: []string{dir}})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	accounts, err := p.DiscoverAccounts(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "123456789012", accounts[0].ID)
+	assert.Equal(t, "account", accounts[0].Type)
+	assert.Equal(t, "sub-1", accounts[1].ID)
+	assert.Equal(t, "subscription", accounts[1].Type)
+	assert.Equal(t, "azure", accounts[1].Tags["source_provider"])
+
+	// Resources come from the states read for the accounts
+	require.NoError(t, os.Remove(path))
+
+	resources, err := p.DiscoverResources(ctx, accounts[0], core.DiscoveryOptions{})
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "i-1", resources[0].ID)
+
+	resources, err = p.DiscoverResources(ctx, accounts[1], core.DiscoveryOptions{Regions: []string{"eastus"}})
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+}
+
+func TestTerraformProvider_NoAccounts(t *testing.T) {
+	dir := t.TempDir()
+	state := `{"version": 4, "resources": [
+		{"mode": "managed", "type": "aws_s3_bucket", "name": "assets", "instances": [
+			{"attributes": {"id": "assets", "arn": "arn:aws:s3:::assets"}}
+		]}
+	]}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "terraform.tfstate"), []byte(state), 0o644))
+
+	p, err := NewProvider(core.TerraformConfig{StatePaths: []string{dir}})
+	require.NoError(t, err)
+
+	// The bucket carries no account, so none is invented for it
+	_, err = p.DiscoverAccounts(context.Background())
+	assert.ErrorContains(t, err, "no supported resources found")
+}
+
+func TestStateFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"prod/terraform.tfstate",
+		"dev/terraform.tfstate",
+		"dev/terraform.tfstate.backup",
+		"dev/.terraform/terraform.tfstate",
+		"main.tf",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("{}"), 0o644))
+	}
+
+	files, err := stateFiles([]string{dir, filepath.Join(dir, "main.tf")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "dev", "terraform.tfstate"),
+		filepath.Join(dir, "main.tf"),
+		filepath.Join(dir, "prod", "terraform.tfstate"),
+	}, files)
+
+	_, err = stateFiles([]string{filepath.Join(dir, "missing")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,415 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/sirupsen/logrus"
+)
+
+// stateFile is the subset of the Terraform state format version 4 read by the provider
+type stateFile struct {
+	Version          int             `json:"version"`
+	TerraformVersion string          `json:"terraform_version"`
+	Resources        []stateResource `json:"resources"`
+}
+
+// stateResource is a resource block with all of its instances
+type stateResource struct {
+	Module    string          `json:"module"`
+	Mode      string          `json:"mode"`
+	Type      string          `json:"type"`
+	Name      string          `json:"name"`
+	Provider  string          `json:"provider"`
+	Instances []stateInstance `json:"instances"`
+}
+
+// stateInstance is a single instance of a resource, one per count or for_each key
+type stateInstance struct {
+	IndexKey            interface{}            `json:"index_key"`
+	Attributes          map[string]interface{} `json:"attributes"`
+	SensitiveAttributes []json.RawMessage      `json:"sensitive_attributes"`
+	Dependencies        []string               `json:"dependencies"`
+}
+
+// resourceKind is the CloudRecon service and type of a Terraform resource type
+type resourceKind struct {
+	service      string
+	resourceType string
+}
+
+// knownKinds maps Terraform resource types to the service and type names
+// used by the live providers, so state and API discoveries line up
+var knownKinds = map[string]resourceKind{
+	// AWS
+	"aws_instance":                      {"ec2", "instance"},
+	"aws_security_group":                {"ec2", "security-group"},
+	"aws_ebs_volume":                    {"ec2", "volume"},
+	"aws_vpc":                           {"ec2", "vpc"},
+	"aws_subnet":                        {"ec2", "subnet"},
+	"aws_s3_bucket":                     {"s3", "bucket"},
+	"aws_db_instance":                   {"rds", "db-instance"},
+	"aws_rds_cluster":                   {"rds", "db-cluster"},
+	"aws_db_parameter_group":            {"rds", "db-parameter-group"},
+	"aws_db_subnet_group":               {"rds", "db-subnet-group"},
+	"aws_iam_user":                      {"iam", "user"},
+	"aws_iam_role":                      {"iam", "role"},
+	"aws_iam_group":                     {"iam", "group"},
+	"aws_iam_policy":                    {"iam", "policy"},
+	"aws_iam_access_key":                {"iam", "access-key"},
+	"aws_lambda_function":               {"lambda", "function"},
+	"aws_lambda_layer_version":          {"lambda", "layer"},
+	"aws_lambda_event_source_mapping":   {"lambda", "event-source-mapping"},
+	"aws_cloudformation_stack":          {"cloudformation", "stack"},
+	"aws_cloudformation_stack_set":      {"cloudformation", "stack-set"},
+	"aws_ecs_cluster":                   {"ecs", "cluster"},
+	"aws_ecs_service":                   {"ecs", "service"},
+	"aws_ecs_task_definition":           {"ecs", "task-definition"},
+	"aws_elasticache_replication_group": {"elasticache", "replication-group"},
+	"aws_lb":                            {"elbv2", "load-balancer"},
+	"aws_alb":                           {"elbv2", "load-balancer"},
+	"aws_sns_topic":                     {"sns", "topic"},
+	"aws_sqs_queue":                     {"sqs", "queue"},
+	"aws_route53_zone":                  {"route53", "hosted-zone"},
+
+	// Azure
+	"azurerm_virtual_machine":         {"compute", "virtual-machine"},
+	"azurerm_linux_virtual_machine":   {"compute", "virtual-machine"},
+	"azurerm_windows_virtual_machine": {"compute", "virtual-machine"},
+	"azurerm_managed_disk":            {"compute", "disk"},
+	"azurerm_virtual_network":         {"network", "virtual-network"},
+	"azurerm_subnet":                  {"network", "subnet"},
+	"azurerm_network_security_group":  {"network", "network-security-group"},
+	"azurerm_storage_account":         {"storage", "storage-account"},
+	"azurerm_mssql_server":            {"sql", "server"},
+	"azurerm_sql_server":              {"sql", "server"},
+	"azurerm_mssql_database":          {"sql", "database"},
+	"azurerm_sql_database":            {"sql", "database"},
+	"azurerm_linux_web_app":           {"web", "site"},
+	"azurerm_windows_web_app":         {"web", "site"},
+	"azurerm_app_service":             {"web", "site"},
+	"azurerm_key_vault":               {"keyvault", "vault"},
+
+	// GCP
+	"google_compute_instance":        {"compute", "instance"},
+	"google_compute_disk":            {"compute", "disk"},
+	"google_compute_network":         {"compute", "network"},
+	"google_compute_firewall":        {"compute", "firewall"},
+	"google_storage_bucket":          {"storage", "bucket"},
+	"google_sql_database_instance":   {"sql", "instance"},
+	"google_cloudfunctions_function": {"cloudfunctions", "function"},
+}
+
+// providerPrefixes maps Terraform resource type prefixes to CloudRecon providers
+var providerPrefixes = []struct {
+	prefix   string
+	provider string
+}{
+	{"aws_", "aws"},
+	{"azurerm_", "azure"},
+	{"google_", "gcp"},
+}
+
+// readState parses a state file and maps its managed resources.
+// Resources of unsupported providers are skipped.
+func readState(path string, discoveredAt time.Time) ([]core.Resource, error) {
+	data, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+
+	var state stateFile
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid state file: %w", err)
+	}
+	if state.Version != 4 {
+		return nil, fmt.Errorf("unsupported state format version %d", state.Version)
+	}
+
+	var resources []core.Resource
+	ids := make(map[string][]string) // Terraform address to resource IDs
+	var dependencies [][]string
+
+	for _, block := range state.Resources {
+		if block.Mode != "managed" {
+			continue
+		}
+		provider, kind, ok := classify(block.Type)
+		if !ok {
+			continue
+		}
+
+		for _, instance := range block.Instances {
+			address := instanceAddress(block, instance)
+			resource := mapInstance(provider, kind, block, instance, address)
+			resource.DiscoveredAt = discoveredAt
+			resource.DiscoveryMethod = "terraform_state"
+
+			// Dependencies name whole resources, so instances are also reachable by the block address
+			ids[address] = append(ids[address], resource.ID)
+			if address != blockAddress(block) {
+				ids[blockAddress(block)] = append(ids[blockAddress(block)], resource.ID)
+			}
+
+			resources = append(resources, resource)
+			dependencies = append(dependencies, instance.Dependencies)
+		}
+	}
+
+	// Resolve Terraform addresses once every instance has an ID
+	for i := range resources {
+		for _, address := range dependencies[i] {
+			resources[i].Dependencies = append(resources[i].Dependencies, ids[address]...)
+		}
+	}
+
+	fillAccounts(resources)
+
+	// A resource without an account cannot be stored, so it is skipped
+	located := resources[:0]
+	for _, resource := range resources {
+		if resource.AccountID == "" {
+			logrus.Warnf("Skipping %s in %s: its %s account cannot be derived",
+				resource.Tags["terraform_address"], path, resource.Provider)
+			continue
+		}
+		located = append(located, resource)
+	}
+	return located, nil
+}
+
+// fillAccounts assigns resources whose ID carries no account, such as S3
+// buckets, to the provider's account when the state file only uses one
+func fillAccounts(resources []core.Resource) {
+	accounts := make(map[string]map[string]bool)
+	for _, resource := range resources {
+		if resource.AccountID == "" {
+			continue
+		}
+		if accounts[resource.Provider] == nil {
+			accounts[resource.Provider] = make(map[string]bool)
+		}
+		accounts[resource.Provider][resource.AccountID] = true
+	}
+
+	for i := range resources {
+		if resources[i].AccountID != "" || len(accounts[resources[i].Provider]) != 1 {
+			continue
+		}
+		for accountID := range accounts[resources[i].Provider] {
+			resources[i].AccountID = accountID
+		}
+	}
+}
+
+// classify returns the provider and kind of a Terraform resource type
+func classify(terraformType string) (string, resourceKind, bool) {
+	for _, p := range providerPrefixes {
+		if !strings.HasPrefix(terraformType, p.prefix) {
+			continue
+		}
+		if kind, ok := knownKinds[terraformType]; ok {
+			return p.provider, kind, true
+		}
+
+		// aws_dynamodb_table becomes service dynamodb, type table
+		rest := strings.TrimPrefix(terraformType, p.prefix)
+		service, resourceType, found := strings.Cut(rest, "_")
+		if !found {
+			resourceType = service
+		}
+		return p.provider, resourceKind{service, strings.ReplaceAll(resourceType, "_", "-")}, true
+	}
+	return "", resourceKind{}, false
+}
+
+// blockAddress returns the Terraform address of a resource block
+func blockAddress(block stateResource) string {
+	address := block.Type + "." + block.Name
+	if block.Module != "" {
+		address = block.Module + "." + address
+	}
+	return address
+}
+
+// instanceAddress returns the Terraform address of a resource instance
+func instanceAddress(block stateResource, instance stateInstance) string {
+	address := blockAddress(block)
+	switch key := instance.IndexKey.(type) {
+	case string:
+		address += fmt.Sprintf("[%q]", key)
+	case float64:
+		address += fmt.Sprintf("[%d]", int(key))
+	}
+	return address
+}
+
+// mapInstance converts a resource instance into a core.Resource
+func mapInstance(provider string, kind resourceKind, block stateResource, instance stateInstance, address string) core.Resource {
+	attributes := redactSensitive(instance.Attributes, instance.SensitiveAttributes)
+
+	resource := core.Resource{
+		ID:       stringAttribute(attributes, "id"),
+		Provider: provider,
+		Service:  kind.service,
+		Type:     kind.resourceType,
+		Name:     stringAttribute(attributes, "name"),
+		Tags:     tagsFromAttributes(provider, attributes),
+	}
+	if resource.ID == "" {
+		resource.ID = address
+	}
+	if resource.Name == "" {
+		resource.Name = resource.Tags["Name"]
+	}
+	if resource.Name == "" {
+		resource.Name = block.Name
+	}
+
+	switch provider {
+	case "aws":
+		resource.ARN = stringAttribute(attributes, "arn")
+		resource.AccountID, resource.Region = awsLocation(resource.ARN, attributes)
+	case "azure":
+		resource.ARN = resource.ID
+		resource.AccountID = azureSubscription(resource.ID)
+		resource.Region = strings.ToLower(strings.ReplaceAll(stringAttribute(attributes, "location"), " ", ""))
+	case "gcp":
+		resource.ARN = stringAttribute(attributes, "self_link")
+		resource.AccountID = stringAttribute(attributes, "project")
+		resource.Region = gcpRegion(attributes)
+	}
+	if resource.Region == "" {
+		resource.Region = "global"
+	}
+
+	resource.PublicAccess = boolAttribute(attributes, "publicly_accessible") ||
+		stringAttribute(attributes, "public_ip") != ""
+	resource.Encrypted = boolAttribute(attributes, "encrypted") ||
+		boolAttribute(attributes, "storage_encrypted")
+
+	resource.Tags["terraform_address"] = address
+
+	if configuration, err := json.Marshal(attributes); err == nil {
+		resource.Configuration = configuration
+	}
+
+	return resource
+}
+
+// redactSensitive drops top-level attributes that Terraform marks as sensitive
+func redactSensitive(attributes map[string]interface{}, sensitive []json.RawMessage) map[string]interface{} {
+	if len(sensitive) == 0 {
+		return attributes
+	}
+
+	redacted := make(map[string]interface{}, len(attributes))
+	for key, value := range attributes {
+		redacted[key] = value
+	}
+
+	for _, raw := range sensitive {
+		var path []struct {
+			Type  string      `json:"type"`
+			Value interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(raw, &path); err != nil || len(path) == 0 {
+			continue
+		}
+		if name, ok := path[0].Value.(string); ok && path[0].Type == "get_attr" {
+			delete(redacted, name)
+		}
+	}
+
+	return redacted
+}
+
+// tagsFromAttributes reads resource tags, or labels for GCP
+func tagsFromAttributes(provider string, attributes map[string]interface{}) map[string]string {
+	keys := []string{"tags_all", "tags"}
+	if provider == "gcp" {
+		keys = []string{"labels", "user_labels"}
+	}
+
+	for _, key := range keys {
+		values, ok := attributes[key].(map[string]interface{})
+		if !ok || len(values) == 0 {
+			continue
+		}
+		tags := make(map[string]string, len(values))
+		for name, value := range values {
+			tags[name] = fmt.Sprint(value)
+		}
+		return tags
+	}
+	return make(map[string]string)
+}
+
+// awsLocation extracts the account and region of an AWS resource
+func awsLocation(arn string, attributes map[string]interface{}) (string, string) {
+	var accountID, region string
+
+	// arn:partition:service:region:account:resource
+	if parts := strings.SplitN(arn, ":", 6); len(parts) == 6 {
+		region = parts[3]
+		accountID = parts[4]
+	}
+	if accountID == "" {
+		accountID = stringAttribute(attributes, "owner_id")
+	}
+	if region == "" {
+		region = stringAttribute(attributes, "region")
+	}
+	if zone := stringAttribute(attributes, "availability_zone"); region == "" && len(zone) > 1 {
+		region = zone[:len(zone)-1]
+	}
+
+	return accountID, region
+}
+
+// azureSubscription extracts the subscription ID from an ARM resource ID
+func azureSubscription(id string) string {
+	parts := strings.Split(strings.Trim(id, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if strings.EqualFold(parts[i], "subscriptions") {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// gcpRegion derives the region of a GCP resource from its region, zone or location
+func gcpRegion(attributes map[string]interface{}) string {
+	if region := stringAttribute(attributes, "region"); region != "" {
+		return lastSegment(region)
+	}
+	if zone := lastSegment(stringAttribute(attributes, "zone")); zone != "" {
+		// us-central1-a becomes us-central1
+		if i := strings.LastIndex(zone, "-"); i > 0 {
+			return zone[:i]
+		}
+		return zone
+	}
+	return strings.ToLower(stringAttribute(attributes, "location"))
+}
+
+// lastSegment returns the last path segment of a self link or plain name
+func lastSegment(value string) string {
+	return value[strings.LastIndex(value, "/")+1:]
+}
+
+// stringAttribute returns a string attribute, or "" when absent
+func stringAttribute(attributes map[string]interface{}, key string) string {
+	value, _ := attributes[key].(string)
+	return value
+}
+
+// boolAttribute returns a bool attribute, or false when absent
+func boolAttribute(attributes map[string]interface{}, key string) bool {
+	value, _ := attributes[key].(bool)
+	return value
+}
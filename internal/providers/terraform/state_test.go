@@ -0,0 +1,311 @@
+package terraform
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		terraformType string
+		provider      string
+		kind          resourceKind
+		ok            bool
+	}{
+		{"aws_instance", "aws", resourceKind{"ec2", "instance"}, true},
+		{"aws_lb", "aws", resourceKind{"elbv2", "load-balancer"}, true},
+		{"aws_dynamodb_table", "aws", resourceKind{"dynamodb", "table"}, true},
+		{"aws_kinesis_firehose_delivery_stream", "aws", resourceKind{"kinesis", "firehose-delivery-stream"}, true},
+		{"aws_eip", "aws", resourceKind{"eip", "eip"}, true},
+		{"azurerm_linux_virtual_machine", "azure", resourceKind{"compute", "virtual-machine"}, true},
+		{"azurerm_cosmosdb_account", "azure", resourceKind{"cosmosdb", "account"}, true},
+		{"google_storage_bucket", "gcp", resourceKind{"storage", "bucket"}, true},
+		{"google_pubsub_topic", "gcp", resourceKind{"pubsub", "topic"}, true},
+		{"random_password", "", resourceKind{}, false},
+		{"kubernetes_namespace", "", resourceKind{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.terraformType, func(t *testing.T) {
+			provider, kind, ok := classify(tt.terraformType)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.provider, provider)
+			assert.Equal(t, tt.kind, kind)
+		})
+	}
+}
+
+func TestAWSLocation(t *testing.T) {
+	tests := []struct {
+		name       string
+		arn        string
+		attributes map[string]interface{}
+		accountID  string
+		region     string
+	}{
+		{
+			name:      "regional ARN",
+			arn:       "arn:aws:ec2:us-east-1:123456789012:instance/i-1",
+			accountID: "123456789012",
+			region:    "us-east-1",
+		},
+		{
+			name:      "ARN with colons in the resource",
+			arn:       "arn:aws:lambda:eu-west-1:123456789012:function:api:live",
+			accountID: "123456789012",
+			region:    "eu-west-1",
+		},
+		{
+			name:       "global ARN without an account",
+			arn:        "arn:aws:s3:::staging-assets",
+			attributes: map[string]interface{}{"region": "us-west-2"},
+			region:     "us-west-2",
+		},
+		{
+			name:      "IAM ARN without a region",
+			arn:       "arn:aws:iam::123456789012:role/reader",
+			accountID: "123456789012",
+		},
+		{
+			name:       "owner and availability zone",
+			attributes: map[string]interface{}{"owner_id": "210987654321", "availability_zone": "eu-central-1b"},
+			accountID:  "210987654321",
+			region:     "eu-central-1",
+		},
+		{name: "nothing to go on"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountID, region := awsLocation(tt.arn, tt.attributes)
+			assert.Equal(t, tt.accountID, accountID)
+			assert.Equal(t, tt.region, region)
+		})
+	}
+}
+
+func TestAzureSubscription(t *testing.T) {
+	tests := []struct {
+		id       string
+		expected string
+	}{
+		{"/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/logs", "sub-1"},
+		{"/SUBSCRIPTIONS/sub-2/resourceGroups/rg", "sub-2"},
+		{"/subscriptions/sub-3", "sub-3"},
+		{"/subscriptions/", ""},
+		{"/providers/Microsoft.Management/managementGroups/root", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			assert.Equal(t, tt.expected, azureSubscription(tt.id))
+		})
+	}
+}
+
+func TestFillAccounts(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []core.Resource
+		expected  []string
+	}{
+		{
+			name: "single account of the provider",
+			resources: []core.Resource{
+				{Provider: "aws", AccountID: "123456789012"},
+				{Provider: "aws"},
+				{Provider: "aws", AccountID: "123456789012"},
+			},
+			expected: []string{"123456789012", "123456789012", "123456789012"},
+		},
+		{
+			name: "several accounts of the provider",
+			resources: []core.Resource{
+				{Provider: "aws", AccountID: "123456789012"},
+				{Provider: "aws", AccountID: "210987654321"},
+				{Provider: "aws"},
+			},
+			expected: []string{"123456789012", "210987654321", ""},
+		},
+		{
+			name: "no account of the provider",
+			resources: []core.Resource{
+				{Provider: "gcp", AccountID: "analytics"},
+				{Provider: "aws"},
+			},
+			expected: []string{"analytics", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fillAccounts(tt.resources)
+
+			var accounts []string
+			for _, resource := range tt.resources {
+				accounts = append(accounts, resource.AccountID)
+			}
+			assert.Equal(t, tt.expected, accounts)
+		})
+	}
+}
+
+func TestMapInstance(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		block    stateResource
+		instance stateInstance
+		expected core.Resource
+	}{
+		{
+			name:     "AWS instance",
+			provider: "aws",
+			block:    stateResource{Type: "aws_instance", Name: "web"},
+			instance: stateInstance{Attributes: map[string]interface{}{
+				"id":        "i-1",
+				"arn":       "arn:aws:ec2:us-east-1:123456789012:instance/i-1",
+				"public_ip": "54.0.0.10",
+				"tags":      map[string]interface{}{"Name": "web-0"},
+				"tags_all":  map[string]interface{}{"Name": "web-0", "Team": "platform"},
+			}},
+			expected: core.Resource{
+				ID:           "i-1",
+				ARN:          "arn:aws:ec2:us-east-1:123456789012:instance/i-1",
+				Provider:     "aws",
+				AccountID:    "123456789012",
+				Region:       "us-east-1",
+				Name:         "web-0",
+				PublicAccess: true,
+				Tags:         map[string]string{"Name": "web-0", "Team": "platform"},
+			},
+		},
+		{
+			name:     "Azure storage account",
+			provider: "azure",
+			block:    stateResource{Type: "azurerm_storage_account", Name: "logs"},
+			instance: stateInstance{Attributes: map[string]interface{}{
+				"id":       "/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/stlogs",
+				"name":     "stlogs",
+				"location": "West Europe",
+				"tags":     map[string]interface{}{"cost_center": 42},
+			}},
+			expected: core.Resource{
+				ID:        "/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/stlogs",
+				ARN:       "/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/stlogs",
+				Provider:  "azure",
+				AccountID: "sub-1",
+				Region:    "westeurope",
+				Name:      "stlogs",
+				Tags:      map[string]string{"cost_center": "42"},
+			},
+		},
+		{
+			name:     "GCP instance",
+			provider: "gcp",
+			block:    stateResource{Type: "google_compute_instance", Name: "worker"},
+			instance: stateInstance{Attributes: map[string]interface{}{
+				"id":        "projects/analytics/zones/europe-west1-b/instances/worker",
+				"name":      "worker",
+				"project":   "analytics",
+				"zone":      "https://www.googleapis.com/compute/v1/projects/analytics/zones/europe-west1-b",
+				"self_link": "https://www.googleapis.com/compute/v1/projects/analytics/zones/europe-west1-b/instances/worker",
+				"labels":    map[string]interface{}{"env": "staging"},
+			}},
+			expected: core.Resource{
+				ID:        "projects/analytics/zones/europe-west1-b/instances/worker",
+				ARN:       "https://www.googleapis.com/compute/v1/projects/analytics/zones/europe-west1-b/instances/worker",
+				Provider:  "gcp",
+				AccountID: "analytics",
+				Region:    "europe-west1",
+				Name:      "worker",
+				Tags:      map[string]string{"env": "staging"},
+			},
+		},
+		{
+			name:     "no ID, name or region",
+			provider: "aws",
+			block:    stateResource{Type: "aws_db_instance", Name: "main"},
+			instance: stateInstance{
+				Attributes: map[string]interface{}{"storage_encrypted": true, "password": "secret"},
+				SensitiveAttributes: []json.RawMessage{
+					json.RawMessage(`[{"type": "get_attr", "value": "password"}]`),
+				},
+			},
+			expected: core.Resource{
+				ID:        "module.db.aws_db_instance.main",
+				Provider:  "aws",
+				Region:    "global",
+				Name:      "main",
+				Encrypted: true,
+				Tags:      map[string]string{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := "module.db." + blockAddress(tt.block)
+			_, kind, ok := classify(tt.block.Type)
+			require.True(t, ok)
+
+			resource := mapInstance(tt.provider, kind, tt.block, tt.instance, address)
+			assert.Equal(t, address, resource.Tags["terraform_address"])
+			assert.NotContains(t, string(resource.Configuration), "secret")
+
+			// The address tag and configuration are checked above
+			delete(resource.Tags, "terraform_address")
+			resource.Configuration = nil
+			tt.expected.Service, tt.expected.Type = kind.service, kind.resourceType
+			assert.Equal(t, tt.expected, resource)
+		})
+	}
+}
+
+func TestReadState_SkipsResourcesWithoutAccount(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	state := `{
+		"version": 4,
+		"resources": [
+			{"mode": "managed", "type": "aws_instance", "name": "a", "instances": [
+				{"attributes": {"id": "i-1", "arn": "arn:aws:ec2:us-east-1:123456789012:instance/i-1"}}
+			]},
+			{"mode": "managed", "type": "aws_instance", "name": "b", "instances": [
+				{"attributes": {"id": "i-2", "arn": "arn:aws:ec2:us-east-1:210987654321:instance/i-2"}}
+			]},
+			{"mode": "managed", "type": "aws_s3_bucket", "name": "assets", "instances": [
+				{"attributes": {"id": "assets", "arn": "arn:aws:s3:::assets"}}
+			]},
+			{"mode": "managed", "type": "google_storage_bucket", "name": "exports", "instances": [
+				{"attributes": {"id": "exports", "location": "EU"}}
+			]}
+		]
+	}`
+	require.NoError(t, os.WriteFile(path, []byte(state), 0o644))
+
+	resources, err := readState(path, time.Now())
+	require.NoError(t, err)
+
+	var ids []string
+	for _, resource := range resources {
+		assert.NotEmpty(t, resource.AccountID)
+		ids = append(ids, resource.ID)
+	}
+	assert.Equal(t, []string{"i-1", "i-2"}, ids)
+}
+
+func TestReadState_UnsupportedVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 3}`), 0o644))
+
+	_, err := readState(path, time.Now())
+	assert.ErrorContains(t, err, "unsupported state format version 3")
+}
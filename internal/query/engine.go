@@ -1,8 +1,8 @@
 package query
 
 import (
-	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -12,6 +12,9 @@ import (
 type QueryEngine struct {
 	storage core.Storage
 	cache   core.Cache
+
+	// asOfRun scopes queries to the inventory of a discovery run, 0 for the latest
+	asOfRun int64
 }
 
 // NewEngine creates a new query engine
@@ -22,6 +25,40 @@ func NewEngine(storage core.Storage) *QueryEngine {
 	}
 }
 
+// AsOf returns an engine whose SQL queries see the resources table as it
+// was after a discovery run was stored
+func (e *QueryEngine) AsOf(runID int64) *QueryEngine {
+	return &QueryEngine{
+		storage: e.storage,
+		cache:   &memoryCache{},
+		asOfRun: runID,
+	}
+}
+
+// snapshotTable shadows the resources table with the resource versions
+// valid at a run; the columns follow the resources table
+const snapshotTable = `resources AS (
+	SELECT id, provider, account_id, region, service, type, name, arn,
+	       created_at, updated_at, tags, configuration, public_access,
+	       encrypted, monthly_cost, dependencies, discovered_at, discovery_method,
+	       compliance, dependents
+	FROM resource_versions
+	WHERE valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)
+)`
+
+// withClausePattern matches the WITH keyword starting a query
+var withClausePattern = regexp.MustCompile(`(?is)^\s*with(\s+recursive)?\s+`)
+
+// withSnapshot makes a query read the snapshot table. A statement has a
+// single WITH clause, so queries with their own common table expressions
+// get the snapshot table added in front of them.
+func withSnapshot(query string) string {
+	if clause := withClausePattern.FindString(query); clause != "" {
+		return clause + snapshotTable + ",\n" + query[len(clause):]
+	}
+	return "WITH " + snapshotTable + "\n" + query
+}
+
 // ExecuteSQL runs raw SQL queries
 func (e *QueryEngine) ExecuteSQL(query string, args ...interface{}) ([]core.Resource, error) {
 	// Add safety checks
@@ -29,65 +66,23 @@ func (e *QueryEngine) ExecuteSQL(query string, args ...interface{}) ([]core.Reso
 		return nil, err
 	}
 
+	// Point-in-time queries run against the snapshot of their run
+	if e.asOfRun != 0 {
+		query = withSnapshot(query)
+		args = append([]interface{}{e.asOfRun, e.asOfRun}, args...)
+	}
+
 	// Check cache
 	cacheKey := fmt.Sprintf("%s:%v", query, args)
 	if cached, ok := e.cache.Get(cacheKey); ok {
 		return cached.([]core.Resource), nil
 	}
 
-	// Execute query
-	rows, err := e.storage.Query(query, args...)
+	// Execute query; the storage decodes rows so every resource field is kept
+	resources, err := e.storage.GetResources(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query execution failed: %w", err)
 	}
-	defer rows.Close()
-
-	// Parse results
-	var resources []core.Resource
-	for rows.Next() {
-		var resource core.Resource
-		var tagsJSON, depsJSON string
-
-		err := rows.Scan(
-			&resource.ID,
-			&resource.Provider,
-			&resource.AccountID,
-			&resource.Region,
-			&resource.Service,
-			&resource.Type,
-			&resource.Name,
-			&resource.ARN,
-			&resource.CreatedAt,
-			&resource.UpdatedAt,
-			&tagsJSON,
-			&resource.Configuration,
-			&resource.PublicAccess,
-			&resource.Encrypted,
-			&resource.MonthlyCost,
-			&depsJSON,
-			&resource.DiscoveredAt,
-			&resource.DiscoveryMethod,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan resource: %w", err)
-		}
-
-		// Parse JSON fields
-		if tagsJSON != "" {
-			if err := json.Unmarshal([]byte(tagsJSON), &resource.Tags); err != nil {
-				// Log error but continue processing
-				_ = err
-			}
-		}
-		if depsJSON != "" {
-			if err := json.Unmarshal([]byte(depsJSON), &resource.Dependencies); err != nil {
-				// Log error but continue processing
-				_ = err
-			}
-		}
-
-		resources = append(resources, resource)
-	}
 
 	// Cache results
 	e.cache.Set(cacheKey, resources, 5*time.Minute)
@@ -168,6 +163,12 @@ func (e *QueryEngine) ExecuteTemplate(templateName string, params map[string]int
 	return e.ExecuteSQL(query)
 }
 
+// selectQueryPattern matches the start of a SELECT query
+var selectQueryPattern = regexp.MustCompile(`^\s*(select|with)\b`)
+
+// sqlWordPattern matches SQL keywords and identifiers
+var sqlWordPattern = regexp.MustCompile(`[a-z0-9_]+`)
+
 // validateQuery performs basic SQL validation
 func (e *QueryEngine) validateQuery(query string) error {
 	// Basic safety checks
@@ -179,14 +180,18 @@ func (e *QueryEngine) validateQuery(query string) error {
 		"exec", "execute", "sp_", "xp_", "cmdshell",
 	}
 
-	for _, op := range dangerousOps {
-		if strings.Contains(query, op) {
-			return fmt.Errorf("dangerous operation not allowed: %s", op)
+	// Match whole identifiers so columns and tables such as updated_at or
+	// deleted_resources stay queryable
+	for _, word := range sqlWordPattern.FindAllString(query, -1) {
+		for _, op := range dangerousOps {
+			if word == op || (strings.HasSuffix(op, "_") && strings.HasPrefix(word, op)) {
+				return fmt.Errorf("dangerous operation not allowed: %s", op)
+			}
 		}
 	}
 
-	// Must be a SELECT query
-	if !strings.HasPrefix(strings.TrimSpace(query), "select") {
+	// Must be a SELECT query, possibly with common table expressions
+	if !selectQueryPattern.MatchString(query) {
 		return fmt.Errorf("only SELECT queries are allowed")
 	}
 
@@ -269,6 +274,26 @@ func (e *QueryEngine) GetResourcesByRegion(region string) ([]core.Resource, erro
 	return e.ExecuteSQL(sqlQuery, region)
 }
 
+// GetResourcesUnderOrgNode returns the resources of every account below an
+// organization hierarchy node, such as an AWS OU, an Azure management group
+// or a GCP folder. The node is matched by ID or name.
+func (e *QueryEngine) GetResourcesUnderOrgNode(node string) ([]core.Resource, error) {
+	sqlQuery := `
+		SELECT * FROM resources
+		WHERE (provider, account_id) IN (
+			WITH RECURSIVE subtree(provider, id, kind) AS (
+				SELECT provider, id, kind FROM org_nodes WHERE id = ? OR name = ?
+				UNION
+				SELECT n.provider, n.id, n.kind FROM org_nodes n
+				JOIN subtree s ON n.provider = s.provider AND n.parent_id = s.id
+			)
+			SELECT provider, id FROM subtree WHERE kind = 'account'
+		)
+		ORDER BY account_id, name
+	`
+	return e.ExecuteSQL(sqlQuery, node, node)
+}
+
 // GetPublicResources returns all public resources
 func (e *QueryEngine) GetPublicResources() ([]core.Resource, error) {
 	return e.ExecuteTemplate("public_resources", nil)
@@ -0,0 +1,50 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSnapshot(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "plain query",
+			query:    "SELECT * FROM resources",
+			expected: "WITH " + snapshotTable + "\nSELECT * FROM resources",
+		},
+		{
+			name:     "query with its own table expressions",
+			query:    "WITH public AS (SELECT * FROM resources WHERE public_access) SELECT * FROM public",
+			expected: "WITH " + snapshotTable + ",\npublic AS (SELECT * FROM resources WHERE public_access) SELECT * FROM public",
+		},
+		{
+			name:     "recursive query",
+			query:    "\n  with recursive deps(id) AS (SELECT id FROM resources) SELECT * FROM deps",
+			expected: "\n  with recursive " + snapshotTable + ",\ndeps(id) AS (SELECT id FROM resources) SELECT * FROM deps",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := withSnapshot(tt.query)
+			assert.Equal(t, tt.expected, snapshot)
+			assert.Equal(t, 1, strings.Count(strings.ToLower(snapshot), "with "), "a statement has one WITH clause")
+		})
+	}
+}
+
+func TestValidateQuery(t *testing.T) {
+	e := &QueryEngine{}
+
+	assert.NoError(t, e.validateQuery("SELECT * FROM resources WHERE updated_at > '2024-01-01'"))
+	assert.NoError(t, e.validateQuery("  WITH public AS (SELECT * FROM resources) SELECT * FROM public"))
+	assert.Error(t, e.validateQuery("WITH gone AS (SELECT id FROM resources) DELETE FROM resources"))
+	assert.Error(t, e.validateQuery("PRAGMA table_info(resources)"))
+	assert.Error(t, e.validateQuery("without SELECT"))
+}
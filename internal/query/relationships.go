@@ -0,0 +1,128 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// Relationship helpers read the results of the last dependency analysis.
+// They always see the current inventory, even on engines scoped with AsOf.
+
+// relationships returns the relationship storage of the engine
+func (e *QueryEngine) relationships() (core.RelationshipStorage, error) {
+	store, ok := e.storage.(core.RelationshipStorage)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not keep resource relationships")
+	}
+	return store, nil
+}
+
+// GetRelationships returns the stored relationships, optionally of some types only
+func (e *QueryEngine) GetRelationships(types ...string) ([]core.Relationship, error) {
+	store, err := e.relationships()
+	if err != nil {
+		return nil, err
+	}
+	return store.GetRelationships(types...)
+}
+
+// GetNeighbors returns the resources directly related to a resource, as
+// either dependency or dependent
+func (e *QueryEngine) GetNeighbors(resourceID string, types ...string) ([]core.Resource, error) {
+	store, err := e.relationships()
+	if err != nil {
+		return nil, err
+	}
+
+	relationships, err := store.GetNeighbors(resourceID, types...)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, relationship := range relationships {
+		if relationship.SourceID == resourceID {
+			ids = append(ids, relationship.TargetID)
+		} else {
+			ids = append(ids, relationship.SourceID)
+		}
+	}
+	return e.resourcesByID(ids)
+}
+
+// GetUpstream returns the resources a resource depends on, directly or
+// transitively, nearest first
+func (e *QueryEngine) GetUpstream(resourceID string, types ...string) ([]core.Resource, error) {
+	store, err := e.relationships()
+	if err != nil {
+		return nil, err
+	}
+
+	related, err := store.GetUpstream(resourceID, types...)
+	if err != nil {
+		return nil, err
+	}
+	return e.resourcesByID(relatedIDs(related))
+}
+
+// GetDownstream returns the resources that depend on a resource, directly
+// or transitively, nearest first
+func (e *QueryEngine) GetDownstream(resourceID string, types ...string) ([]core.Resource, error) {
+	store, err := e.relationships()
+	if err != nil {
+		return nil, err
+	}
+
+	related, err := store.GetDownstream(resourceID, types...)
+	if err != nil {
+		return nil, err
+	}
+	return e.resourcesByID(relatedIDs(related))
+}
+
+// relatedIDs returns the IDs of related resources in order
+func relatedIDs(related []core.RelatedResource) []string {
+	ids := make([]string, 0, len(related))
+	for _, resource := range related {
+		ids = append(ids, resource.ResourceID)
+	}
+	return ids
+}
+
+// resourcesByID loads resources in the order of their IDs, skipping
+// duplicates and resources no longer in the inventory
+func (e *QueryEngine) resourcesByID(ids []string) ([]core.Resource, error) {
+	if len(ids) == 0 {
+		return []core.Resource{}, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	resources, err := e.storage.GetResources(fmt.Sprintf(
+		"SELECT * FROM resources WHERE id IN (%s)",
+		strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "),
+	), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query execution failed: %w", err)
+	}
+
+	byID := make(map[string]core.Resource, len(resources))
+	for _, resource := range resources {
+		byID[resource.ID] = resource
+	}
+
+	ordered := make([]core.Resource, 0, len(resources))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if resource, ok := byID[id]; ok && !seen[id] {
+			seen[id] = true
+			ordered = append(ordered, resource)
+		}
+	}
+	return ordered, nil
+}
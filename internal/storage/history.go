@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// recordChangeTx records how a resource changed since the previous run, in
+// resource_changes. A run that stores a resource more than once, first from
+// a checkpoint and then enriched, keeps a single change against the state
+// before the run.
+func (s *SQLiteStorage) recordChangeTx(tx *sql.Tx, runID int64, resource core.Resource) error {
+	previous, err := s.previousStateTx(tx, runID, resource.ID)
+	if err != nil {
+		return err
+	}
+
+	if runID != 0 {
+		if _, err := tx.Exec(
+			"DELETE FROM resource_changes WHERE resource_id = ? AND run_id = ?", resource.ID, runID,
+		); err != nil {
+			return fmt.Errorf("failed to replace change of %s: %w", resource.ID, err)
+		}
+	}
+
+	changeType := core.EventCreated
+	var oldConfiguration, fieldsJSON interface{}
+	if previous != nil {
+		fields := core.DiffResources(*previous, resource)
+		if len(fields) == 0 {
+			return nil
+		}
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return fmt.Errorf("failed to marshal changes of %s: %w", resource.ID, err)
+		}
+		changeType = core.EventUpdated
+		oldConfiguration = nullableJSON(previous.Configuration)
+		fieldsJSON = string(data)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO resource_changes (resource_id, run_id, change_type, changed_at, old_configuration, new_configuration, fields)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, resource.ID, runID, changeType, time.Now().UTC(), oldConfiguration, nullableJSON(resource.Configuration), fieldsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to record change of %s: %w", resource.ID, err)
+	}
+
+	return nil
+}
+
+// previousStateTx returns the state of a resource before a run, or nil for
+// a resource the run sees for the first time, or again after its deletion.
+// Outside a run the stored resource is the previous state.
+func (s *SQLiteStorage) previousStateTx(tx *sql.Tx, runID int64, resourceID string) (*core.Resource, error) {
+	query := fmt.Sprintf("SELECT %s FROM resources WHERE id = ?", strings.Join(resourceColumns, ", "))
+	args := []interface{}{resourceID}
+	if runID != 0 {
+		query = fmt.Sprintf(`
+			SELECT %s FROM resource_versions
+			WHERE id = ? AND valid_from < ? AND (valid_to IS NULL OR valid_to = ?)
+			ORDER BY valid_from DESC LIMIT 1
+		`, strings.Join(resourceColumns, ", "))
+		args = append(args, runID, runID)
+	}
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read previous state of %s: %w", resourceID, err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	resource, err := scanResource(rows)
+	if err != nil {
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// nullableJSON stores an empty document as NULL
+func nullableJSON(data json.RawMessage) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+// GetResourceHistory returns the timeline of a resource: when it was first
+// seen, each change and its deletion. Changes recorded before field-level
+// tracking only carry their configuration, which is diffed on read.
+func (s *SQLiteStorage) GetResourceHistory(resourceID string) (*core.ResourceHistory, error) {
+	history := &core.ResourceHistory{ResourceID: resourceID, Events: []core.ResourceEvent{}}
+
+	// Latest known state, live or tombstoned
+	for _, table := range []string{"resources", "deleted_resources"} {
+		resources, err := s.GetResources(
+			fmt.Sprintf("SELECT %s FROM %s WHERE id = ?", strings.Join(resourceColumns, ", "), table), resourceID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if len(resources) > 0 {
+			history.Resource = &resources[0]
+			history.Deleted = table == "deleted_resources"
+			break
+		}
+	}
+
+	rows, err := s.db.Query(`
+		SELECT run_id, change_type, changed_at, old_configuration, new_configuration, fields
+		FROM resource_changes WHERE resource_id = ? ORDER BY id
+	`, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query resource changes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event core.ResourceEvent
+		var runID sql.NullInt64
+		var changedAt sql.NullTime
+		var oldConfiguration, newConfiguration, fieldsJSON sql.NullString
+		if err := rows.Scan(&runID, &event.Change, &changedAt, &oldConfiguration, &newConfiguration, &fieldsJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan resource change: %w", err)
+		}
+		event.RunID = runID.Int64
+		event.Time = changedAt.Time
+		if oldConfiguration.String != "" {
+			event.OldConfiguration = json.RawMessage(oldConfiguration.String)
+		}
+		if newConfiguration.String != "" {
+			event.NewConfiguration = json.RawMessage(newConfiguration.String)
+		}
+
+		switch {
+		case fieldsJSON.String != "":
+			if err := json.Unmarshal([]byte(fieldsJSON.String), &event.Fields); err != nil {
+				return nil, fmt.Errorf("failed to parse changed fields: %w", err)
+			}
+		case event.Change == core.EventUpdated:
+			event.Fields = core.DiffResources(
+				core.Resource{Configuration: event.OldConfiguration},
+				core.Resource{Configuration: event.NewConfiguration},
+			)
+		}
+
+		switch event.Change {
+		case core.EventCreated:
+			if history.FirstSeen.IsZero() {
+				history.FirstSeen = event.Time
+			}
+			history.DeletedAt = nil
+		case core.EventDeleted:
+			deletedAt := event.Time
+			history.DeletedAt = &deletedAt
+		}
+
+		history.Events = append(history.Events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if history.Resource == nil && len(history.Events) == 0 {
+		return nil, fmt.Errorf("resource %s not found", resourceID)
+	}
+
+	return history, nil
+}
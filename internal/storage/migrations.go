@@ -0,0 +1,390 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSchemaTooNew is returned when a database was written by a newer
+// version of CloudRecon than the one opening it
+var ErrSchemaTooNew = errors.New("database schema is newer than this version of cloudrecon supports")
+
+// Migration is a versioned schema change
+type Migration struct {
+	Version     int
+	Description string
+	AppliedAt   time.Time // Zero while the migration is pending
+}
+
+// migration is a schema change applied in its own transaction
+type migration struct {
+	version     int
+	description string
+	statements  string
+}
+
+// migrations are applied in order and must never be edited once released;
+// schema changes are made by appending a new migration. The first ones use
+// IF NOT EXISTS so databases created before versioning adopt them as is.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "initial schema",
+		statements: `
+		CREATE TABLE IF NOT EXISTS resources (
+			id TEXT PRIMARY KEY,
+			provider TEXT NOT NULL,
+			account_id TEXT NOT NULL,
+			region TEXT,
+			service TEXT NOT NULL,
+			type TEXT NOT NULL,
+			name TEXT,
+			arn TEXT,
+			created_at DATETIME,
+			updated_at DATETIME,
+			tags TEXT,
+			configuration TEXT,
+			public_access BOOLEAN DEFAULT FALSE,
+			encrypted BOOLEAN DEFAULT FALSE,
+			monthly_cost REAL DEFAULT 0,
+			dependencies TEXT,
+			discovered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			discovery_method TEXT,
+			UNIQUE(provider, account_id, id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_provider ON resources(provider);
+		CREATE INDEX IF NOT EXISTS idx_account ON resources(account_id);
+		CREATE INDEX IF NOT EXISTS idx_type ON resources(type);
+		CREATE INDEX IF NOT EXISTS idx_public ON resources(public_access);
+		CREATE INDEX IF NOT EXISTS idx_cost ON resources(monthly_cost);
+		CREATE INDEX IF NOT EXISTS idx_service ON resources(service);
+		CREATE INDEX IF NOT EXISTS idx_region ON resources(region);
+
+		-- Tombstones: resources that a fully covered scope no longer returned.
+		-- Same columns as resources; the deletion time is in resource_changes.
+		CREATE TABLE IF NOT EXISTS deleted_resources (
+			id TEXT PRIMARY KEY,
+			provider TEXT NOT NULL,
+			account_id TEXT NOT NULL,
+			region TEXT,
+			service TEXT NOT NULL,
+			type TEXT NOT NULL,
+			name TEXT,
+			arn TEXT,
+			created_at DATETIME,
+			updated_at DATETIME,
+			tags TEXT,
+			configuration TEXT,
+			public_access BOOLEAN DEFAULT FALSE,
+			encrypted BOOLEAN DEFAULT FALSE,
+			monthly_cost REAL DEFAULT 0,
+			dependencies TEXT,
+			discovered_at DATETIME,
+			discovery_method TEXT
+		);
+
+		CREATE TABLE IF NOT EXISTS discovery_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			started_at DATETIME NOT NULL,
+			completed_at DATETIME,
+			resource_count INTEGER DEFAULT 0,
+			providers TEXT,
+			mode TEXT,
+			status TEXT,
+			errors TEXT
+		);
+
+		CREATE TABLE IF NOT EXISTS discovery_checkpoints (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id INTEGER NOT NULL,
+			provider TEXT NOT NULL,
+			account_id TEXT NOT NULL,
+			region TEXT NOT NULL DEFAULT '', -- empty for a whole account
+			resource_count INTEGER DEFAULT 0,
+			resource_ids TEXT,
+			completed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(run_id, provider, account_id, region),
+			FOREIGN KEY(run_id) REFERENCES discovery_runs(id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_checkpoint_run ON discovery_checkpoints(run_id);
+
+		CREATE TABLE IF NOT EXISTS resource_changes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			resource_id TEXT NOT NULL,
+			change_type TEXT NOT NULL, -- created, updated, deleted
+			changed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			old_configuration TEXT,
+			new_configuration TEXT,
+			FOREIGN KEY(resource_id) REFERENCES resources(id)
+		);
+
+		CREATE TABLE IF NOT EXISTS resource_relationships (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			source_id TEXT NOT NULL,
+			target_id TEXT NOT NULL,
+			relationship TEXT NOT NULL,
+			weight INTEGER DEFAULT 1,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY(source_id) REFERENCES resources(id),
+			FOREIGN KEY(target_id) REFERENCES resources(id)
+		);
+		`,
+	},
+	{
+		version:     2,
+		description: "organization hierarchy",
+		statements: `
+		-- Organization hierarchy: AWS organizations and OUs, Azure management
+		-- groups, GCP organizations and folders, with accounts as leaves
+		CREATE TABLE IF NOT EXISTS org_nodes (
+			provider TEXT NOT NULL,
+			id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			name TEXT,
+			parent_id TEXT, -- NULL for roots
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY(provider, id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_org_parent ON org_nodes(provider, parent_id);
+		`,
+	},
+	{
+		version:     3,
+		description: "resource compliance and dependents",
+		statements: `
+		ALTER TABLE resources ADD COLUMN compliance TEXT;
+		ALTER TABLE resources ADD COLUMN dependents TEXT;
+		ALTER TABLE deleted_resources ADD COLUMN compliance TEXT;
+		ALTER TABLE deleted_resources ADD COLUMN dependents TEXT;
+		`,
+	},
+	{
+		version:     4,
+		description: "per-run resource versions",
+		statements: `
+		-- Resource columns follow the resources table so versions scan alike
+		CREATE TABLE resource_versions (
+			version_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			valid_from INTEGER NOT NULL, -- run that stored the version
+			valid_to INTEGER,            -- run that changed or deleted it, NULL while current
+			content_hash TEXT,
+			id TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			account_id TEXT NOT NULL,
+			region TEXT,
+			service TEXT NOT NULL,
+			type TEXT NOT NULL,
+			name TEXT,
+			arn TEXT,
+			created_at DATETIME,
+			updated_at DATETIME,
+			tags TEXT,
+			configuration TEXT,
+			public_access BOOLEAN DEFAULT FALSE,
+			encrypted BOOLEAN DEFAULT FALSE,
+			monthly_cost REAL DEFAULT 0,
+			dependencies TEXT,
+			discovered_at DATETIME,
+			discovery_method TEXT,
+			compliance TEXT,
+			dependents TEXT
+		);
+
+		CREATE INDEX idx_versions_resource ON resource_versions(id, valid_to);
+		CREATE INDEX idx_versions_window ON resource_versions(valid_from, valid_to);
+
+		-- History starts with the current inventory as of the latest run
+		INSERT INTO resource_versions (
+			valid_from, id, provider, account_id, region, service, type, name, arn,
+			created_at, updated_at, tags, configuration, public_access, encrypted,
+			monthly_cost, dependencies, discovered_at, discovery_method, compliance, dependents
+		)
+		SELECT
+			COALESCE((SELECT MAX(id) FROM discovery_runs), 0), id, provider, account_id, region,
+			service, type, name, arn, created_at, updated_at, tags, configuration, public_access,
+			encrypted, monthly_cost, dependencies, discovered_at, discovery_method, compliance, dependents
+		FROM resources;
+		`,
+	},
+	{
+		version:     5,
+		description: "resource change runs and fields",
+		statements: `
+		-- Changes recorded before this migration have neither
+		ALTER TABLE resource_changes ADD COLUMN run_id INTEGER;
+		ALTER TABLE resource_changes ADD COLUMN fields TEXT; -- JSON list of changed fields
+
+		CREATE INDEX idx_changes_resource ON resource_changes(resource_id, run_id);
+		`,
+	},
+	{
+		version:     6,
+		description: "dependency analysis relationships",
+		statements: `
+		ALTER TABLE resource_relationships ADD COLUMN direction TEXT NOT NULL DEFAULT 'outbound';
+		ALTER TABLE resource_relationships ADD COLUMN confidence REAL NOT NULL DEFAULT 1;
+		ALTER TABLE resource_relationships ADD COLUMN run_id INTEGER; -- run whose inventory was analyzed
+		ALTER TABLE resource_relationships ADD COLUMN metadata TEXT;
+
+		CREATE INDEX idx_relationships_source ON resource_relationships(source_id, relationship);
+		CREATE INDEX idx_relationships_target ON resource_relationships(target_id, relationship);
+		`,
+	},
+}
+
+// LatestSchemaVersion is the schema version this build migrates databases to
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// ensureMigrationsTable creates the table tracking applied migrations
+func (s *SQLiteStorage) ensureMigrationsTable() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// hasMigrationsTable reports whether the database tracks applied migrations
+func (s *SQLiteStorage) hasMigrationsTable() (bool, error) {
+	var count int
+	err := s.db.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'",
+	).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up schema_migrations table: %w", err)
+	}
+	return count > 0, nil
+}
+
+// SchemaVersion returns the version of the most recent migration applied
+// to the database, or 0 for a database that is empty or predates
+// versioning. The database is only read.
+func (s *SQLiteStorage) SchemaVersion() (int, error) {
+	exists, err := s.hasMigrationsTable()
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	var version sql.NullInt64
+	if err := s.db.QueryRow("SELECT MAX(version) FROM schema_migrations").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
+
+// Migrations lists every migration known to this build with the time it
+// was applied to the database. The database is only read.
+func (s *SQLiteStorage) Migrations() ([]Migration, error) {
+	exists, err := s.hasMigrationsTable()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]time.Time)
+	if exists {
+		rows, err := s.db.Query("SELECT version, applied_at FROM schema_migrations")
+		if err != nil {
+			return nil, fmt.Errorf("failed to query schema migrations: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var version int
+			var appliedAt time.Time
+			if err := rows.Scan(&version, &appliedAt); err != nil {
+				return nil, fmt.Errorf("failed to scan schema migration: %w", err)
+			}
+			applied[version] = appliedAt
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]Migration, 0, len(migrations))
+	for _, m := range migrations {
+		result = append(result, Migration{
+			Version:     m.version,
+			Description: m.description,
+			AppliedAt:   applied[m.version],
+		})
+	}
+	return result, nil
+}
+
+// Migrate applies pending migrations in order and returns the ones applied.
+// Each migration runs in its own transaction, so a failure leaves the
+// database at the last successfully applied version. Databases from a
+// newer version are refused with ErrSchemaTooNew rather than modified.
+func (s *SQLiteStorage) Migrate() ([]Migration, error) {
+	if err := s.ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if latest := LatestSchemaVersion(); current > latest {
+		return nil, fmt.Errorf("%w: database is at version %d, latest known version is %d", ErrSchemaTooNew, current, latest)
+	}
+
+	var applied []Migration
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		appliedAt, err := s.applyMigration(m)
+		if err != nil {
+			return applied, err
+		}
+		applied = append(applied, Migration{Version: m.version, Description: m.description, AppliedAt: appliedAt})
+	}
+
+	return applied, nil
+}
+
+// applyMigration runs a migration and records it
+func (s *SQLiteStorage) applyMigration(m migration) (time.Time, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			// Log rollback error but don't fail the operation
+			_ = rollbackErr
+		}
+	}()
+
+	if _, err := tx.Exec(m.statements); err != nil {
+		return time.Time{}, fmt.Errorf("failed to apply migration %d (%s): %w", m.version, m.description, err)
+	}
+
+	appliedAt := time.Now().UTC()
+	if _, err := tx.Exec(
+		"INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)",
+		m.version, m.description, appliedAt,
+	); err != nil {
+		return time.Time{}, fmt.Errorf("failed to record migration %d: %w", m.version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("failed to commit migration %d: %w", m.version, err)
+	}
+	return appliedAt, nil
+}
@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// maxRelationshipDepth bounds transitive lookups so dependency cycles end
+const maxRelationshipDepth = 32
+
+// StoreRelationships replaces the stored relationships with the results of
+// a dependency analysis of the current inventory. They are recorded against
+// the latest completed discovery run.
+func (s *SQLiteStorage) StoreRelationships(relationships []core.Relationship) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			// Log rollback error but don't fail the operation
+			_ = rollbackErr
+		}
+	}()
+
+	runID, err := latestRun(tx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM resource_relationships"); err != nil {
+		return fmt.Errorf("failed to clear relationships: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO resource_relationships (source_id, target_id, relationship, direction, confidence, run_id, metadata, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare relationship statement: %w", err)
+	}
+	defer stmt.Close()
+
+	createdAt := time.Now().UTC()
+	for _, relationship := range relationships {
+		direction := relationship.Direction
+		if direction == "" {
+			direction = core.DirectionOutbound
+		}
+
+		var metadata interface{}
+		if len(relationship.Metadata) > 0 {
+			data, err := json.Marshal(relationship.Metadata)
+			if err != nil {
+				return fmt.Errorf("failed to marshal relationship metadata: %w", err)
+			}
+			metadata = string(data)
+		}
+
+		if _, err := stmt.Exec(
+			relationship.SourceID, relationship.TargetID, relationship.Type, direction,
+			relationship.Confidence, runID, metadata, createdAt,
+		); err != nil {
+			return fmt.Errorf("failed to store relationship %s -> %s: %w", relationship.SourceID, relationship.TargetID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// latestRun returns the latest completed discovery run, 0 if there is none
+func latestRun(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) (int64, error) {
+	var runID int64
+	err := q.QueryRow("SELECT COALESCE(MAX(id), 0) FROM discovery_runs WHERE completed_at IS NOT NULL").Scan(&runID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest discovery run: %w", err)
+	}
+	return runID, nil
+}
+
+// RelationshipsCurrent reports whether relationships are stored and were
+// derived from the latest completed discovery run
+func (s *SQLiteStorage) RelationshipsCurrent() (bool, error) {
+	var count int
+	var runID sql.NullInt64
+	err := s.db.QueryRow("SELECT COUNT(*), MIN(run_id) FROM resource_relationships").Scan(&count, &runID)
+	if err != nil {
+		return false, fmt.Errorf("failed to query relationships: %w", err)
+	}
+	if count == 0 {
+		return false, nil
+	}
+
+	latest, err := latestRun(s.db)
+	if err != nil {
+		return false, err
+	}
+	return runID.Int64 == latest, nil
+}
+
+// GetRelationships returns the stored relationships
+func (s *SQLiteStorage) GetRelationships(types ...string) ([]core.Relationship, error) {
+	filter, args := relationshipTypeFilter(types)
+	return s.queryRelationships(fmt.Sprintf(`
+		SELECT source_id, target_id, relationship, direction, confidence, run_id, metadata
+		FROM resource_relationships WHERE 1 = 1 %s
+		ORDER BY source_id, target_id, relationship
+	`, filter), args...)
+}
+
+// GetNeighbors returns the relationships a resource takes part in, either
+// as source or as target
+func (s *SQLiteStorage) GetNeighbors(resourceID string, types ...string) ([]core.Relationship, error) {
+	filter, args := relationshipTypeFilter(types)
+	return s.queryRelationships(fmt.Sprintf(`
+		SELECT source_id, target_id, relationship, direction, confidence, run_id, metadata
+		FROM resource_relationships WHERE (source_id = ? OR target_id = ?) %s
+		ORDER BY source_id, target_id, relationship
+	`, filter), append([]interface{}{resourceID, resourceID}, args...)...)
+}
+
+// GetUpstream returns the resources a resource depends on, directly or
+// transitively, nearest first
+func (s *SQLiteStorage) GetUpstream(resourceID string, types ...string) ([]core.RelatedResource, error) {
+	return s.walkRelationships(resourceID, false, types)
+}
+
+// GetDownstream returns the resources that depend on a resource, directly
+// or transitively, nearest first
+func (s *SQLiteStorage) GetDownstream(resourceID string, types ...string) ([]core.RelatedResource, error) {
+	return s.walkRelationships(resourceID, true, types)
+}
+
+// walkRelationships follows dependencies from a resource. Relationships are
+// first turned into edges from the dependent resource to its dependency;
+// downstream walks follow the edges backwards.
+func (s *SQLiteStorage) walkRelationships(resourceID string, downstream bool, types []string) ([]core.RelatedResource, error) {
+	filter, filterArgs := relationshipTypeFilter(types)
+
+	from, to := "from_id", "to_id"
+	if downstream {
+		from, to = to, from
+	}
+
+	query := fmt.Sprintf(`
+		WITH RECURSIVE edges(from_id, to_id, relationship) AS (
+			SELECT source_id, target_id, relationship FROM resource_relationships
+			WHERE direction != 'inbound' %[1]s
+			UNION ALL
+			SELECT target_id, source_id, relationship FROM resource_relationships
+			WHERE direction IN ('inbound', 'bidirectional') %[1]s
+		),
+		walk(id, depth, relationship) AS (
+			SELECT %[3]s, 1, relationship FROM edges WHERE %[2]s = ?
+			UNION
+			SELECT e.%[3]s, w.depth + 1, e.relationship FROM edges e
+			JOIN walk w ON e.%[2]s = w.id
+			WHERE w.depth < ?
+		)
+		SELECT id, MIN(depth), relationship FROM walk
+		WHERE id != ?
+		GROUP BY id
+		ORDER BY MIN(depth), id
+	`, filter, from, to)
+
+	args := append(append([]interface{}{}, filterArgs...), filterArgs...)
+	args = append(args, resourceID, maxRelationshipDepth, resourceID)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk relationships: %w", err)
+	}
+	defer rows.Close()
+
+	related := []core.RelatedResource{}
+	for rows.Next() {
+		var resource core.RelatedResource
+		if err := rows.Scan(&resource.ResourceID, &resource.Depth, &resource.Relationship); err != nil {
+			return nil, fmt.Errorf("failed to scan related resource: %w", err)
+		}
+		related = append(related, resource)
+	}
+
+	return related, rows.Err()
+}
+
+// relationshipTypeFilter restricts a relationship query to some types
+func relationshipTypeFilter(types []string) (string, []interface{}) {
+	if len(types) == 0 {
+		return "", nil
+	}
+
+	args := make([]interface{}, len(types))
+	for i, t := range types {
+		args[i] = t
+	}
+	return fmt.Sprintf("AND relationship IN (%s)", strings.TrimSuffix(strings.Repeat("?, ", len(types)), ", ")), args
+}
+
+// queryRelationships scans relationships from a query
+func (s *SQLiteStorage) queryRelationships(query string, args ...interface{}) ([]core.Relationship, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relationships: %w", err)
+	}
+	defer rows.Close()
+
+	relationships := []core.Relationship{}
+	for rows.Next() {
+		var relationship core.Relationship
+		var runID sql.NullInt64
+		var metadata sql.NullString
+		if err := rows.Scan(
+			&relationship.SourceID, &relationship.TargetID, &relationship.Type, &relationship.Direction,
+			&relationship.Confidence, &runID, &metadata,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan relationship: %w", err)
+		}
+		relationship.RunID = runID.Int64
+		if metadata.String != "" {
+			if err := json.Unmarshal([]byte(metadata.String), &relationship.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to parse relationship metadata: %w", err)
+			}
+		}
+		relationships = append(relationships, relationship)
+	}
+
+	return relationships, rows.Err()
+}
@@ -5,6 +5,10 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,89 +20,62 @@ type SQLiteStorage struct {
 	db *sql.DB
 }
 
-// NewSQLiteStorage creates a new SQLite storage instance
+// NewSQLiteStorage creates a new SQLite storage instance, migrating the
+// database schema to the latest version
 func NewSQLiteStorage(databasePath string) (*SQLiteStorage, error) {
-	db, err := sql.Open("sqlite", databasePath)
+	storage, err := OpenSQLiteStorage(databasePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
 
-	storage := &SQLiteStorage{db: db}
 	if err := storage.Initialize(); err != nil {
-		_ = db.Close()
+		_ = storage.Close()
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
 
 	return storage, nil
 }
 
-// Initialize creates tables and indexes
+// OpenSQLiteStorage opens a database without migrating its schema
+func OpenSQLiteStorage(databasePath string) (*SQLiteStorage, error) {
+	db, err := sql.Open("sqlite", databasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	return &SQLiteStorage{db: db}, nil
+}
+
+// OpenSQLiteStorageReadOnly opens an existing database for reading only,
+// so neither the file nor its schema are created. A missing database fails
+// with an error wrapping os.ErrNotExist.
+func OpenSQLiteStorageReadOnly(databasePath string) (*SQLiteStorage, error) {
+	if _, err := os.Stat(databasePath); err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Read-only mode needs a URI, which takes absolute paths
+	path, err := filepath.Abs(databasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows drive paths, file:///C:/...
+		path = "/" + path
+	}
+	dsn := (&url.URL{Scheme: "file", Path: path, RawQuery: "mode=ro"}).String()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	return &SQLiteStorage{db: db}, nil
+}
+
+// Initialize brings the schema up to date by applying pending migrations
 func (s *SQLiteStorage) Initialize() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS resources (
-		id TEXT PRIMARY KEY,
-		provider TEXT NOT NULL,
-		account_id TEXT NOT NULL,
-		region TEXT,
-		service TEXT NOT NULL,
-		type TEXT NOT NULL,
-		name TEXT,
-		arn TEXT,
-		created_at DATETIME,
-		updated_at DATETIME,
-		tags TEXT,
-		configuration TEXT,
-		public_access BOOLEAN DEFAULT FALSE,
-		encrypted BOOLEAN DEFAULT FALSE,
-		monthly_cost REAL DEFAULT 0,
-		dependencies TEXT,
-		discovered_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		discovery_method TEXT,
-		UNIQUE(provider, account_id, id)
-	);
-	
-	CREATE INDEX IF NOT EXISTS idx_provider ON resources(provider);
-	CREATE INDEX IF NOT EXISTS idx_account ON resources(account_id);
-	CREATE INDEX IF NOT EXISTS idx_type ON resources(type);
-	CREATE INDEX IF NOT EXISTS idx_public ON resources(public_access);
-	CREATE INDEX IF NOT EXISTS idx_cost ON resources(monthly_cost);
-	CREATE INDEX IF NOT EXISTS idx_service ON resources(service);
-	CREATE INDEX IF NOT EXISTS idx_region ON resources(region);
-	
-	CREATE TABLE IF NOT EXISTS discovery_runs (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		started_at DATETIME NOT NULL,
-		completed_at DATETIME,
-		resource_count INTEGER DEFAULT 0,
-		providers TEXT,
-		mode TEXT,
-		status TEXT,
-		errors TEXT
-	);
-	
-	CREATE TABLE IF NOT EXISTS resource_changes (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		resource_id TEXT NOT NULL,
-		change_type TEXT NOT NULL, -- created, updated, deleted
-		changed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		old_configuration TEXT,
-		new_configuration TEXT,
-		FOREIGN KEY(resource_id) REFERENCES resources(id)
-	);
-	
-	CREATE TABLE IF NOT EXISTS resource_relationships (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		source_id TEXT NOT NULL,
-		target_id TEXT NOT NULL,
-		relationship TEXT NOT NULL,
-		weight INTEGER DEFAULT 1,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY(source_id) REFERENCES resources(id),
-		FOREIGN KEY(target_id) REFERENCES resources(id)
-	);
-	`
-
-	_, err := s.db.Exec(schema)
+	_, err := s.Migrate()
 	return err
 }
 
@@ -119,30 +96,9 @@ func (s *SQLiteStorage) StoreResources(ctx context.Context, resources []core.Res
 		}
 	}()
 
-	stmt, err := tx.PrepareContext(ctx, `
-		INSERT OR REPLACE INTO resources (
-			id, provider, account_id, region, service, type, name, arn,
-			created_at, updated_at, discovered_at, discovery_method,
-			tags, configuration, public_access, encrypted, monthly_cost
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
-	}
-	defer stmt.Close()
-
-	for _, resource := range resources {
-		tagsJSON, _ := json.Marshal(resource.Tags)
-		_, err := stmt.ExecContext(ctx,
-			resource.ID, resource.Provider, resource.AccountID, resource.Region,
-			resource.Service, resource.Type, resource.Name, resource.ARN,
-			resource.CreatedAt, resource.UpdatedAt, resource.DiscoveredAt,
-			resource.DiscoveryMethod, string(tagsJSON), string(resource.Configuration),
-			resource.PublicAccess, resource.Encrypted, resource.MonthlyCost,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to insert resource %s: %w", resource.ID, err)
-		}
+	// Resources stored outside a discovery run are not versioned
+	if err := s.storeResourcesTx(tx, 0, resources); err != nil {
+		return err
 	}
 
 	return tx.Commit()
@@ -166,89 +122,541 @@ func (s *SQLiteStorage) StoreDiscovery(result *core.DiscoveryResult) error {
 
 	errorsJSON, _ := json.Marshal(result.Errors)
 
-	runResult, err := tx.Exec(`
-		INSERT INTO discovery_runs (started_at, completed_at, resource_count, providers, mode, status, errors)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, result.StartTime, result.EndTime, len(result.Resources),
-		strings.Join(providers, ","), result.Mode, "completed", string(errorsJSON))
+	status := result.Status
+	if status == "" {
+		status = core.RunStatusCompleted
+	}
 
-	if err != nil {
-		return fmt.Errorf("failed to insert discovery run: %w", err)
+	// Runs are stored as they finish; the completion time resolves
+	// point-in-time queries to a run
+	completedAt := result.EndTime
+	if completedAt.IsZero() {
+		completedAt = time.Now()
+	}
+
+	runID := result.RunID
+	if runID != 0 {
+		// The run was registered up front by CreateRun
+		_, err = tx.Exec(`
+			UPDATE discovery_runs
+			SET completed_at = ?, resource_count = ?, providers = ?, mode = ?, status = ?, errors = ?
+			WHERE id = ?
+		`, completedAt.UTC(), len(result.Resources), strings.Join(providers, ","), result.Mode,
+			status, string(errorsJSON), runID)
+		if err != nil {
+			return fmt.Errorf("failed to update discovery run: %w", err)
+		}
+	} else {
+		runResult, err := tx.Exec(`
+			INSERT INTO discovery_runs (started_at, completed_at, resource_count, providers, mode, status, errors)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, result.StartTime.UTC(), completedAt.UTC(), len(result.Resources),
+			strings.Join(providers, ","), result.Mode, status, string(errorsJSON))
+
+		if err != nil {
+			return fmt.Errorf("failed to insert discovery run: %w", err)
+		}
+
+		if runID, err = runResult.LastInsertId(); err != nil {
+			return fmt.Errorf("failed to get discovery run ID: %w", err)
+		}
+	}
+
+	if err := s.storeResourcesTx(tx, runID, result.Resources); err != nil {
+		return err
+	}
+
+	if err := s.storeOrgNodesTx(tx, core.OrgNodes(result.Accounts)); err != nil {
+		return err
+	}
+
+	if err := s.tombstoneResourcesTx(tx, runID, result); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// tombstoneResourcesTx moves stored resources that a fully covered scope no
+// longer returned into deleted_resources and records a deleted change.
+// Scopes that were skipped, failed or only partially listed are never touched.
+func (s *SQLiteStorage) tombstoneResourcesTx(tx *sql.Tx, runID int64, result *core.DiscoveryResult) error {
+	if len(result.CoveredScopes) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(result.Resources))
+	for _, resource := range result.Resources {
+		seen[resource.ID] = true
+	}
+
+	type tombstone struct {
+		id            string
+		configuration sql.NullString
+	}
+
+	for _, scope := range result.CoveredScopes {
+		rows, err := tx.Query(`
+			SELECT id, configuration FROM resources
+			WHERE provider = ? AND account_id = ? AND region = ? AND service = ?
+		`, scope.Provider, scope.AccountID, scope.Region, scope.Service)
+		if err != nil {
+			return fmt.Errorf("failed to query resources in scope: %w", err)
+		}
+
+		var missing []tombstone
+		for rows.Next() {
+			var t tombstone
+			if err := rows.Scan(&t.id, &t.configuration); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan resource: %w", err)
+			}
+			if !seen[t.id] {
+				missing = append(missing, t)
+			}
+		}
+		rows.Close()
+
+		for _, t := range missing {
+			if _, err := tx.Exec("INSERT OR REPLACE INTO deleted_resources SELECT * FROM resources WHERE id = ?", t.id); err != nil {
+				return fmt.Errorf("failed to tombstone resource %s: %w", t.id, err)
+			}
+			if _, err := tx.Exec("DELETE FROM resources WHERE id = ?", t.id); err != nil {
+				return fmt.Errorf("failed to remove resource %s: %w", t.id, err)
+			}
+			if _, err := tx.Exec(`
+				INSERT INTO resource_changes (resource_id, run_id, change_type, changed_at, old_configuration, new_configuration)
+				VALUES (?, ?, ?, ?, ?, NULL)
+			`, t.id, runID, core.EventDeleted, time.Now().UTC(), t.configuration); err != nil {
+				return fmt.Errorf("failed to record deletion of %s: %w", t.id, err)
+			}
+			if _, err := tx.Exec(
+				"UPDATE resource_versions SET valid_to = ? WHERE id = ? AND valid_to IS NULL", runID, t.id,
+			); err != nil {
+				return fmt.Errorf("failed to close version of %s: %w", t.id, err)
+			}
+		}
 	}
 
-	_, _ = runResult.LastInsertId()
+	return nil
+}
 
+// storeResourcesTx upserts resources, records their changes and versions
+// the resources under a discovery run. Without a run, versions are left
+// untouched since point-in-time queries resolve to runs.
+func (s *SQLiteStorage) storeResourcesTx(tx *sql.Tx, runID int64, resources []core.Resource) error {
 	// Prepare statements for efficiency
-	insertStmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO resources (
-			id, provider, account_id, region, service, type, name, arn,
-			created_at, updated_at, tags, configuration, public_access,
-			encrypted, monthly_cost, dependencies, discovered_at, discovery_method
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`)
+	insertStmt, err := tx.Prepare(fmt.Sprintf(
+		"INSERT OR REPLACE INTO resources (%s) VALUES (%s)",
+		strings.Join(resourceColumns, ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(resourceColumns)), ", "),
+	))
 	if err != nil {
 		return fmt.Errorf("failed to prepare insert statement: %w", err)
 	}
 	defer insertStmt.Close()
 
-	changeStmt, err := tx.Prepare(`
-		INSERT INTO resource_changes (resource_id, change_type, old_configuration, new_configuration)
-		VALUES (?, ?, ?, ?)
+	// Process each resource
+	for _, resource := range resources {
+		if err := s.recordChangeTx(tx, runID, resource); err != nil {
+			return err
+		}
+
+		// A tombstoned resource that shows up again is live once more
+		if _, err := tx.Exec("DELETE FROM deleted_resources WHERE id = ?", resource.ID); err != nil {
+			return fmt.Errorf("failed to restore resource %s: %w", resource.ID, err)
+		}
+
+		// Store resource
+		_, err = insertStmt.Exec(resourceValues(resource)...)
+		if err != nil {
+			return fmt.Errorf("failed to store resource %s: %w", resource.ID, err)
+		}
+
+		if runID == 0 {
+			continue
+		}
+		if err := s.storeVersionTx(tx, runID, resource); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StoreOrgNodes stores organization hierarchy nodes. Nodes are keyed by
+// provider and ID, so an account moved to another OU is re-parented.
+func (s *SQLiteStorage) StoreOrgNodes(nodes []core.OrgNode) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			// Log rollback error but don't fail the operation
+			_ = rollbackErr
+		}
+	}()
+
+	if err := s.storeOrgNodesTx(tx, nodes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// storeOrgNodesTx upserts organization hierarchy nodes
+func (s *SQLiteStorage) storeOrgNodesTx(tx *sql.Tx, nodes []core.OrgNode) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT OR REPLACE INTO org_nodes (provider, id, kind, name, parent_id, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
-		return fmt.Errorf("failed to prepare change statement: %w", err)
+		return fmt.Errorf("failed to prepare org node statement: %w", err)
 	}
-	defer changeStmt.Close()
+	defer stmt.Close()
 
-	// Process each resource
-	for _, resource := range result.Resources {
-		// Check if resource exists
-		var existingConfig string
-		err := tx.QueryRow("SELECT configuration FROM resources WHERE id = ?", resource.ID).
-			Scan(&existingConfig)
+	now := time.Now()
+	for _, node := range nodes {
+		parentID := sql.NullString{String: node.ParentID, Valid: node.ParentID != ""}
+		if _, err := stmt.Exec(node.Provider, node.ID, node.Kind, node.Name, parentID, now); err != nil {
+			return fmt.Errorf("failed to store org node %s: %w", node.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// GetOrgNodes returns the organization hierarchy nodes of a provider, or of
+// every provider when provider is empty
+func (s *SQLiteStorage) GetOrgNodes(provider string) ([]core.OrgNode, error) {
+	query := "SELECT provider, id, kind, name, parent_id FROM org_nodes"
+	var args []interface{}
+	if provider != "" {
+		query += " WHERE provider = ?"
+		args = append(args, provider)
+	}
+	query += " ORDER BY provider, id"
 
-		changeType := "created"
-		if err == nil {
-			changeType = "updated"
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query org nodes: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []core.OrgNode
+	for rows.Next() {
+		var node core.OrgNode
+		var name, parentID sql.NullString
+		if err := rows.Scan(&node.Provider, &node.ID, &node.Kind, &name, &parentID); err != nil {
+			return nil, fmt.Errorf("failed to scan org node: %w", err)
 		}
-		// Detect actual changes
-		if err == nil && existingConfig != string(resource.Configuration) {
-			_, _ = changeStmt.Exec(resource.ID, changeType, existingConfig, resource.Configuration)
+		node.Name = name.String
+		node.ParentID = parentID.String
+		nodes = append(nodes, node)
+	}
+
+	return nodes, rows.Err()
+}
+
+// GetOrgNodeChildren returns the direct children of a hierarchy node
+func (s *SQLiteStorage) GetOrgNodeChildren(provider, id string) ([]core.OrgNode, error) {
+	nodes, err := s.GetOrgNodes(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	var children []core.OrgNode
+	for _, node := range nodes {
+		if node.ParentID == id {
+			children = append(children, node)
 		}
+	}
+	return children, nil
+}
 
-		// Store resource
-		tagsJSON, _ := json.Marshal(resource.Tags)
-		depsJSON, _ := json.Marshal(resource.Dependencies)
-
-		_, err = insertStmt.Exec(
-			resource.ID,
-			resource.Provider,
-			resource.AccountID,
-			resource.Region,
-			resource.Service,
-			resource.Type,
-			resource.Name,
-			resource.ARN,
-			resource.CreatedAt,
-			resource.UpdatedAt,
-			string(tagsJSON),
-			string(resource.Configuration),
-			resource.PublicAccess,
-			resource.Encrypted,
-			resource.MonthlyCost,
-			string(depsJSON),
-			resource.DiscoveredAt,
-			resource.DiscoveryMethod,
-		)
+// CreateRun registers a discovery run before any resources are discovered
+func (s *SQLiteStorage) CreateRun(result *core.DiscoveryResult) (int64, error) {
+	res, err := s.db.Exec(`
+		INSERT INTO discovery_runs (started_at, providers, mode, status)
+		VALUES (?, ?, ?, ?)
+	`, result.StartTime.UTC(), strings.Join(result.Providers, ","), result.Mode, core.RunStatusRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert discovery run: %w", err)
+	}
+
+	return res.LastInsertId()
+}
+
+// GetRun returns the status of a single discovery run
+func (s *SQLiteStorage) GetRun(runID int64) (*core.DiscoveryStatus, error) {
+	var status core.DiscoveryStatus
+	var providers, runStatus sql.NullString
+
+	err := s.db.QueryRow(`
+		SELECT started_at, resource_count, providers, status
+		FROM discovery_runs
+		WHERE id = ?
+	`, runID).Scan(&status.LastRun, &status.ResourceCount, &providers, &runStatus)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("discovery run %d not found", runID)
+		}
+		return nil, fmt.Errorf("failed to get discovery run: %w", err)
+	}
+
+	status.Providers = providers.String
+	status.Status = runStatus.String
+	return &status, nil
+}
+
+// GetCheckpoints returns the completed units of a discovery run
+func (s *SQLiteStorage) GetCheckpoints(runID int64) ([]core.Checkpoint, error) {
+	rows, err := s.db.Query(`
+		SELECT run_id, provider, account_id, region, resource_count, completed_at
+		FROM discovery_checkpoints
+		WHERE run_id = ?
+		ORDER BY id
+	`, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var checkpoints []core.Checkpoint
+	for rows.Next() {
+		var checkpoint core.Checkpoint
+		if err := rows.Scan(
+			&checkpoint.RunID,
+			&checkpoint.Provider,
+			&checkpoint.AccountID,
+			&checkpoint.Region,
+			&checkpoint.ResourceCount,
+			&checkpoint.CompletedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan checkpoint: %w", err)
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	return checkpoints, rows.Err()
+}
+
+// GetCheckpointResources returns the resources stored by a run's checkpoints
+func (s *SQLiteStorage) GetCheckpointResources(runID int64) ([]core.Resource, error) {
+	rows, err := s.db.Query("SELECT resource_ids FROM discovery_checkpoints WHERE run_id = ?", runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query checkpoints: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for rows.Next() {
+		var idsJSON sql.NullString
+		if err := rows.Scan(&idsJSON); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan checkpoint: %w", err)
+		}
+
+		var unitIDs []string
+		if idsJSON.String != "" {
+			if err := json.Unmarshal([]byte(idsJSON.String), &unitIDs); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to parse checkpoint resources: %w", err)
+			}
+		}
 
+		// Account checkpoints repeat the ids of their region checkpoints
+		for _, id := range unitIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	rows.Close()
+
+	// Stay well below SQLite's bound parameter limit
+	const batchSize = 500
+
+	var resources []core.Resource
+	for start := 0; start < len(ids); start += batchSize {
+		end := start + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		args := make([]interface{}, 0, end-start)
+		for _, id := range ids[start:end] {
+			args = append(args, id)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(args)), ",")
+
+		batch, err := s.GetResources("SELECT * FROM resources WHERE id IN ("+placeholders+")", args...)
 		if err != nil {
-			return fmt.Errorf("failed to store resource %s: %w", resource.ID, err)
+			return nil, err
+		}
+		resources = append(resources, batch...)
+	}
+
+	return resources, nil
+}
+
+// SaveCheckpoint stores the resources of a completed unit together with its checkpoint
+func (s *SQLiteStorage) SaveCheckpoint(checkpoint core.Checkpoint, resources []core.Resource) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			// Log rollback error but don't fail the operation
+			_ = rollbackErr
 		}
+	}()
+
+	if err := s.storeResourcesTx(tx, checkpoint.RunID, resources); err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		ids = append(ids, resource.ID)
+	}
+	idsJSON, _ := json.Marshal(ids)
+
+	_, err = tx.Exec(`
+		INSERT OR REPLACE INTO discovery_checkpoints (
+			run_id, provider, account_id, region, resource_count, resource_ids, completed_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, checkpoint.RunID, checkpoint.Provider, checkpoint.AccountID, checkpoint.Region,
+		checkpoint.ResourceCount, string(idsJSON), checkpoint.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert checkpoint: %w", err)
 	}
 
 	return tx.Commit()
 }
 
+// resourceColumns are the columns of the resources table in table order,
+// so that SELECT * rows can be scanned by scanResource
+var resourceColumns = []string{
+	"id", "provider", "account_id", "region", "service", "type", "name", "arn",
+	"created_at", "updated_at", "tags", "configuration", "public_access",
+	"encrypted", "monthly_cost", "dependencies", "discovered_at", "discovery_method",
+	"compliance", "dependents",
+}
+
+// resourceValues returns the values of a resource for resourceColumns.
+// Times are stored in UTC since the driver cannot read back other zones.
+func resourceValues(resource core.Resource) []interface{} {
+	tagsJSON, _ := json.Marshal(resource.Tags)
+	depsJSON, _ := json.Marshal(resource.Dependencies)
+	complianceJSON, _ := json.Marshal(resource.Compliance)
+	dependentsJSON, _ := json.Marshal(resource.Dependents)
+
+	return []interface{}{
+		resource.ID,
+		resource.Provider,
+		resource.AccountID,
+		resource.Region,
+		resource.Service,
+		resource.Type,
+		resource.Name,
+		resource.ARN,
+		resource.CreatedAt.UTC(),
+		resource.UpdatedAt.UTC(),
+		string(tagsJSON),
+		string(resource.Configuration),
+		resource.PublicAccess,
+		resource.Encrypted,
+		resource.MonthlyCost,
+		string(depsJSON),
+		resource.DiscoveredAt.UTC(),
+		resource.DiscoveryMethod,
+		string(complianceJSON),
+		string(dependentsJSON),
+	}
+}
+
+// scanResource reads a resource from a SELECT * row of the resources or
+// deleted_resources table
+func scanResource(rows core.Rows) (core.Resource, error) {
+	var resource core.Resource
+	var region, name, arn, tagsJSON, configJSON, depsJSON, method, complianceJSON, dependentsJSON sql.NullString
+	var createdAt, updatedAt, discoveredAt sql.NullTime
+	var publicAccess, encrypted sql.NullBool
+	var monthlyCost sql.NullFloat64
+
+	err := rows.Scan(
+		&resource.ID,
+		&resource.Provider,
+		&resource.AccountID,
+		&region,
+		&resource.Service,
+		&resource.Type,
+		&name,
+		&arn,
+		&createdAt,
+		&updatedAt,
+		&tagsJSON,
+		&configJSON,
+		&publicAccess,
+		&encrypted,
+		&monthlyCost,
+		&depsJSON,
+		&discoveredAt,
+		&method,
+		&complianceJSON,
+		&dependentsJSON,
+	)
+	if err != nil {
+		return resource, fmt.Errorf("failed to scan resource: %w", err)
+	}
+
+	resource.Region = region.String
+	resource.Name = name.String
+	resource.ARN = arn.String
+	resource.CreatedAt = createdAt.Time
+	resource.UpdatedAt = updatedAt.Time
+	resource.PublicAccess = publicAccess.Bool
+	resource.Encrypted = encrypted.Bool
+	resource.MonthlyCost = monthlyCost.Float64
+	resource.DiscoveredAt = discoveredAt.Time
+	resource.DiscoveryMethod = method.String
+
+	// Parse JSON fields
+	if configJSON.String != "" {
+		resource.Configuration = json.RawMessage(configJSON.String)
+	}
+	if tagsJSON.String != "" {
+		if err := json.Unmarshal([]byte(tagsJSON.String), &resource.Tags); err != nil {
+			resource.Tags = make(map[string]string)
+		}
+	}
+	if depsJSON.String != "" {
+		if err := json.Unmarshal([]byte(depsJSON.String), &resource.Dependencies); err != nil {
+			resource.Dependencies = make([]string, 0)
+		}
+	}
+	if complianceJSON.String != "" {
+		if err := json.Unmarshal([]byte(complianceJSON.String), &resource.Compliance); err != nil {
+			resource.Compliance = make([]string, 0)
+		}
+	}
+	if dependentsJSON.String != "" {
+		if err := json.Unmarshal([]byte(dependentsJSON.String), &resource.Dependents); err != nil {
+			resource.Dependents = make([]string, 0)
+		}
+	}
+
+	return resource, nil
+}
+
 // GetResources retrieves resources based on query
 func (s *SQLiteStorage) GetResources(query string, args ...interface{}) ([]core.Resource, error) {
 	rows, err := s.db.Query(query, args...)
@@ -259,49 +667,14 @@ func (s *SQLiteStorage) GetResources(query string, args ...interface{}) ([]core.
 
 	var resources []core.Resource
 	for rows.Next() {
-		var resource core.Resource
-		var tagsJSON, depsJSON string
-
-		err := rows.Scan(
-			&resource.ID,
-			&resource.Provider,
-			&resource.AccountID,
-			&resource.Region,
-			&resource.Service,
-			&resource.Type,
-			&resource.Name,
-			&resource.ARN,
-			&resource.CreatedAt,
-			&resource.UpdatedAt,
-			&tagsJSON,
-			&resource.Configuration,
-			&resource.PublicAccess,
-			&resource.Encrypted,
-			&resource.MonthlyCost,
-			&depsJSON,
-			&resource.DiscoveredAt,
-			&resource.DiscoveryMethod,
-		)
+		resource, err := scanResource(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan resource: %w", err)
-		}
-
-		// Parse JSON fields
-		if tagsJSON != "" {
-			if err := json.Unmarshal([]byte(tagsJSON), &resource.Tags); err != nil {
-				resource.Tags = make(map[string]string)
-			}
-		}
-		if depsJSON != "" {
-			if err := json.Unmarshal([]byte(depsJSON), &resource.Dependencies); err != nil {
-				resource.Dependencies = make([]string, 0)
-			}
+			return nil, err
 		}
-
 		resources = append(resources, resource)
 	}
 
-	return resources, nil
+	return resources, rows.Err()
 }
 
 // GetDiscoveryStatus returns status of last discovery
@@ -461,5 +834,110 @@ func (s *SQLiteStorage) GetResourceSummary() (*core.ResourceSummary, error) {
 		return nil, fmt.Errorf("failed to get security issues: %w", err)
 	}
 
+	// Roll-ups by organization hierarchy node
+	summary.ByOrgNode, err = s.orgNodeSummaries()
+	if err != nil {
+		return nil, err
+	}
+
 	return summary, nil
 }
+
+// orgNodeSummaries rolls up the resources of each account into every
+// organization node above it
+func (s *SQLiteStorage) orgNodeSummaries() ([]core.OrgNodeSummary, error) {
+	nodes, err := s.GetOrgNodes("")
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	type key struct{ provider, id string }
+	byKey := make(map[key]core.OrgNode, len(nodes))
+	for _, node := range nodes {
+		byKey[key{node.Provider, node.ID}] = node
+	}
+
+	// ancestors returns a node and the nodes above it, bottom up
+	ancestors := func(node core.OrgNode) []core.OrgNode {
+		chain := []core.OrgNode{node}
+		seen := map[key]bool{{node.Provider, node.ID}: true}
+		for node.ParentID != "" {
+			parentKey := key{node.Provider, node.ParentID}
+			parent, ok := byKey[parentKey]
+			if !ok || seen[parentKey] {
+				break
+			}
+			seen[parentKey] = true
+			chain = append(chain, parent)
+			node = parent
+		}
+		return chain
+	}
+
+	totals := make(map[key]*core.OrgNodeSummary)
+	for _, node := range nodes {
+		if node.Kind == core.OrgNodeAccount {
+			continue
+		}
+		chain := ancestors(node)
+		names := make([]string, 0, len(chain))
+		for i := len(chain) - 1; i >= 0; i-- {
+			names = append(names, chain[i].Name)
+		}
+		totals[key{node.Provider, node.ID}] = &core.OrgNodeSummary{
+			Node: node,
+			Path: strings.Join(names, "/"),
+		}
+	}
+
+	rows, err := s.db.Query(`
+		SELECT provider, account_id, COUNT(*), COALESCE(SUM(monthly_cost), 0),
+		       SUM(CASE WHEN public_access THEN 1 ELSE 0 END)
+		FROM resources
+		GROUP BY provider, account_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var account key
+		var count, public int
+		var cost float64
+		if err := rows.Scan(&account.provider, &account.id, &count, &cost, &public); err != nil {
+			return nil, err
+		}
+
+		node, ok := byKey[account]
+		if !ok {
+			continue
+		}
+		for _, ancestor := range ancestors(node) {
+			if total, ok := totals[key{ancestor.Provider, ancestor.ID}]; ok {
+				total.ResourceCount += count
+				total.MonthlyCost += cost
+				total.PublicResources += public
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	summaries := make([]core.OrgNodeSummary, 0, len(totals))
+	for _, total := range totals {
+		summaries = append(summaries, *total)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Node.Provider != summaries[j].Node.Provider {
+			return summaries[i].Node.Provider < summaries[j].Node.Provider
+		}
+		return summaries[i].Path < summaries[j].Path
+	})
+
+	return summaries, nil
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+)
+
+// Resource versions record the state of every resource over discovery runs.
+// A version is valid from the run that stored it up to, but excluding, the
+// run that changed or deleted the resource; valid_to is NULL for current
+// versions. Runs that find a resource unchanged do not add a version, so
+// the inventory as of any run is the set of versions valid at that run.
+
+// storeVersionTx records the state of a resource stored by a run
+func (s *SQLiteStorage) storeVersionTx(tx *sql.Tx, runID int64, resource core.Resource) error {
+	hash := resourceHash(resource)
+
+	var versionID, validFrom int64
+	var currentHash sql.NullString
+	err := tx.QueryRow(
+		"SELECT version_id, valid_from, content_hash FROM resource_versions WHERE id = ? AND valid_to IS NULL",
+		resource.ID,
+	).Scan(&versionID, &validFrom, &currentHash)
+
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return fmt.Errorf("failed to read version of %s: %w", resource.ID, err)
+	case currentHash.String == hash:
+		return nil
+	case validFrom == runID:
+		// A resource stored twice by the same run, first by a checkpoint and
+		// then enriched, keeps only its final state
+		if _, err := tx.Exec("DELETE FROM resource_versions WHERE version_id = ?", versionID); err != nil {
+			return fmt.Errorf("failed to replace version of %s: %w", resource.ID, err)
+		}
+	default:
+		if _, err := tx.Exec("UPDATE resource_versions SET valid_to = ? WHERE version_id = ?", runID, versionID); err != nil {
+			return fmt.Errorf("failed to close version of %s: %w", resource.ID, err)
+		}
+	}
+
+	columns := append([]string{"valid_from", "content_hash"}, resourceColumns...)
+	values := append([]interface{}{runID, hash}, resourceValues(resource)...)
+	_, err = tx.Exec(fmt.Sprintf(
+		"INSERT INTO resource_versions (%s) VALUES (%s)",
+		strings.Join(columns, ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "),
+	), values...)
+	if err != nil {
+		return fmt.Errorf("failed to store version of %s: %w", resource.ID, err)
+	}
+
+	return nil
+}
+
+// resourceHash fingerprints the state of a resource. The discovery time is
+// left out since it changes on every run.
+func resourceHash(resource core.Resource) string {
+	resource.DiscoveredAt = time.Time{}
+	data, _ := json.Marshal(resourceValues(resource))
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// GetResourcesAsOf returns the inventory as it was after a discovery run
+// was stored
+func (s *SQLiteStorage) GetResourcesAsOf(runID int64) ([]core.Resource, error) {
+	return s.GetResources(fmt.Sprintf(`
+		SELECT %s FROM resource_versions
+		WHERE valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)
+		ORDER BY id
+	`, strings.Join(resourceColumns, ", ")), runID, runID)
+}
+
+// runTimeLayouts are the accepted formats of point-in-time references
+var runTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// ResolveRun resolves a run reference to a run ID. The reference is a run
+// ID, or a time that resolves to the last run completed at or before it.
+// A date without a time refers to the end of that day in UTC.
+func (s *SQLiteStorage) ResolveRun(ref string) (int64, error) {
+	ref = strings.TrimSpace(ref)
+
+	if runID, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		var id int64
+		err := s.db.QueryRow("SELECT id FROM discovery_runs WHERE id = ?", runID).Scan(&id)
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("discovery run %d not found", runID)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get discovery run: %w", err)
+		}
+		return id, nil
+	}
+
+	at, err := parseRunTime(ref)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := s.db.Query("SELECT id, completed_at FROM discovery_runs WHERE completed_at IS NOT NULL ORDER BY id")
+	if err != nil {
+		return 0, fmt.Errorf("failed to query discovery runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runID int64
+	for rows.Next() {
+		var id int64
+		var completedAt sql.NullTime
+		if err := rows.Scan(&id, &completedAt); err != nil {
+			return 0, fmt.Errorf("failed to scan discovery run: %w", err)
+		}
+		// Runs stored before completion times were recorded have none
+		if completedAt.Time.IsZero() || completedAt.Time.After(at) {
+			continue
+		}
+		runID = id
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if runID == 0 {
+		return 0, fmt.Errorf("no discovery run completed at or before %s", at.Format(time.RFC3339))
+	}
+	return runID, nil
+}
+
+// parseRunTime parses a point-in-time reference
+func parseRunTime(ref string) (time.Time, error) {
+	for _, layout := range runTimeLayouts {
+		if at, err := time.Parse(layout, ref); err == nil {
+			return at, nil
+		}
+	}
+
+	if day, err := time.Parse("2006-01-02", ref); err == nil {
+		return day.Add(24*time.Hour - time.Nanosecond), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid run reference %q: expected a run ID, a date or an RFC 3339 time", ref)
+}
+
+// DiffRuns compares the inventories stored by two discovery runs
+func (s *SQLiteStorage) DiffRuns(fromRunID, toRunID int64, filter core.DiffFilter) (*core.InventoryDiff, error) {
+	from, err := s.GetResourcesAsOf(fromRunID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inventory of run %d: %w", fromRunID, err)
+	}
+	to, err := s.GetResourcesAsOf(toRunID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inventory of run %d: %w", toRunID, err)
+	}
+
+	diff := core.DiffInventories(from, to, filter)
+	diff.FromRun = fromRunID
+	diff.ToRun = toRunID
+	return diff, nil
+}
@@ -10,6 +10,8 @@ import (
 
 // ProgressBar represents a progress bar
 type ProgressBar struct {
+	name      string
+	message   string
 	total     int
 	current   int
 	width     int
@@ -52,6 +54,30 @@ func (pb *ProgressBar) SetShowETA(show bool) {
 	pb.showETA = show
 }
 
+// SetName sets the label shown in front of the progress bar
+func (pb *ProgressBar) SetName(name string) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.name = name
+}
+
+// SetMessage sets the status message shown after the progress bar
+func (pb *ProgressBar) SetMessage(message string) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.message = message
+}
+
+// SetTotal changes the total once it becomes known
+func (pb *ProgressBar) SetTotal(total int) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	pb.total = total
+	if pb.current > pb.total {
+		pb.current = pb.total
+	}
+}
+
 // Add increments the progress bar
 func (pb *ProgressBar) Add(amount int) {
 	pb.mu.Lock()
@@ -77,7 +103,11 @@ func (pb *ProgressBar) String() string {
 	pb.mu.RLock()
 	defer pb.mu.RUnlock()
 
-	percentage := float64(pb.current) / float64(pb.total)
+	// A bar without a known total is rendered as empty
+	percentage := 0.0
+	if pb.total > 0 {
+		percentage = float64(pb.current) / float64(pb.total)
+	}
 	filled := int(percentage * float64(pb.width))
 	empty := pb.width - filled
 
@@ -97,6 +127,10 @@ func (pb *ProgressBar) String() string {
 		status += fmt.Sprintf(" ETA: %s", eta.Round(time.Second))
 	}
 
+	if pb.message != "" {
+		status += " " + pb.message
+	}
+
 	return status
 }
 
@@ -114,8 +148,10 @@ func (pb *ProgressBar) Finish() {
 
 // MultiProgressBar represents multiple progress bars
 type MultiProgressBar struct {
-	bars []*ProgressBar
-	mu   sync.RWMutex
+	bars   []*ProgressBar
+	footer []string
+	drawn  int
+	mu     sync.RWMutex
 }
 
 // NewMultiProgressBar creates a new multi-progress bar
@@ -135,17 +171,51 @@ func (mpb *MultiProgressBar) AddBar(total int) *ProgressBar {
 	return bar
 }
 
-// Write writes all progress bars to an io.Writer
-func (mpb *MultiProgressBar) Write(w io.Writer) {
-	mpb.mu.RLock()
-	defer mpb.mu.RUnlock()
+// AddNamedBar adds a labelled progress bar
+func (mpb *MultiProgressBar) AddNamedBar(name string, total int) *ProgressBar {
+	bar := mpb.AddBar(total)
+	bar.SetName(name)
+	return bar
+}
 
-	// Clear screen
-	fmt.Fprint(w, "\033[2J\033[H")
+// SetFooter sets lines drawn below the bars and redrawn along with them
+func (mpb *MultiProgressBar) SetFooter(lines []string) {
+	mpb.mu.Lock()
+	defer mpb.mu.Unlock()
+	mpb.footer = lines
+}
 
+// Write writes all progress bars to an io.Writer. Bars written before are
+// redrawn in place, leaving the output above them untouched.
+func (mpb *MultiProgressBar) Write(w io.Writer) {
+	mpb.mu.Lock()
+	defer mpb.mu.Unlock()
+
+	lines := make([]string, 0, len(mpb.bars)+len(mpb.footer))
 	for i, bar := range mpb.bars {
-		fmt.Fprintf(w, "Bar %d: %s\n", i+1, bar.String())
+		bar.mu.RLock()
+		name := bar.name
+		bar.mu.RUnlock()
+
+		if name == "" {
+			name = fmt.Sprintf("Bar %d", i+1)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", name, bar.String()))
+	}
+	lines = append(lines, mpb.footer...)
+
+	// Move back to the first line drawn last time
+	if mpb.drawn > 0 {
+		fmt.Fprintf(w, "\033[%dA", mpb.drawn)
+	}
+	for _, line := range lines {
+		fmt.Fprintf(w, "\r\033[2K%s\n", line)
+	}
+	// Clear what is left of a longer previous drawing
+	if len(lines) < mpb.drawn {
+		fmt.Fprint(w, "\033[J")
 	}
+	mpb.drawn = len(lines)
 }
 
 // Spinner represents a spinner
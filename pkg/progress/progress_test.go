@@ -0,0 +1,36 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiProgressBar_WriteRedrawsInPlace(t *testing.T) {
+	mpb := NewMultiProgressBar()
+	mpb.AddNamedBar("aws", 2).SetShowRate(false)
+	mpb.AddNamedBar("gcp", 2).SetShowRate(false)
+
+	var out bytes.Buffer
+	mpb.Write(&out)
+	assert.NotContains(t, out.String(), "\033[2J", "the screen must not be cleared")
+	assert.True(t, strings.HasPrefix(out.String(), "\r\033[2Kaws: "), "nothing was drawn before")
+	assert.Equal(t, 2, strings.Count(out.String(), "\n"))
+
+	// The second drawing moves up over the two bars and clears each line
+	out.Reset()
+	mpb.SetFooter([]string{"  error: gcp/p1: denied"})
+	mpb.Write(&out)
+	assert.True(t, strings.HasPrefix(out.String(), "\033[2A"))
+	assert.Equal(t, 3, strings.Count(out.String(), "\r\033[2K"))
+	assert.Contains(t, out.String(), "  error: gcp/p1: denied\n")
+
+	// A shorter drawing clears the lines left below it
+	out.Reset()
+	mpb.SetFooter(nil)
+	mpb.Write(&out)
+	assert.True(t, strings.HasPrefix(out.String(), "\033[3A"))
+	assert.True(t, strings.HasSuffix(out.String(), "\033[J"))
+}
@@ -0,0 +1,353 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/cloudrecon/cloudrecon/internal/providers/azure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fakeTenant       = "00000000-0000-0000-0000-0000000000aa"
+	fakeSubscription = "00000000-0000-0000-0000-000000000001"
+)
+
+// fakeSubscriptions are the subscriptions visible to the fake credential
+var fakeSubscriptions = []map[string]interface{}{
+	{"subscriptionId": fakeSubscription, "displayName": "Production", "state": "Enabled", "tenantId": fakeTenant},
+	{"subscriptionId": "00000000-0000-0000-0000-000000000002", "displayName": "Sandbox", "state": "Enabled", "tenantId": fakeTenant},
+	{"subscriptionId": "00000000-0000-0000-0000-000000000003", "displayName": "Retired", "state": "Disabled", "tenantId": fakeTenant},
+}
+
+// fakeDescendants is the management group hierarchy of the fake tenant:
+// root > Platform > Production subscription, root > Sandbox subscription
+var fakeDescendants = []map[string]interface{}{
+	{
+		"id":   "/providers/Microsoft.Management/managementGroups/mg-platform",
+		"name": "mg-platform",
+		"type": "Microsoft.Management/managementGroups",
+		"properties": map[string]interface{}{
+			"displayName": "Platform",
+			"parent":      map[string]interface{}{"id": "/providers/Microsoft.Management/managementGroups/" + fakeTenant},
+		},
+	},
+	{
+		"id":   "/subscriptions/" + fakeSubscription,
+		"name": fakeSubscription,
+		"type": "/subscriptions",
+		"properties": map[string]interface{}{
+			"displayName": "Production",
+			"parent":      map[string]interface{}{"id": "/providers/Microsoft.Management/managementGroups/mg-platform"},
+		},
+	},
+	{
+		"id":   "/subscriptions/00000000-0000-0000-0000-000000000002",
+		"name": "00000000-0000-0000-0000-000000000002",
+		"type": "/subscriptions",
+		"properties": map[string]interface{}{
+			"displayName": "Sandbox",
+			"parent":      map[string]interface{}{"id": "/providers/Microsoft.Management/managementGroups/" + fakeTenant},
+		},
+	},
+}
+
+// fakeCredential issues tokens for the fake Resource Manager API
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "fake-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// newFakeARM serves list operations of a single subscription. The virtual
+// machine list is split over two pages to exercise nextLink handling.
+func newFakeARM(t *testing.T) *httptest.Server {
+	rg := "/subscriptions/" + fakeSubscription + "/resourceGroups/rg-app"
+	var server *httptest.Server
+
+	pages := map[string][]map[string]interface{}{
+		"/locations": {
+			{"name": "eastus", "metadata": map[string]interface{}{"regionType": "Physical"}},
+			{"name": "westeurope", "metadata": map[string]interface{}{"regionType": "Physical"}},
+			{"name": "europe", "metadata": map[string]interface{}{"regionType": "Logical"}},
+		},
+		"/providers/Microsoft.Compute/disks": {
+			{
+				"id": rg + "/providers/Microsoft.Compute/disks/web-1-os", "name": "web-1-os", "location": "eastus",
+				"properties": map[string]interface{}{"encryption": map[string]interface{}{"type": "EncryptionAtRestWithPlatformKey"}},
+			},
+		},
+		"/providers/Microsoft.Network/virtualNetworks": {
+			{
+				"id": rg + "/providers/Microsoft.Network/virtualNetworks/vnet-app", "name": "vnet-app", "location": "eastus",
+				"tags": map[string]string{"Environment": "prod"},
+				"properties": map[string]interface{}{
+					"subnets": []map[string]interface{}{
+						{
+							"id":   rg + "/providers/Microsoft.Network/virtualNetworks/vnet-app/subnets/web",
+							"name": "web",
+							"properties": map[string]interface{}{
+								"addressPrefix":        "10.0.1.0/24",
+								"networkSecurityGroup": map[string]interface{}{"id": rg + "/providers/Microsoft.Network/networkSecurityGroups/nsg-web"},
+							},
+						},
+					},
+				},
+			},
+		},
+		"/providers/Microsoft.Network/networkSecurityGroups": {
+			{
+				"id": rg + "/providers/Microsoft.Network/networkSecurityGroups/nsg-web", "name": "nsg-web", "location": "eastus",
+				"properties": map[string]interface{}{
+					"securityRules": []map[string]interface{}{
+						{"name": "https", "properties": map[string]interface{}{
+							"direction": "Inbound", "access": "Allow", "sourceAddressPrefix": "Internet",
+						}},
+					},
+				},
+			},
+		},
+		"/providers/Microsoft.Storage/storageAccounts": {
+			{
+				"id": rg + "/providers/Microsoft.Storage/storageAccounts/appdata", "name": "appdata", "location": "westeurope",
+				"properties": map[string]interface{}{"networkAcls": map[string]interface{}{"defaultAction": "Deny"}},
+			},
+		},
+		"/providers/Microsoft.Sql/servers": {
+			{
+				"id": rg + "/providers/Microsoft.Sql/servers/sql-app", "name": "sql-app", "location": "westeurope",
+				"properties": map[string]interface{}{"publicNetworkAccess": "Enabled"},
+			},
+		},
+		"/providers/Microsoft.Web/sites": {
+			{
+				"id": rg + "/providers/Microsoft.Web/sites/app-web", "name": "app-web", "location": "eastus",
+				"properties": map[string]interface{}{"httpsOnly": true},
+			},
+		},
+		"/providers/Microsoft.KeyVault/vaults": {
+			{"id": rg + "/providers/Microsoft.KeyVault/vaults/kv-app", "name": "kv-app", "location": "eastus"},
+		},
+	}
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer fake-token", r.Header.Get("Authorization"))
+		assert.NotEmpty(t, r.URL.Query().Get("api-version"))
+
+		switch r.URL.Path {
+		case "/subscriptions":
+			w.Header().Set("Content-Type", "application/json")
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{"value": fakeSubscriptions}))
+			return
+		case "/providers/Microsoft.Management/managementGroups/" + fakeTenant + "/descendants":
+			w.Header().Set("Content-Type", "application/json")
+			require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{"value": fakeDescendants}))
+			return
+		}
+
+		prefix := "/subscriptions/" + fakeSubscription
+		path := r.URL.Path
+		if len(path) < len(prefix) || path[:len(prefix)] != prefix {
+			http.NotFound(w, r)
+			return
+		}
+		path = path[len(prefix):]
+
+		var body map[string]interface{}
+		switch {
+		case path == "/providers/Microsoft.Compute/virtualMachines" && r.URL.Query().Get("page") == "":
+			body = map[string]interface{}{
+				"value": []map[string]interface{}{
+					{
+						"id": rg + "/providers/Microsoft.Compute/virtualMachines/web-1", "name": "web-1", "location": "eastus",
+						"properties": map[string]interface{}{
+							"timeCreated": "2024-01-02T03:04:05Z",
+							"storageProfile": map[string]interface{}{
+								"osDisk": map[string]interface{}{
+									"managedDisk": map[string]interface{}{"id": rg + "/providers/Microsoft.Compute/disks/web-1-os"},
+								},
+							},
+						},
+					},
+				},
+				"nextLink": server.URL + r.URL.Path + "?api-version=" + r.URL.Query().Get("api-version") + "&page=2",
+			}
+		case path == "/providers/Microsoft.Compute/virtualMachines":
+			body = map[string]interface{}{
+				"value": []map[string]interface{}{
+					{"id": rg + "/providers/Microsoft.Compute/virtualMachines/web-2", "name": "web-2", "location": "West Europe"},
+				},
+			}
+		default:
+			value, ok := pages[path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			body = map[string]interface{}{"value": value}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newFakeAzureProvider(t *testing.T, cfg core.AzureConfig) *azure.AzureProvider {
+	server := newFakeARM(t)
+
+	provider, err := azure.NewProviderWithOptions(cfg, azure.ClientOptions{
+		Credential: fakeCredential{},
+		Endpoint:   server.URL,
+		Transport:  server.Client(),
+	})
+	require.NoError(t, err)
+	return provider
+}
+
+func TestAzureARMDiscovery(t *testing.T) {
+	// Skip if not running integration tests
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	provider := newFakeAzureProvider(t, core.AzureConfig{})
+	account := core.Account{ID: fakeSubscription, Provider: "azure", Type: "subscription"}
+
+	resources, err := provider.DiscoverResources(context.Background(), account, core.DiscoveryOptions{
+		Mode: core.StandardMode,
+	})
+	require.NoError(t, err)
+
+	byName := make(map[string]core.Resource)
+	for _, resource := range resources {
+		assert.Equal(t, fakeSubscription, resource.AccountID)
+		assert.Equal(t, "direct_api", resource.DiscoveryMethod)
+		byName[resource.Name] = resource
+	}
+	assert.Len(t, resources, 10)
+
+	// Both pages of virtual machines, with display-name locations normalized
+	require.Contains(t, byName, "web-1")
+	require.Contains(t, byName, "web-2")
+	assert.Equal(t, "westeurope", byName["web-2"].Region)
+	assert.Equal(t, "virtualmachines", byName["web-1"].Type)
+	assert.Equal(t, 2024, byName["web-1"].CreatedAt.Year())
+	assert.Equal(t, []string{byName["web-1-os"].ID}, byName["web-1"].Dependencies)
+	assert.Equal(t, "rg-app", byName["web-1"].Tags["ResourceGroup"])
+
+	// Subnets are read from their virtual network
+	require.Contains(t, byName, "web")
+	subnet := byName["web"]
+	assert.Equal(t, "network", subnet.Service)
+	assert.Equal(t, "subnets", subnet.Type)
+	assert.Equal(t, []string{byName["vnet-app"].ID, byName["nsg-web"].ID}, subnet.Dependencies)
+
+	// Security flags
+	assert.True(t, byName["nsg-web"].PublicAccess)
+	assert.True(t, byName["sql-app"].PublicAccess)
+	assert.False(t, byName["appdata"].PublicAccess)
+	assert.True(t, byName["web-1-os"].Encrypted)
+}
+
+func TestAzureARMDiscoveryScope(t *testing.T) {
+	// Skip if not running integration tests
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	provider := newFakeAzureProvider(t, core.AzureConfig{})
+	account := core.Account{ID: fakeSubscription, Provider: "azure", Type: "subscription"}
+
+	t.Run("regions", func(t *testing.T) {
+		resources, err := provider.DiscoverResources(context.Background(), account, core.DiscoveryOptions{
+			Mode:    core.StandardMode,
+			Regions: []string{"westeurope"},
+		})
+		require.NoError(t, err)
+
+		var names []string
+		for _, resource := range resources {
+			names = append(names, resource.Name)
+		}
+		assert.ElementsMatch(t, []string{"web-2", "appdata", "sql-app"}, names)
+	})
+
+	t.Run("quick mode", func(t *testing.T) {
+		resources, err := provider.DiscoverResources(context.Background(), account, core.DiscoveryOptions{
+			Mode: core.QuickMode,
+		})
+		require.NoError(t, err)
+
+		for _, resource := range resources {
+			assert.Contains(t, []string{"virtualmachines", "storageaccounts", "servers"}, resource.Type)
+		}
+		assert.Len(t, resources, 4)
+	})
+
+	t.Run("resource types", func(t *testing.T) {
+		resources, err := provider.DiscoverResources(context.Background(), account, core.DiscoveryOptions{
+			Mode:          core.StandardMode,
+			ResourceTypes: []string{"Microsoft.KeyVault/vaults"},
+		})
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, "kv-app", resources[0].Name)
+	})
+}
+
+func TestAzureSubscriptionDiscovery(t *testing.T) {
+	// Skip if not running integration tests
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	t.Run("tenant", func(t *testing.T) {
+		provider := newFakeAzureProvider(t, core.AzureConfig{})
+
+		accounts, err := provider.DiscoverAccounts(context.Background())
+		require.NoError(t, err)
+
+		// Disabled subscriptions are skipped
+		require.Len(t, accounts, 2)
+
+		production := accounts[0]
+		assert.Equal(t, fakeSubscription, production.ID)
+		assert.Equal(t, "Production", production.Name)
+		assert.Equal(t, "subscription", production.Type)
+		assert.Equal(t, fakeTenant, production.Tags["tenant_id"])
+		assert.Equal(t, "Enabled", production.Tags["state"])
+		assert.Equal(t, "mg-platform", production.Tags["management_group"])
+		assert.Equal(t, fakeTenant+"/mg-platform", production.Tags["management_group_ids"])
+		assert.Equal(t, fakeTenant+"/Platform", production.Tags["management_group_path"])
+		require.Len(t, production.Hierarchy, 2)
+		assert.Equal(t, core.OrgNodeManagementGroup, production.Hierarchy[1].Kind)
+		assert.Equal(t, "Platform", production.Hierarchy[1].Name)
+		assert.Equal(t, fakeTenant, production.Hierarchy[1].ParentID)
+
+		sandbox := accounts[1]
+		assert.Equal(t, fakeTenant, sandbox.Tags["management_group"])
+	})
+
+	t.Run("allow-list", func(t *testing.T) {
+		provider := newFakeAzureProvider(t, core.AzureConfig{Subscriptions: []string{"Sandbox"}})
+
+		accounts, err := provider.DiscoverAccounts(context.Background())
+		require.NoError(t, err)
+		require.Len(t, accounts, 1)
+		assert.Equal(t, "Sandbox", accounts[0].Name)
+	})
+}
@@ -0,0 +1,92 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/cloudrecon/cloudrecon/internal/export"
+	"github.com/cloudrecon/cloudrecon/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventoryDiff(t *testing.T) {
+	// Skip if not running integration tests
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "cloudrecon.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	scope := core.Scope{Provider: "aws", AccountID: "123456789012", Region: "us-east-1", Service: "s3"}
+	bucket := func(id string, public bool, tags map[string]string) core.Resource {
+		return core.Resource{
+			ID: id, Provider: scope.Provider, AccountID: scope.AccountID, Region: scope.Region,
+			Service: scope.Service, Type: "bucket", Name: id, Tags: tags, PublicAccess: public,
+			Configuration: json.RawMessage(`{"Versioning":"Enabled"}`),
+			DiscoveredAt:  time.Now(),
+		}
+	}
+	storeRun := func(runEnd time.Time, resources ...core.Resource) int64 {
+		runID, err := store.CreateRun(&core.DiscoveryResult{StartTime: runEnd.Add(-time.Minute), Providers: []string{"aws"}})
+		require.NoError(t, err)
+		require.NoError(t, store.StoreDiscovery(&core.DiscoveryResult{
+			RunID:         runID,
+			StartTime:     runEnd.Add(-time.Minute),
+			EndTime:       runEnd,
+			Providers:     []string{"aws"},
+			Resources:     resources,
+			CoveredScopes: []core.Scope{scope},
+		}))
+		return runID
+	}
+
+	first := storeRun(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		bucket("logs", false, map[string]string{"Team": "ops"}),
+		bucket("assets", false, nil),
+	)
+	second := storeRun(time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC),
+		bucket("logs", true, map[string]string{"Team": "platform"}),
+		bucket("backups", false, nil),
+	)
+
+	diff, err := store.DiffRuns(first, second, core.DiffFilter{})
+	require.NoError(t, err)
+
+	assert.Equal(t, first, diff.FromRun)
+	assert.Equal(t, second, diff.ToRun)
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, "backups", diff.Added[0].ID)
+	require.Len(t, diff.Removed, 1)
+	assert.Equal(t, "assets", diff.Removed[0].ID)
+	require.Len(t, diff.Modified, 1)
+	assert.Equal(t, []core.FieldChange{
+		{Field: "public_access", Old: false, New: true},
+		{Field: "tags.Team", Old: "ops", New: "platform"},
+	}, diff.Modified[0].Fields)
+
+	t.Run("filter", func(t *testing.T) {
+		diff, err := store.DiffRuns(first, second, core.DiffFilter{Services: []string{"ec2"}})
+		require.NoError(t, err)
+		assert.True(t, diff.Empty())
+	})
+
+	t.Run("formats", func(t *testing.T) {
+		exporter := export.NewExporter()
+		for _, format := range []string{"text", "json", "markdown"} {
+			var buf bytes.Buffer
+			require.NoError(t, exporter.WriteDiff(&buf, diff, format))
+			assert.Contains(t, buf.String(), "tags.Team", format)
+		}
+		assert.Error(t, exporter.WriteDiff(&bytes.Buffer{}, diff, "xml"))
+	})
+}
@@ -120,6 +120,10 @@ func (m *MockStorage) GetResourceSummary() (*core.ResourceSummary, error) {
 	return &core.ResourceSummary{}, nil
 }
 
+func (m *MockStorage) GetResourceHistory(resourceID string) (*core.ResourceHistory, error) {
+	return &core.ResourceHistory{ResourceID: resourceID}, nil
+}
+
 func (m *MockStorage) GetDiscoveryStatus() (*core.DiscoveryStatus, error) {
 	return &core.DiscoveryStatus{}, nil
 }
@@ -0,0 +1,115 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/cloudrecon/cloudrecon/internal/query"
+	"github.com/cloudrecon/cloudrecon/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgHierarchyStorage(t *testing.T) {
+	// Skip if not running integration tests
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "cloudrecon.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	organization := core.OrgNode{ID: "o-example", Provider: "aws", Kind: core.OrgNodeOrganization, Name: "o-example"}
+	workloads := core.OrgNode{ID: "ou-work", Provider: "aws", Kind: core.OrgNodeOrganizationalUnit, Name: "Workloads", ParentID: "o-example"}
+	payments := core.OrgNode{ID: "ou-pay", Provider: "aws", Kind: core.OrgNodeOrganizationalUnit, Name: "Payments", ParentID: "ou-work"}
+	security := core.OrgNode{ID: "ou-sec", Provider: "aws", Kind: core.OrgNodeOrganizationalUnit, Name: "Security", ParentID: "o-example"}
+
+	now := time.Now()
+	resource := func(id, accountID string, cost float64, public bool) core.Resource {
+		return core.Resource{
+			ID: id, Provider: "aws", AccountID: accountID, Region: "us-east-1",
+			Service: "ec2", Type: "instance", Name: id,
+			CreatedAt: now, UpdatedAt: now, DiscoveredAt: now,
+			MonthlyCost: cost, PublicAccess: public,
+		}
+	}
+
+	result := &core.DiscoveryResult{
+		StartTime: now,
+		EndTime:   now,
+		Providers: []string{"aws"},
+		Accounts: []core.Account{
+			{ID: "111111111111", Provider: "aws", Name: "payments-prod", Hierarchy: []core.OrgNode{organization, workloads, payments}},
+			{ID: "222222222222", Provider: "aws", Name: "shared", Hierarchy: []core.OrgNode{organization, workloads}},
+			{ID: "333333333333", Provider: "aws", Name: "audit", Hierarchy: []core.OrgNode{organization, security}},
+		},
+		Resources: []core.Resource{
+			resource("i-pay-1", "111111111111", 100, true),
+			resource("i-pay-2", "111111111111", 50, false),
+			resource("i-shared", "222222222222", 25, false),
+			resource("i-audit", "333333333333", 10, false),
+		},
+	}
+	require.NoError(t, store.StoreDiscovery(result))
+
+	t.Run("nodes", func(t *testing.T) {
+		nodes, err := store.GetOrgNodes("aws")
+		require.NoError(t, err)
+		assert.Len(t, nodes, 7)
+
+		children, err := store.GetOrgNodeChildren("aws", "ou-work")
+		require.NoError(t, err)
+		var ids []string
+		for _, child := range children {
+			ids = append(ids, child.ID)
+		}
+		assert.ElementsMatch(t, []string{"ou-pay", "222222222222"}, ids)
+	})
+
+	t.Run("resources under node", func(t *testing.T) {
+		engine := query.NewEngine(store)
+
+		resources, err := engine.GetResourcesUnderOrgNode("ou-work")
+		require.NoError(t, err)
+		assert.Len(t, resources, 3)
+
+		// Nodes can also be referenced by name
+		resources, err = engine.GetResourcesUnderOrgNode("Security")
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, "i-audit", resources[0].ID)
+	})
+
+	t.Run("summary roll-up", func(t *testing.T) {
+		summary, err := store.GetResourceSummary()
+		require.NoError(t, err)
+
+		byPath := make(map[string]core.OrgNodeSummary)
+		for _, node := range summary.ByOrgNode {
+			byPath[node.Path] = node
+		}
+		require.Len(t, byPath, 4)
+
+		assert.Equal(t, 4, byPath["o-example"].ResourceCount)
+		assert.Equal(t, 3, byPath["o-example/Workloads"].ResourceCount)
+		assert.InDelta(t, 175.0, byPath["o-example/Workloads"].MonthlyCost, 0.001)
+		assert.Equal(t, 1, byPath["o-example/Workloads"].PublicResources)
+		assert.Equal(t, 2, byPath["o-example/Workloads/Payments"].ResourceCount)
+		assert.Equal(t, 1, byPath["o-example/Security"].ResourceCount)
+	})
+
+	t.Run("moved account", func(t *testing.T) {
+		moved := core.OrgNode{ID: "222222222222", Provider: "aws", Kind: core.OrgNodeAccount, Name: "shared", ParentID: "ou-sec"}
+		require.NoError(t, store.StoreOrgNodes([]core.OrgNode{moved}))
+
+		resources, err := query.NewEngine(store).GetResourcesUnderOrgNode("ou-sec")
+		require.NoError(t, err)
+		assert.Len(t, resources, 2)
+	})
+}
@@ -0,0 +1,113 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/cloudrecon/cloudrecon/internal/export"
+	"github.com/cloudrecon/cloudrecon/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceHistory(t *testing.T) {
+	// Skip if not running integration tests
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "cloudrecon.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	scope := core.Scope{Provider: "aws", AccountID: "123456789012", Region: "us-east-1", Service: "s3"}
+	bucket := func(public, encrypted bool, versioning string, tags map[string]string) core.Resource {
+		return core.Resource{
+			ID: "data-bucket", Provider: scope.Provider, AccountID: scope.AccountID, Region: scope.Region,
+			Service: scope.Service, Type: "bucket", Name: "data-bucket", Tags: tags,
+			PublicAccess: public, Encrypted: encrypted,
+			Configuration: json.RawMessage(`{"Versioning":"` + versioning + `"}`),
+			DiscoveredAt:  time.Now(),
+		}
+	}
+	storeRun := func(runEnd time.Time, checkpointed []core.Resource, resources ...core.Resource) int64 {
+		runID, err := store.CreateRun(&core.DiscoveryResult{StartTime: runEnd.Add(-time.Minute), Providers: []string{"aws"}})
+		require.NoError(t, err)
+		if checkpointed != nil {
+			require.NoError(t, store.SaveCheckpoint(core.Checkpoint{
+				RunID: runID, Provider: scope.Provider, AccountID: scope.AccountID, Region: scope.Region,
+				ResourceCount: len(checkpointed), CompletedAt: runEnd,
+			}, checkpointed))
+		}
+		require.NoError(t, store.StoreDiscovery(&core.DiscoveryResult{
+			RunID:         runID,
+			StartTime:     runEnd.Add(-time.Minute),
+			EndTime:       runEnd,
+			Providers:     []string{"aws"},
+			Resources:     resources,
+			CoveredScopes: []core.Scope{scope},
+		}))
+		return runID
+	}
+
+	first := storeRun(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), nil,
+		bucket(false, true, "Enabled", map[string]string{"Team": "data"}))
+	storeRun(time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC), nil,
+		bucket(false, true, "Enabled", map[string]string{"Team": "data"}))
+	// The checkpoint stores the bucket before it is enriched with tags
+	third := storeRun(time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC),
+		[]core.Resource{bucket(true, false, "Suspended", nil)},
+		bucket(true, false, "Suspended", map[string]string{"Team": "data"}))
+	fourth := storeRun(time.Date(2024, 6, 4, 12, 0, 0, 0, time.UTC), nil)
+
+	history, err := store.GetResourceHistory("data-bucket")
+	require.NoError(t, err)
+
+	require.Len(t, history.Events, 3)
+	assert.Equal(t, core.EventCreated, history.Events[0].Change)
+	assert.Equal(t, first, history.Events[0].RunID)
+	assert.Equal(t, history.Events[0].Time, history.FirstSeen)
+
+	update := history.Events[1]
+	assert.Equal(t, core.EventUpdated, update.Change)
+	assert.Equal(t, third, update.RunID)
+	assert.Equal(t, []core.FieldChange{
+		{Field: "public_access", Old: false, New: true},
+		{Field: "encrypted", Old: true, New: false},
+		{Field: "configuration.Versioning", Old: "Enabled", New: "Suspended"},
+	}, update.Fields)
+	assert.JSONEq(t, `{"Versioning":"Enabled"}`, string(update.OldConfiguration))
+
+	assert.Equal(t, core.EventDeleted, history.Events[2].Change)
+	assert.Equal(t, fourth, history.Events[2].RunID)
+	assert.True(t, history.Deleted)
+	require.NotNil(t, history.DeletedAt)
+	require.NotNil(t, history.Resource)
+	assert.Equal(t, "data-bucket", history.Resource.Name)
+
+	t.Run("formats", func(t *testing.T) {
+		exporter := export.NewExporter()
+
+		var text bytes.Buffer
+		require.NoError(t, exporter.WriteHistory(&text, history, "text"))
+		assert.Contains(t, text.String(), "configuration.Versioning: \"Enabled\" -> \"Suspended\"")
+
+		var data bytes.Buffer
+		require.NoError(t, exporter.WriteHistory(&data, history, "json"))
+		var decoded core.ResourceHistory
+		require.NoError(t, json.Unmarshal(data.Bytes(), &decoded))
+		assert.Len(t, decoded.Events, 3)
+	})
+
+	t.Run("unknown resource", func(t *testing.T) {
+		_, err := store.GetResourceHistory("missing")
+		assert.Error(t, err)
+	})
+}
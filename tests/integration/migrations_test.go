@@ -0,0 +1,134 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cloudrecon/cloudrecon/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestSchemaMigrations(t *testing.T) {
+	// Skip if not running integration tests
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	t.Run("new database", func(t *testing.T) {
+		store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "cloudrecon.db"))
+		require.NoError(t, err)
+		defer store.Close()
+
+		version, err := store.SchemaVersion()
+		require.NoError(t, err)
+		assert.Equal(t, storage.LatestSchemaVersion(), version)
+
+		// Reopening applies nothing
+		applied, err := store.Migrate()
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+	})
+
+	t.Run("database from before versioning", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "legacy.db")
+
+		db, err := sql.Open("sqlite", path)
+		require.NoError(t, err)
+		_, err = db.Exec(`
+			CREATE TABLE resources (
+				id TEXT PRIMARY KEY, provider TEXT NOT NULL, account_id TEXT NOT NULL, region TEXT,
+				service TEXT NOT NULL, type TEXT NOT NULL, name TEXT, arn TEXT,
+				created_at DATETIME, updated_at DATETIME, tags TEXT, configuration TEXT,
+				public_access BOOLEAN DEFAULT FALSE, encrypted BOOLEAN DEFAULT FALSE,
+				monthly_cost REAL DEFAULT 0, dependencies TEXT,
+				discovered_at DATETIME DEFAULT CURRENT_TIMESTAMP, discovery_method TEXT,
+				UNIQUE(provider, account_id, id)
+			);
+			INSERT INTO resources VALUES (
+				'i-legacy', 'aws', '123456789012', 'us-east-1', 'ec2', 'instance', 'legacy', '',
+				CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, '{}', '{}', FALSE, TRUE, 0, '[]',
+				CURRENT_TIMESTAMP, 'direct_api'
+			);
+		`)
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		store, err := storage.OpenSQLiteStorage(path)
+		require.NoError(t, err)
+		defer store.Close()
+
+		version, err := store.SchemaVersion()
+		require.NoError(t, err)
+		assert.Equal(t, 0, version)
+
+		applied, err := store.Migrate()
+		require.NoError(t, err)
+		assert.Len(t, applied, storage.LatestSchemaVersion())
+
+		resources, err := store.GetResources("SELECT * FROM resources")
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, "i-legacy", resources[0].ID)
+
+		migrations, err := store.Migrations()
+		require.NoError(t, err)
+		for _, migration := range migrations {
+			assert.False(t, migration.AppliedAt.IsZero(), "migration %d not applied", migration.Version)
+		}
+	})
+
+	t.Run("reading the version changes nothing", func(t *testing.T) {
+		dir := t.TempDir()
+
+		_, err := storage.OpenSQLiteStorageReadOnly(filepath.Join(dir, "missing.db"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		_, err = os.Stat(filepath.Join(dir, "missing.db"))
+		assert.True(t, os.IsNotExist(err), "the database must not be created")
+
+		// An empty file is a database without any table
+		path := filepath.Join(dir, "empty.db")
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+		store, err := storage.OpenSQLiteStorageReadOnly(path)
+		require.NoError(t, err)
+		defer store.Close()
+
+		version, err := store.SchemaVersion()
+		require.NoError(t, err)
+		assert.Equal(t, 0, version)
+
+		migrations, err := store.Migrations()
+		require.NoError(t, err)
+		require.Len(t, migrations, storage.LatestSchemaVersion())
+		for _, migration := range migrations {
+			assert.True(t, migration.AppliedAt.IsZero())
+		}
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Zero(t, info.Size(), "no schema must be written")
+	})
+
+	t.Run("database from a newer version", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "newer.db")
+
+		store, err := storage.NewSQLiteStorage(path)
+		require.NoError(t, err)
+		_, err = store.Exec(
+			"INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, 'from the future', CURRENT_TIMESTAMP)",
+			storage.LatestSchemaVersion()+1,
+		)
+		require.NoError(t, err)
+		require.NoError(t, store.Close())
+
+		_, err = storage.NewSQLiteStorage(path)
+		assert.ErrorIs(t, err, storage.ErrSchemaTooNew)
+	})
+}
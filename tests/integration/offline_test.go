@@ -0,0 +1,144 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/analysis"
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/cloudrecon/cloudrecon/internal/export"
+	"github.com/cloudrecon/cloudrecon/internal/providers/file"
+	"github.com/cloudrecon/cloudrecon/internal/providers/terraform"
+	"github.com/cloudrecon/cloudrecon/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfflinePipeline(t *testing.T) {
+	// Skip if not running integration tests
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	dir := t.TempDir()
+
+	provider, err := file.NewProvider(core.FileConfig{Path: "../fixtures/inventory"})
+	require.NoError(t, err)
+
+	store, err := storage.NewSQLiteStorage(filepath.Join(dir, "cloudrecon.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	// Discover
+	opts := core.DiscoveryOptions{
+		Mode:        core.StandardMode,
+		Providers:   []string{"file"},
+		MaxParallel: 2,
+		Timeout:     time.Minute,
+	}
+	orchestrator := core.NewDiscoveryOrchestrator(map[string]core.CloudProvider{"file": provider}, store, opts)
+
+	result, err := orchestrator.Discover(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, result.Accounts, 2)
+	assert.Len(t, result.Resources, 7)
+
+	// Store
+	resources, err := store.GetResources("SELECT * FROM resources")
+	require.NoError(t, err)
+	assert.Len(t, resources, 7)
+
+	// Analyze
+	report, err := analysis.NewAnalysisOrchestrator(store).AnalyzeAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 7, report.Summary.TotalResources)
+
+	// Export
+	output := filepath.Join(dir, "inventory.json")
+	require.NoError(t, export.NewExporter().Export(resources, "json", output))
+	info, err := os.Stat(output)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Size())
+}
+
+func TestOfflineResourceTypeFilter(t *testing.T) {
+	// Skip if not running integration tests
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	provider, err := file.NewProvider(core.FileConfig{Path: "../fixtures/inventory"})
+	require.NoError(t, err)
+
+	opts := core.DiscoveryOptions{
+		Mode:          core.StandardMode,
+		MaxParallel:   2,
+		ResourceTypes: []string{"AWS::EC2::Instance", "AWS::S3::Bucket"},
+	}
+	orchestrator := core.NewDiscoveryOrchestrator(map[string]core.CloudProvider{"file": provider}, &MockStorage{}, opts)
+
+	result, err := orchestrator.Discover(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, result.Resources, 2)
+}
+
+func TestTerraformStateDiscovery(t *testing.T) {
+	// Skip if not running integration tests
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	provider, err := terraform.NewProvider(core.TerraformConfig{StatePaths: []string{"../fixtures/terraform"}})
+	require.NoError(t, err)
+
+	opts := core.DiscoveryOptions{Mode: core.StandardMode, MaxParallel: 2}
+	orchestrator := core.NewDiscoveryOrchestrator(map[string]core.CloudProvider{"terraform": provider}, &MockStorage{}, opts)
+
+	result, err := orchestrator.Discover(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, result.Accounts, 3)
+	require.Len(t, result.Resources, 7)
+
+	byID := make(map[string]core.Resource)
+	for _, resource := range result.Resources {
+		assert.Equal(t, "terraform_state", resource.DiscoveryMethod)
+		byID[resource.ID] = resource
+	}
+
+	web := byID["i-0123456789abcdef0"]
+	assert.Equal(t, "aws", web.Provider)
+	assert.Equal(t, "123456789012", web.AccountID)
+	assert.Equal(t, "us-east-1", web.Region)
+	assert.Equal(t, "ec2", web.Service)
+	assert.Equal(t, "instance", web.Type)
+	assert.Equal(t, "web-0", web.Name)
+	assert.Equal(t, "platform", web.Tags["Team"])
+	assert.Equal(t, `aws_instance.web[0]`, web.Tags["terraform_address"])
+	assert.True(t, web.PublicAccess)
+	assert.Equal(t, []string{"sg-0123456789abcdef0"}, web.Dependencies)
+
+	bucket := byID["staging-assets"]
+	assert.Equal(t, "123456789012", bucket.AccountID)
+	assert.Equal(t, "module.storage.aws_s3_bucket.assets", bucket.Tags["terraform_address"])
+
+	db := byID["db-ABCDEFGHIJKLMNOP"]
+	assert.True(t, db.Encrypted)
+	assert.NotContains(t, string(db.Configuration), "not-a-real-password")
+
+	storageAccount := byID["/subscriptions/00000000-0000-0000-0000-000000000001/resourceGroups/rg-logs/providers/Microsoft.Storage/storageAccounts/stlogs"]
+	assert.Equal(t, "azure", storageAccount.Provider)
+	assert.Equal(t, "00000000-0000-0000-0000-000000000001", storageAccount.AccountID)
+	assert.Equal(t, "westeurope", storageAccount.Region)
+
+	worker := byID["projects/analytics-staging/zones/europe-west1-b/instances/worker"]
+	assert.Equal(t, "gcp", worker.Provider)
+	assert.Equal(t, "analytics-staging", worker.AccountID)
+	assert.Equal(t, "europe-west1", worker.Region)
+	assert.Equal(t, "staging", worker.Tags["environment"])
+}
@@ -0,0 +1,130 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/cloudrecon/cloudrecon/internal/query"
+	"github.com/cloudrecon/cloudrecon/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceRelationships(t *testing.T) {
+	// Skip if not running integration tests
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "cloudrecon.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	storeRun := func(resources ...core.Resource) int64 {
+		runID, err := store.CreateRun(&core.DiscoveryResult{StartTime: time.Now(), Providers: []string{"aws"}})
+		require.NoError(t, err)
+		require.NoError(t, store.StoreDiscovery(&core.DiscoveryResult{
+			RunID: runID, StartTime: time.Now(), EndTime: time.Now(), Providers: []string{"aws"}, Resources: resources,
+		}))
+		return runID
+	}
+	resource := func(id string) core.Resource {
+		return core.Resource{ID: id, Provider: "aws", AccountID: "123456789012", Region: "us-east-1", Service: "ec2", Type: "instance", Name: id}
+	}
+
+	runID := storeRun(resource("lb"), resource("app"), resource("db"), resource("vpc"), resource("cache"))
+
+	current, err := store.RelationshipsCurrent()
+	require.NoError(t, err)
+	assert.False(t, current, "nothing analyzed yet")
+
+	// lb -> app -> db -> vpc, the cache depends on app (stored inbound), and
+	// app and db depend on each other through a bidirectional relationship
+	require.NoError(t, store.StoreRelationships([]core.Relationship{
+		{SourceID: "lb", TargetID: "app", Type: "routes_to", Direction: core.DirectionOutbound, Confidence: 0.9},
+		{SourceID: "app", TargetID: "db", Type: "connects_to", Direction: core.DirectionBidirectional, Confidence: 0.8,
+			Metadata: map[string]interface{}{"port": float64(5432)}},
+		{SourceID: "db", TargetID: "vpc", Type: "runs_in_vpc", Direction: core.DirectionOutbound, Confidence: 0.95},
+		{SourceID: "app", TargetID: "cache", Type: "warms", Direction: core.DirectionInbound, Confidence: 0.5},
+	}))
+
+	current, err = store.RelationshipsCurrent()
+	require.NoError(t, err)
+	assert.True(t, current)
+
+	t.Run("stored fields", func(t *testing.T) {
+		relationships, err := store.GetRelationships("connects_to")
+		require.NoError(t, err)
+		require.Len(t, relationships, 1)
+		assert.Equal(t, core.Relationship{
+			SourceID: "app", TargetID: "db", Type: "connects_to", Direction: core.DirectionBidirectional,
+			Confidence: 0.8, RunID: runID, Metadata: map[string]interface{}{"port": float64(5432)},
+		}, relationships[0])
+	})
+
+	t.Run("neighbors", func(t *testing.T) {
+		relationships, err := store.GetNeighbors("app")
+		require.NoError(t, err)
+		assert.Len(t, relationships, 3)
+
+		relationships, err = store.GetNeighbors("app", "routes_to")
+		require.NoError(t, err)
+		require.Len(t, relationships, 1)
+		assert.Equal(t, "lb", relationships[0].SourceID)
+	})
+
+	t.Run("upstream", func(t *testing.T) {
+		upstream, err := store.GetUpstream("lb")
+		require.NoError(t, err)
+		assert.Equal(t, []core.RelatedResource{
+			{ResourceID: "app", Depth: 1, Relationship: "routes_to"},
+			{ResourceID: "db", Depth: 2, Relationship: "connects_to"},
+			{ResourceID: "vpc", Depth: 3, Relationship: "runs_in_vpc"},
+		}, upstream)
+
+		upstream, err = store.GetUpstream("lb", "routes_to")
+		require.NoError(t, err)
+		assert.Len(t, upstream, 1)
+	})
+
+	t.Run("downstream", func(t *testing.T) {
+		downstream, err := store.GetDownstream("vpc")
+		require.NoError(t, err)
+		assert.Equal(t, []core.RelatedResource{
+			{ResourceID: "db", Depth: 1, Relationship: "runs_in_vpc"},
+			{ResourceID: "app", Depth: 2, Relationship: "connects_to"},
+			{ResourceID: "cache", Depth: 3, Relationship: "warms"},
+			{ResourceID: "lb", Depth: 3, Relationship: "routes_to"},
+		}, downstream)
+	})
+
+	t.Run("query engine", func(t *testing.T) {
+		engine := query.NewEngine(store)
+
+		resources, err := engine.GetDownstream("db", "connects_to", "routes_to")
+		require.NoError(t, err)
+		var ids []string
+		for _, resource := range resources {
+			ids = append(ids, resource.ID)
+		}
+		assert.Equal(t, []string{"app", "lb"}, ids)
+
+		resources, err = engine.GetNeighbors("vpc")
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, "db", resources[0].ID)
+	})
+
+	t.Run("stale after a new run", func(t *testing.T) {
+		storeRun(resource("lb"))
+
+		current, err := store.RelationshipsCurrent()
+		require.NoError(t, err)
+		assert.False(t, current)
+	})
+}
@@ -0,0 +1,99 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/cloudrecon/cloudrecon/internal/query"
+	"github.com/cloudrecon/cloudrecon/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fullResource returns a resource with every field populated
+func fullResource(id string) core.Resource {
+	return core.Resource{
+		ID:              id,
+		ARN:             "arn:aws:ec2:us-east-1:123456789012:instance/" + id,
+		Provider:        "aws",
+		AccountID:       "123456789012",
+		Region:          "us-east-1",
+		Service:         "ec2",
+		Type:            "instance",
+		Name:            "web-" + id,
+		CreatedAt:       time.Date(2024, 1, 2, 3, 4, 5, 6000, time.UTC),
+		UpdatedAt:       time.Date(2024, 2, 3, 4, 5, 6, 7000, time.UTC),
+		Tags:            map[string]string{"Environment": "prod", "Team": "payments"},
+		Configuration:   json.RawMessage(`{"InstanceType":"m5.large","State":"running"}`),
+		PublicAccess:    true,
+		Encrypted:       true,
+		Compliance:      []string{"public-access", "imdsv1-enabled"},
+		MonthlyCost:     70.08,
+		Dependencies:    []string{"vol-1", "sg-1"},
+		Dependents:      []string{"elb-1"},
+		DiscoveredAt:    time.Date(2024, 3, 4, 5, 6, 7, 8000, time.UTC),
+		DiscoveryMethod: "config",
+	}
+}
+
+func TestResourceRoundTrip(t *testing.T) {
+	// Skip if not running integration tests
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	t.Run("store discovery", func(t *testing.T) {
+		store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "cloudrecon.db"))
+		require.NoError(t, err)
+		defer store.Close()
+
+		resource := fullResource("i-discovery")
+		require.NoError(t, store.StoreDiscovery(&core.DiscoveryResult{
+			StartTime: time.Now(),
+			EndTime:   time.Now(),
+			Providers: []string{"aws"},
+			Resources: []core.Resource{resource},
+		}))
+
+		resources, err := store.GetResources("SELECT * FROM resources")
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, resource, resources[0])
+	})
+
+	t.Run("store resources", func(t *testing.T) {
+		store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "cloudrecon.db"))
+		require.NoError(t, err)
+		defer store.Close()
+
+		resource := fullResource("i-direct")
+		require.NoError(t, store.StoreResources(context.Background(), []core.Resource{resource}))
+
+		resources, err := query.NewEngine(store).ExecuteSQL("SELECT * FROM resources")
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.Equal(t, resource, resources[0])
+	})
+
+	t.Run("local times", func(t *testing.T) {
+		store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "cloudrecon.db"))
+		require.NoError(t, err)
+		defer store.Close()
+
+		resource := fullResource("i-local")
+		resource.CreatedAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+		require.NoError(t, store.StoreResources(context.Background(), []core.Resource{resource}))
+
+		resources, err := store.GetResources("SELECT * FROM resources")
+		require.NoError(t, err)
+		require.Len(t, resources, 1)
+		assert.True(t, resource.CreatedAt.Equal(resources[0].CreatedAt))
+	})
+}
@@ -0,0 +1,146 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloudrecon/cloudrecon/internal/core"
+	"github.com/cloudrecon/cloudrecon/internal/query"
+	"github.com/cloudrecon/cloudrecon/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventorySnapshots(t *testing.T) {
+	// Skip if not running integration tests
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	store, err := storage.NewSQLiteStorage(filepath.Join(t.TempDir(), "cloudrecon.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	scope := core.Scope{Provider: "aws", AccountID: "123456789012", Region: "us-east-1", Service: "ec2"}
+	instance := func(id, state string) core.Resource {
+		return core.Resource{
+			ID: id, Provider: scope.Provider, AccountID: scope.AccountID, Region: scope.Region,
+			Service: scope.Service, Type: "instance", Name: id,
+			Configuration: json.RawMessage(`{"State":"` + state + `"}`),
+			CreatedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			UpdatedAt:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			DiscoveredAt:  time.Now(),
+		}
+	}
+	storeRun := func(runEnd time.Time, resources ...core.Resource) int64 {
+		runID, err := store.CreateRun(&core.DiscoveryResult{StartTime: runEnd.Add(-time.Minute), Providers: []string{"aws"}})
+		require.NoError(t, err)
+		require.NoError(t, store.StoreDiscovery(&core.DiscoveryResult{
+			RunID:         runID,
+			StartTime:     runEnd.Add(-time.Minute),
+			EndTime:       runEnd,
+			Providers:     []string{"aws"},
+			Resources:     resources,
+			CoveredScopes: []core.Scope{scope},
+		}))
+		return runID
+	}
+
+	first := storeRun(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), instance("i-a", "running"), instance("i-b", "running"))
+	second := storeRun(time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC), instance("i-a", "stopped"), instance("i-c", "running"))
+	third := storeRun(time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC), instance("i-a", "stopped"), instance("i-c", "running"))
+
+	ids := func(resources []core.Resource) []string {
+		var result []string
+		for _, resource := range resources {
+			result = append(result, resource.ID)
+		}
+		return result
+	}
+
+	t.Run("run snapshots", func(t *testing.T) {
+		resources, err := store.GetResourcesAsOf(first)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"i-a", "i-b"}, ids(resources))
+		assert.JSONEq(t, `{"State":"running"}`, string(resources[0].Configuration))
+
+		resources, err = store.GetResourcesAsOf(second)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"i-a", "i-c"}, ids(resources))
+		assert.JSONEq(t, `{"State":"stopped"}`, string(resources[0].Configuration))
+
+		resources, err = store.GetResourcesAsOf(third)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"i-a", "i-c"}, ids(resources))
+	})
+
+	t.Run("unchanged resources share versions", func(t *testing.T) {
+		rows, err := store.Query("SELECT COUNT(*) FROM resource_versions")
+		require.NoError(t, err)
+		defer rows.Close()
+
+		var count int
+		require.True(t, rows.Next())
+		require.NoError(t, rows.Scan(&count))
+		assert.Equal(t, 4, count)
+	})
+
+	t.Run("resolve run", func(t *testing.T) {
+		runID, err := store.ResolveRun("2024-06-02")
+		require.NoError(t, err)
+		assert.Equal(t, second, runID)
+
+		runID, err = store.ResolveRun("2024-06-02T11:00:00Z")
+		require.NoError(t, err)
+		assert.Equal(t, first, runID)
+
+		_, err = store.ResolveRun("2024-05-01")
+		assert.Error(t, err)
+
+		_, err = store.ResolveRun("999")
+		assert.Error(t, err)
+	})
+
+	t.Run("resources stored outside a run are not versioned", func(t *testing.T) {
+		require.NoError(t, store.StoreResources(context.Background(), []core.Resource{instance("i-a", "terminated")}))
+
+		resources, err := store.GetResourcesAsOf(third)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"i-a", "i-c"}, ids(resources))
+		assert.JSONEq(t, `{"State":"stopped"}`, string(resources[0].Configuration))
+
+		rows, err := store.Query("SELECT COUNT(*) FROM resource_versions WHERE valid_from = 0 OR valid_to = 0")
+		require.NoError(t, err)
+		defer rows.Close()
+
+		var count int
+		require.True(t, rows.Next())
+		require.NoError(t, rows.Scan(&count))
+		assert.Zero(t, count)
+	})
+
+	t.Run("query as of", func(t *testing.T) {
+		engine := query.NewEngine(store).AsOf(first)
+
+		resources, err := engine.ExecuteSQL("SELECT * FROM resources WHERE json_extract(configuration, '$.State') = ?", "running")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"i-a", "i-b"}, ids(resources))
+
+		resources, err = query.NewEngine(store).ExecuteSQL("SELECT * FROM resources WHERE json_extract(configuration, '$.State') = ?", "running")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"i-c"}, ids(resources))
+
+		// Queries with their own table expressions read the snapshot too
+		resources, err = engine.ExecuteSQL(`
+			WITH running AS (SELECT id FROM resources WHERE json_extract(configuration, '$.State') = ?)
+			SELECT * FROM resources WHERE id IN (SELECT id FROM running) AND id != ?`, "running", "i-a")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"i-b"}, ids(resources))
+	})
+}